@@ -23,6 +23,26 @@ type Config struct {
 	RefreshSecret string
 	TokenExpiry   int
 	RefreshExpiry int
+	// Trashed todos are permanently deleted after TrashRetentionDays, checked every
+	// TrashPurgeInterval seconds. 0 keeps them forever.
+	TrashRetentionDays int
+	TrashPurgeInterval int
+	// Comments can be edited by their author for CommentEditWindow seconds after
+	// being posted. 0 lets them be edited at any time.
+	CommentEditWindow int
+	// Attachments are stored by StorageDriver, "local" under StorageLocalPath or "s3"
+	// in the S3Bucket of an S3-compatible server like MinIO.
+	StorageDriver    string
+	StorageLocalPath string
+	S3Endpoint       string
+	S3Region         string
+	S3Bucket         string
+	S3AccessKey      string
+	S3SecretKey      string
+	S3UseSSL         bool
+	// AttachmentMaxSize is in bytes, AttachmentTypes the comma-separated MIME types accepted
+	AttachmentMaxSize int
+	AttachmentTypes   string
 }
 
 var (
@@ -40,17 +60,33 @@ func LoadConfig() *Config {
 
 		// Parse and validate configuration variables
 		cfg = &Config{
-			Env:           getEnv("ENV", "production"),
-			AppPort:       getEnv("APP_PORT", "8080"),
-			DbHost:        getEnv("DB_HOST", "localhost"),
-			DbPort:        getEnvAsInt("DB_PORT", 5432),
-			DbUser:        getEnv("DB_USER", "postgres"),
-			DbPassword:    getEnv("DB_PASSWORD", "password"),
-			DbName:        getEnv("DB_NAME", "echo_app"),
-			JwtSecret:     getEnv("JWT_SECRET", "supersecretkey"),
-			RefreshSecret: getEnv("REFRESH_SECRET", "superrefreshkey"),
-			TokenExpiry:   getEnvAsInt("TOKEN_EXPIRY", 3600),    // Default: 1 hour
-			RefreshExpiry: getEnvAsInt("REFRESH_EXPIRY", 86400), // Default: 1 day
+			Env:                getEnv("ENV", "production"),
+			AppPort:            getEnv("APP_PORT", "8080"),
+			DbHost:             getEnv("DB_HOST", "localhost"),
+			DbPort:             getEnvAsInt("DB_PORT", 5432),
+			DbUser:             getEnv("DB_USER", "postgres"),
+			DbPassword:         getEnv("DB_PASSWORD", "password"),
+			DbName:             getEnv("DB_NAME", "echo_app"),
+			JwtSecret:          getEnv("JWT_SECRET", "supersecretkey"),
+			RefreshSecret:      getEnv("REFRESH_SECRET", "superrefreshkey"),
+			TokenExpiry:        getEnvAsInt("TOKEN_EXPIRY", 3600),         // Default: 1 hour
+			RefreshExpiry:      getEnvAsInt("REFRESH_EXPIRY", 86400),      // Default: 1 day
+			TrashRetentionDays: getEnvAsInt("TRASH_RETENTION_DAYS", 0),    // Default: keep trashed todos forever
+			TrashPurgeInterval: getEnvAsInt("TRASH_PURGE_INTERVAL", 3600), // Default: 1 hour
+			CommentEditWindow:  getEnvAsInt("COMMENT_EDIT_WINDOW", 900),   // Default: 15 minutes
+			StorageDriver:      getEnv("STORAGE_DRIVER", "local"),
+			StorageLocalPath:   getEnv("STORAGE_LOCAL_PATH", "uploads"),
+			S3Endpoint:         getEnv("S3_ENDPOINT", "localhost:9000"),
+			S3Region:           getEnv("S3_REGION", ""),
+			S3Bucket:           getEnv("S3_BUCKET", "attachments"),
+			S3AccessKey:        getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey:        getEnv("S3_SECRET_KEY", ""),
+			S3UseSSL:           getEnvAsBool("S3_USE_SSL", false),
+			AttachmentMaxSize:  getEnvAsInt("ATTACHMENT_MAX_SIZE", 10<<20), // Default: 10 MiB
+			AttachmentTypes: getEnv("ATTACHMENT_TYPES",
+				"image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain,text/csv,"+
+					"application/zip,application/vnd.openxmlformats-officedocument.wordprocessingml.document,"+
+					"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"),
 		}
 	})
 
@@ -65,6 +101,15 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvAsBool gets an environment variable as a boolean or returns a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 // getEnvAsInt gets an environment variable as an integer or returns a default value
 func getEnvAsInt(key string, defaultValue int) int {
 	valueStr := getEnv(key, "")
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"go-feToDo/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTodoHistory handles GET requests to fetch a page of the history of a todo
+func GetTodoHistory(c *gin.Context) {
+	todoID := c.Param("todoID")
+	var query dto.HistoryQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	page, err := services.GetTodoHistory(todoID, &query, authorID.(string))
+	if err != nil {
+		c.JSON(historyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// historyErrorStatus maps the errors of the history service to a status code.
+func historyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrToDoNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dto.ErrUnauthToDo):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrInvalidCursor):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"go-feToDo/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AssignTodo handles PUT requests to assign a todo to a user
+func AssignTodo(c *gin.Context) {
+	todoID := c.Param("todoID")
+	var assignDTO dto.AssignTodoDTO
+	if err := c.ShouldBindJSON(&assignDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(assignDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todo, err := services.AssignTodo(todoID, &assignDTO, authorID.(string))
+	if errors.Is(err, dto.ErrUserNotFound) || errors.Is(err, dto.ErrToDoAssigneeNoAccess) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// UnassignTodo handles DELETE requests to remove the assignee of a todo
+func UnassignTodo(c *gin.Context) {
+	todoID := c.Param("todoID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todo, err := services.UnassignTodo(todoID, authorID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// GetAssignedTodos handles GET requests to fetch the todos assigned to a user
+func GetAssignedTodos(c *gin.Context) {
+	listToDo(c, services.GetAssignedTodos)
+}
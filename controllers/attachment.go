@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"errors"
+	"go-feToDo/config"
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// attachmentFormOverhead is the room left in the request body for the multipart framing around the file
+const attachmentFormOverhead = 1 << 20
+
+// GetAttachments handles GET requests to list the files attached to a todo
+func GetAttachments(c *gin.Context) {
+	todoID := c.Param("todoID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	attachments, err := services.GetAttachments(todoID, authorID.(string))
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attachments": attachments,
+	})
+}
+
+// UploadAttachment handles multipart POST requests to attach a file to a todo
+func UploadAttachment(c *gin.Context) {
+	todoID := c.Param("todoID")
+	maxSize := int64(config.LoadConfig().AttachmentMaxSize)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+attachmentFormOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": dto.ErrAttachmentTooLarge.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrAttachmentFile.Error()})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := services.UploadAttachment(todoID, fileHeader.Filename, file, fileHeader.Size, authorID.(string))
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DownloadAttachment handles GET requests to download a file attached to a todo
+func DownloadAttachment(c *gin.Context) {
+	todoID := c.Param("todoID")
+	attachmentID := c.Param("attachmentID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	attachment, content, err := services.OpenAttachment(todoID, attachmentID, authorID.(string))
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment handles DELETE requests to remove a file attached to a todo
+func DeleteAttachment(c *gin.Context) {
+	todoID := c.Param("todoID")
+	attachmentID := c.Param("attachmentID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	if err := services.DeleteAttachment(todoID, attachmentID, authorID.(string)); err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// attachmentErrorStatus maps the errors of the attachment services to a status code.
+func attachmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrToDoNotFound), errors.Is(err, dto.ErrAttachmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dto.ErrUnauthToDo):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, dto.ErrAttachmentType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, dto.ErrAttachmentFile):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
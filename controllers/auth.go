@@ -9,10 +9,9 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
-var validate = validator.New()
+var validate = utils.Validate
 
 // Login handles user login by validating credentials and generating JWT tokens
 func Login(c *gin.Context) {
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"go-feToDo/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetBoard handles GET requests to fetch the todos of a user in columns by status
+func GetBoard(c *gin.Context) {
+	var query dto.BoardQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	board, err := services.GetBoard(&query, authorID.(string))
+	if err != nil {
+		c.JSON(boardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// MoveCard handles POST requests to move a todo to a column of the board and a place in it
+func MoveCard(c *gin.Context) {
+	var moveDTO dto.MoveCardDTO
+	if err := c.ShouldBindJSON(&moveDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(moveDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todo, err := services.MoveCard(&moveDTO, authorID.(string))
+	if err != nil {
+		c.JSON(boardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// boardErrorStatus maps the errors of the board services to a status code.
+func boardErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrToDoNotFound), errors.Is(err, dto.ErrProjectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dto.ErrUnauthToDo), errors.Is(err, dto.ErrUnauthProject):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrToDoStatusUnknown), errors.Is(err, dto.ErrToDoMoveNeighbours):
+		return http.StatusBadRequest
+	case errors.Is(err, dto.ErrToDoStatusTransition), errors.Is(err, dto.ErrBoardWIPLimit),
+		errors.Is(err, dto.ErrToDoSubtasksOpen), errors.Is(err, dto.ErrToDoBlocked):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,490 @@
+package controllers
+
+import (
+	"encoding/xml"
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"go-feToDo/utils"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// davRoot is where the CalDAV routes are mounted
+	davRoot = "/dav"
+	// davCalendarName is the name of the one calendar collection of a user, holding their todos
+	davCalendarName = "todos"
+	// davObjectType is the content type of the todo resources
+	davObjectType = "text/calendar; charset=utf-8; component=VTODO"
+	// maxDavObjectSize bounds the size of a resource a client can PUT
+	maxDavObjectSize = 1 << 20
+	// davAllowedMethods are the methods the CalDAV routes answer
+	davAllowedMethods = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT"
+)
+
+// davKind is the kind of resource a CalDAV path points to.
+type davKind int
+
+const (
+	davRootKind davKind = iota
+	davPrincipalKind
+	davHomeKind
+	davCalendarKind
+	davObjectKind
+)
+
+// davTarget is the resource a CalDAV request is about.
+type davTarget struct {
+	kind     davKind
+	username string
+	name     string
+}
+
+// davHiddenProperties are too expensive to list when a client asks for every property.
+var davHiddenProperties = map[xml.Name]bool{
+	{Space: utils.CalDavNamespace, Local: "calendar-data"}: true,
+}
+
+// DavOptions handles OPTIONS requests, telling clients CalDAV is spoken here
+func DavOptions(c *gin.Context) {
+	c.Header("DAV", "1, 3, calendar-access")
+	c.Header("Allow", davAllowedMethods)
+	c.Status(http.StatusOK)
+}
+
+// DavPropfind handles PROPFIND requests, reading the properties of a resource and of its members with Depth: 1
+func DavPropfind(c *gin.Context) {
+	target, ok := findDavTarget(c)
+	if !ok {
+		return
+	}
+
+	body, err := utils.ParseDavBody(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	depth := c.GetHeader("Depth")
+	var collection *dto.DavCollectionDTO
+	var todo *dto.DavTodoDTO
+	switch target.kind {
+	case davObjectKind:
+		todo, err = services.GetDavTodo(target.name, c.GetString("authorID"))
+		if errors.Is(err, dto.ErrToDoNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	case davHomeKind, davCalendarKind:
+		// The home lists the calendar with its CTag unless its members aren't asked for
+		if target.kind == davHomeKind && depth == "0" {
+			break
+		}
+		collection, err = services.GetDavCollection(c.GetString("authorID"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	responses := davPropfindResponses(target, davRequestedProperties(body), depth, collection, todo)
+	c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", utils.WriteDavMultistatus(responses))
+}
+
+// DavReport handles REPORT requests on the calendar collection, calendar-query lists the todos
+// and calendar-multiget reads some of them, with their data when asked for
+func DavReport(c *gin.Context) {
+	target, ok := findDavTarget(c)
+	if !ok {
+		return
+	}
+
+	body, err := utils.ParseDavBody(c.Request.Body)
+	if err != nil || body == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": utils.ErrDavBody.Error()})
+		return
+	}
+	report := body.XMLName
+	if target.kind != davCalendarKind || report.Space != utils.CalDavNamespace ||
+		(report.Local != "calendar-query" && report.Local != "calendar-multiget") {
+		c.Data(http.StatusForbidden, "application/xml; charset=utf-8",
+			utils.WriteDavError(xml.Name{Space: utils.DavNamespace, Local: "supported-report"}))
+		return
+	}
+
+	collection, err := services.GetDavCollection(c.GetString("authorID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	responses := davReportResponses(target, body, collection)
+	c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", utils.WriteDavMultistatus(responses))
+}
+
+// DavGet handles GET and HEAD requests, reading the iCalendar data of a todo
+func DavGet(c *gin.Context) {
+	target, ok := findDavTarget(c)
+	if !ok {
+		return
+	}
+	if target.kind != davObjectKind {
+		c.Header("Allow", "OPTIONS, PROPFIND, REPORT")
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "collections can't be read with GET, use PROPFIND or REPORT"})
+		return
+	}
+
+	todo, err := services.GetDavTodo(target.name, c.GetString("authorID"))
+	if errors.Is(err, dto.ErrToDoNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", todo.ETag)
+	c.Header("Last-Modified", todo.LastModified.UTC().Format(http.TimeFormat))
+	if utils.ETagMatches(c.GetHeader("If-None-Match"), todo.ETag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, davObjectType, todo.Calendar)
+}
+
+// DavPut handles PUT requests, creating or replacing a todo from the VTODO of a client
+func DavPut(c *gin.Context) {
+	target, ok := findDavTarget(c)
+	if !ok {
+		return
+	}
+	if target.kind != davObjectKind {
+		c.Header("Allow", "OPTIONS, PROPFIND, REPORT")
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "only todo resources can be written"})
+		return
+	}
+
+	calendar, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxDavObjectSize))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": dto.ErrDavInvalidCalendar.Error()})
+		return
+	}
+
+	created, err := services.PutDavTodo(target.name, calendar, c.GetHeader("If-Match"), c.GetHeader("If-None-Match"), c.GetString("authorID"))
+	if err != nil {
+		davFail(c, err)
+		return
+	}
+
+	// The todo is stored as fields, not as sent, so no ETag tells the client to read it back
+	if created {
+		c.Header("Location", target.href())
+		c.Status(http.StatusCreated)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DavDelete handles DELETE requests, moving a todo to the trash
+func DavDelete(c *gin.Context) {
+	target, ok := findDavTarget(c)
+	if !ok {
+		return
+	}
+	if target.kind != davObjectKind {
+		c.Header("Allow", "OPTIONS, PROPFIND, REPORT")
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "only todo resources can be deleted"})
+		return
+	}
+
+	if err := services.DeleteDavTodo(target.name, c.GetHeader("If-Match"), c.GetString("authorID")); err != nil {
+		davFail(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// WellKnownCalDav points clients discovering the CalDAV server to its root
+func WellKnownCalDav(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, davRoot+"/")
+}
+
+// findDavTarget reads the resource a request is about. Only the resources of the
+// authenticated user can be reached, anything else is answered with a 404.
+func findDavTarget(c *gin.Context) (davTarget, bool) {
+	var segments []string
+	for _, segment := range strings.Split(c.Param("path"), "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	target := davTarget{username: c.GetString("username")}
+	found := false
+	switch {
+	case len(segments) == 0:
+		target.kind, found = davRootKind, true
+	case len(segments) == 2 && segments[0] == "principals":
+		target.kind, found = davPrincipalKind, segments[1] == target.username
+	case len(segments) >= 2 && segments[0] == "calendars" && segments[1] == target.username:
+		switch {
+		case len(segments) == 2:
+			target.kind, found = davHomeKind, true
+		case len(segments) == 3 && segments[2] == davCalendarName:
+			target.kind, found = davCalendarKind, true
+		case len(segments) == 4 && segments[2] == davCalendarName:
+			target.kind, target.name, found = davObjectKind, segments[3], true
+		}
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "CalDAV resource not found"})
+		return target, false
+	}
+	return target, true
+}
+
+// href returns the path of the resource, collections ending with a slash.
+func (t davTarget) href() string {
+	switch t.kind {
+	case davPrincipalKind:
+		return davRoot + "/principals/" + url.PathEscape(t.username) + "/"
+	case davHomeKind:
+		return davRoot + "/calendars/" + url.PathEscape(t.username) + "/"
+	case davCalendarKind:
+		return davRoot + "/calendars/" + url.PathEscape(t.username) + "/" + davCalendarName + "/"
+	case davObjectKind:
+		return davRoot + "/calendars/" + url.PathEscape(t.username) + "/" + davCalendarName + "/" + url.PathEscape(t.name)
+	}
+	return davRoot + "/"
+}
+
+// davObjectName reads the name of a todo resource from a href of the calendar collection.
+func davObjectName(calendar davTarget, href string) string {
+	if parsed, err := url.Parse(href); err == nil {
+		href = parsed.Path
+	}
+	name, ok := strings.CutPrefix(href, calendar.href())
+	if !ok || strings.Contains(name, "/") {
+		return ""
+	}
+	return name
+}
+
+// davRequestedProperties lists the properties named in the prop element of a request body.
+// No body or no prop element lists none, which asks for every property.
+func davRequestedProperties(body *utils.DavNode) []xml.Name {
+	var requested []xml.Name
+	if body == nil {
+		return requested
+	}
+	if prop := body.Child(utils.DavNamespace, "prop"); prop != nil {
+		for _, child := range prop.Children {
+			requested = append(requested, child.XMLName)
+		}
+	}
+	return requested
+}
+
+// davPropfindResponses answers a PROPFIND on a resource and, unless depth is 0, on its
+// members. collection is the data of the calendar collection, needed for it and for the home
+// unless depth is 0, and todo the data of a todo resource.
+func davPropfindResponses(target davTarget, requested []xml.Name, depth string, collection *dto.DavCollectionDTO, todo *dto.DavTodoDTO) []*utils.DavResponse {
+	var responses []*utils.DavResponse
+	switch target.kind {
+	case davRootKind, davPrincipalKind:
+		responses = append(responses, davResponse(target.href(), davProperties(target, nil), requested))
+	case davHomeKind:
+		responses = append(responses, davResponse(target.href(), davProperties(target, nil), requested))
+		if depth != "0" {
+			calendar := davTarget{kind: davCalendarKind, username: target.username}
+			responses = append(responses, davResponse(calendar.href(), davCalendarProperties(calendar, collection), requested))
+		}
+	case davObjectKind:
+		responses = append(responses, davResponse(target.href(), davProperties(target, todo), requested))
+	case davCalendarKind:
+		responses = append(responses, davResponse(target.href(), davCalendarProperties(target, collection), requested))
+		if depth != "0" {
+			for _, todo := range collection.Todos {
+				object := davTarget{kind: davObjectKind, username: target.username, name: todo.Name}
+				responses = append(responses, davResponse(object.href(), davProperties(object, todo), requested))
+			}
+		}
+	}
+	return responses
+}
+
+// davCalendarProperties lists the properties of the calendar collection, with the CTag of its data.
+func davCalendarProperties(target davTarget, collection *dto.DavCollectionDTO) []utils.DavProperty {
+	return append(davProperties(target, nil), utils.DavProperty{
+		Name:  xml.Name{Space: utils.CalendarServerNamespace, Local: "getctag"},
+		Value: utils.DavText(collection.CTag),
+	})
+}
+
+// davReportResponses answers a calendar-query or calendar-multiget REPORT on the calendar collection.
+func davReportResponses(target davTarget, report *utils.DavNode, collection *dto.DavCollectionDTO) []*utils.DavResponse {
+	requested := davRequestedProperties(report)
+
+	var responses []*utils.DavResponse
+	if report.XMLName.Local == "calendar-multiget" {
+		for _, href := range report.ChildrenNamed(utils.DavNamespace, "href") {
+			name := davObjectName(target, strings.TrimSpace(href.Text))
+			todo := findDavTodo(collection, name)
+			if todo == nil {
+				responses = append(responses, &utils.DavResponse{Href: strings.TrimSpace(href.Text), Status: http.StatusNotFound})
+				continue
+			}
+			object := davTarget{kind: davObjectKind, username: target.username, name: todo.Name}
+			responses = append(responses, davResponse(object.href(), davProperties(object, todo), requested))
+		}
+	} else if davQueriesTodos(report) {
+		// Time ranges and property filters aren't applied, clients filter what they get
+		for _, todo := range collection.Todos {
+			object := davTarget{kind: davObjectKind, username: target.username, name: todo.Name}
+			responses = append(responses, davResponse(object.href(), davProperties(object, todo), requested))
+		}
+	}
+	return responses
+}
+
+// davProperties lists the properties a resource has, todo being the data of a todo resource.
+func davProperties(target davTarget, todo *dto.DavTodoDTO) []utils.DavProperty {
+	dav := func(local string, value string) utils.DavProperty {
+		return utils.DavProperty{Name: xml.Name{Space: utils.DavNamespace, Local: local}, Value: value}
+	}
+	caldav := func(local string, value string) utils.DavProperty {
+		return utils.DavProperty{Name: xml.Name{Space: utils.CalDavNamespace, Local: local}, Value: value}
+	}
+	principal := davTarget{kind: davPrincipalKind, username: target.username}
+	home := davTarget{kind: davHomeKind, username: target.username}
+
+	properties := []utils.DavProperty{dav("current-user-principal", utils.DavHref(principal.href()))}
+	switch target.kind {
+	case davRootKind:
+		properties = append(properties, dav("resourcetype", "<d:collection/>"))
+	case davPrincipalKind:
+		properties = append(properties,
+			dav("resourcetype", "<d:principal/>"),
+			dav("displayname", utils.DavText(target.username)),
+			dav("principal-URL", utils.DavHref(principal.href())),
+			caldav("calendar-home-set", utils.DavHref(home.href())),
+		)
+	case davHomeKind:
+		properties = append(properties,
+			dav("resourcetype", "<d:collection/>"),
+			dav("owner", utils.DavHref(principal.href())),
+		)
+	case davCalendarKind:
+		properties = append(properties,
+			dav("resourcetype", "<d:collection/><c:calendar/>"),
+			dav("displayname", "Todos"),
+			dav("owner", utils.DavHref(principal.href())),
+			dav("current-user-privilege-set", "<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege>"+
+				"<d:privilege><d:write-content/></d:privilege><d:privilege><d:bind/></d:privilege><d:privilege><d:unbind/></d:privilege>"),
+			dav("supported-report-set", "<d:supported-report><d:report><c:calendar-query/></d:report></d:supported-report>"+
+				"<d:supported-report><d:report><c:calendar-multiget/></d:report></d:supported-report>"),
+			caldav("supported-calendar-component-set", `<c:comp name="VTODO"/>`),
+		)
+	case davObjectKind:
+		properties = append(properties,
+			dav("resourcetype", ""),
+			dav("getcontenttype", davObjectType),
+			dav("getetag", utils.DavText(todo.ETag)),
+			dav("getlastmodified", todo.LastModified.UTC().Format(http.TimeFormat)),
+			dav("getcontentlength", strconv.Itoa(len(todo.Calendar))),
+			caldav("calendar-data", utils.DavText(string(todo.Calendar))),
+		)
+	}
+	return properties
+}
+
+// davResponse answers the requested properties of a resource, all of them when none is named.
+func davResponse(href string, properties []utils.DavProperty, requested []xml.Name) *utils.DavResponse {
+	response := &utils.DavResponse{Href: href}
+	if len(requested) == 0 {
+		for _, property := range properties {
+			if !davHiddenProperties[property.Name] {
+				response.Found = append(response.Found, property)
+			}
+		}
+		return response
+	}
+
+	for _, name := range requested {
+		found := false
+		for _, property := range properties {
+			if property.Name == name {
+				response.Found = append(response.Found, property)
+				found = true
+				break
+			}
+		}
+		if !found {
+			response.Missing = append(response.Missing, name)
+		}
+	}
+	return response
+}
+
+// davQueriesTodos tells whether the filter of a calendar-query lets VTODOs through.
+func davQueriesTodos(query *utils.DavNode) bool {
+	filter := query.Child(utils.CalDavNamespace, "filter")
+	if filter == nil {
+		return true
+	}
+	for _, calendar := range filter.ChildrenNamed(utils.CalDavNamespace, "comp-filter") {
+		components := calendar.ChildrenNamed(utils.CalDavNamespace, "comp-filter")
+		if len(components) == 0 {
+			return true
+		}
+		for _, component := range components {
+			if strings.EqualFold(component.Attr("name"), "VTODO") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findDavTodo returns the resource of the collection with a name, or nil.
+func findDavTodo(collection *dto.DavCollectionDTO, name string) *dto.DavTodoDTO {
+	for _, todo := range collection.Todos {
+		if todo.Name == name {
+			return todo
+		}
+	}
+	return nil
+}
+
+// davFail answers the error of a write to a todo resource.
+func davFail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, dto.ErrToDoNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, dto.ErrDavPrecondition):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+	case errors.Is(err, dto.ErrDavInvalidCalendar):
+		c.Data(http.StatusForbidden, "application/xml; charset=utf-8",
+			utils.WriteDavError(xml.Name{Space: utils.CalDavNamespace, Local: "valid-calendar-data"}))
+	case errors.Is(err, dto.ErrDavUIDConflict):
+		c.Data(http.StatusForbidden, "application/xml; charset=utf-8",
+			utils.WriteDavError(xml.Name{Space: utils.CalDavNamespace, Local: "no-uid-conflict"}))
+	case errors.Is(err, dto.ErrToDoSubtasksOpen), errors.Is(err, dto.ErrToDoTitleAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/xml"
+	dto "go-feToDo/dtos"
+	"go-feToDo/utils"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// davFixtureCollection is the calendar collection the client requests are answered from.
+func davFixtureCollection() *dto.DavCollectionDTO {
+	return &dto.DavCollectionDTO{
+		CTag: "5f1d3c0a9b7e2d4c6a8e0f1b3d5c7a9e",
+		Todos: []*dto.DavTodoDTO{
+			{
+				Name:         "renew-passport.ics",
+				ETag:         `"0c8e1a7d2b4f6e9a1c3d5b7f9e2a4c6d"`,
+				LastModified: time.Date(2024, 6, 11, 8, 15, 0, 0, time.UTC),
+				Calendar: []byte("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:renew-passport\r\n" +
+					"SUMMARY:Renew passport\r\nSTATUS:NEEDS-ACTION\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"),
+			},
+			{
+				Name:         "quarterly report.ics",
+				ETag:         `"7b3e9f1c5a2d8e4b6c0a9f3e1d7b5c2a"`,
+				LastModified: time.Date(2024, 6, 3, 7, 13, 42, 0, time.UTC),
+				Calendar: []byte("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:quarterly-report\r\n" +
+					"SUMMARY:Sales & ops <Q2>\r\nSTATUS:IN-PROCESS\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"),
+			},
+		},
+	}
+}
+
+// readDavFixture reads a request in the format of a CalDAV client, or the answer expected
+// to it. The requests are written by hand after what each client sends, not captured.
+func readDavFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "caldav", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// checkDavMultistatus compares a multistatus with the expected one, after checking
+// it is well-formed XML.
+func checkDavMultistatus(t *testing.T, got []byte, golden string) {
+	t.Helper()
+	decoder := xml.NewDecoder(bytes.NewReader(got))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("multistatus isn't well-formed: %v\n%s", err, got)
+		}
+	}
+
+	want := readDavFixture(t, golden)
+	if !bytes.Equal(got, want) {
+		t.Errorf("multistatus =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDavPropfindResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  davTarget
+		depth   string
+		request string // empty for a request without a body
+		golden  string
+	}{
+		{
+			name:    "apple discovers the principal",
+			target:  davTarget{kind: davPrincipalKind, username: "alice"},
+			depth:   "0",
+			request: "propfind-principal-apple.xml",
+			golden:  "propfind-principal-apple.golden.xml",
+		},
+		{
+			name:    "davx5 lists the calendars of the home",
+			target:  davTarget{kind: davHomeKind, username: "alice"},
+			depth:   "1",
+			request: "propfind-home-davx5.xml",
+			golden:  "propfind-home-davx5.golden.xml",
+		},
+		{
+			name:    "thunderbird reads the calendar and its ctag",
+			target:  davTarget{kind: davCalendarKind, username: "alice"},
+			depth:   "0",
+			request: "propfind-calendar-thunderbird.xml",
+			golden:  "propfind-calendar-thunderbird.golden.xml",
+		},
+		{
+			name:    "thunderbird lists the etags",
+			target:  davTarget{kind: davCalendarKind, username: "alice"},
+			depth:   "1",
+			request: "propfind-etags-thunderbird.xml",
+			golden:  "propfind-etags-thunderbird.golden.xml",
+		},
+		{
+			name:   "every property of a todo",
+			target: davTarget{kind: davObjectKind, username: "alice", name: "quarterly report.ics"},
+			depth:  "0",
+			golden: "propfind-allprop-object.golden.xml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request []byte
+			if tt.request != "" {
+				request = readDavFixture(t, tt.request)
+			}
+			body, err := utils.ParseDavBody(bytes.NewReader(request))
+			if err != nil {
+				t.Fatalf("ParseDavBody() error = %v", err)
+			}
+
+			collection := davFixtureCollection()
+			var todo *dto.DavTodoDTO
+			if tt.target.kind == davObjectKind {
+				todo = findDavTodo(collection, tt.target.name)
+			}
+			responses := davPropfindResponses(tt.target, davRequestedProperties(body), tt.depth, collection, todo)
+			checkDavMultistatus(t, utils.WriteDavMultistatus(responses), tt.golden)
+		})
+	}
+}
+
+func TestDavReportResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		request string
+		golden  string
+	}{
+		{
+			name:    "davx5 queries the todos",
+			request: "report-query-davx5.xml",
+			golden:  "report-query-davx5.golden.xml",
+		},
+		{
+			name:    "thunderbird queries the events",
+			request: "report-query-events-thunderbird.xml",
+			golden:  "report-query-events-thunderbird.golden.xml",
+		},
+		{
+			name:    "davx5 reads some todos",
+			request: "report-multiget-davx5.xml",
+			golden:  "report-multiget-davx5.golden.xml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := utils.ParseDavBody(bytes.NewReader(readDavFixture(t, tt.request)))
+			if err != nil || body == nil {
+				t.Fatalf("ParseDavBody() = %v, %v", body, err)
+			}
+
+			target := davTarget{kind: davCalendarKind, username: "alice"}
+			responses := davReportResponses(target, body, davFixtureCollection())
+			checkDavMultistatus(t, utils.WriteDavMultistatus(responses), tt.golden)
+		})
+	}
+}
+
+func TestDavObjectName(t *testing.T) {
+	calendar := davTarget{kind: davCalendarKind, username: "alice"}
+	tests := []struct {
+		href string
+		want string
+	}{
+		{href: "/dav/calendars/alice/todos/renew-passport.ics", want: "renew-passport.ics"},
+		{href: "/dav/calendars/alice/todos/quarterly%20report.ics", want: "quarterly report.ics"},
+		{href: "https://todo.example.com/dav/calendars/alice/todos/renew-passport.ics", want: "renew-passport.ics"},
+		{href: "/dav/calendars/bob/todos/renew-passport.ics", want: ""},
+		{href: "/dav/calendars/alice/todos/nested/renew-passport.ics", want: ""},
+		{href: "/dav/calendars/alice/todos/", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.href, func(t *testing.T) {
+			if got := davObjectName(calendar, tt.href); got != tt.want {
+				t.Errorf("davObjectName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
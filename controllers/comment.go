@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"go-feToDo/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetComments handles GET requests to fetch a page of the comments of a todo
+func GetComments(c *gin.Context) {
+	todoID := c.Param("todoID")
+	var query dto.CommentQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	page, err := services.GetComments(todoID, &query, authorID.(string))
+	if err != nil {
+		c.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// CreateComment handles POST requests to comment on a todo
+func CreateComment(c *gin.Context) {
+	todoID := c.Param("todoID")
+	var commentDTO dto.CreateCommentDTO
+	if err := c.ShouldBindJSON(&commentDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(commentDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	comment, err := services.CreateComment(todoID, &commentDTO, authorID.(string))
+	if err != nil {
+		c.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UpdateComment handles PUT requests to edit a comment
+func UpdateComment(c *gin.Context) {
+	todoID := c.Param("todoID")
+	commentID := c.Param("commentID")
+	var updateDTO dto.UpdateCommentDTO
+	if err := c.ShouldBindJSON(&updateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(updateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	comment, err := services.UpdateComment(todoID, commentID, &updateDTO, authorID.(string))
+	if err != nil {
+		c.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment handles DELETE requests to delete a comment
+func DeleteComment(c *gin.Context) {
+	todoID := c.Param("todoID")
+	commentID := c.Param("commentID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	if err := services.DeleteComment(todoID, commentID, authorID.(string)); err != nil {
+		c.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// commentErrorStatus maps the errors of the comment services to a status code.
+func commentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrToDoNotFound), errors.Is(err, dto.ErrCommentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dto.ErrUnauthToDo), errors.Is(err, dto.ErrUnauthComment), errors.Is(err, dto.ErrCommentEditWindow):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrInvalidCursor), errors.Is(err, dto.ErrCommentParent):
+		return http.StatusBadRequest
+	case errors.Is(err, dto.ErrCommentDeleted):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"go-feToDo/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddDependency handles POST requests to make a todo wait for another one
+func AddDependency(c *gin.Context) {
+	todoID := c.Param("todoID")
+	var dependencyDTO dto.AddDependencyDTO
+	if err := c.ShouldBindJSON(&dependencyDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(dependencyDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todo, err := services.AddDependency(todoID, &dependencyDTO, authorID.(string))
+	if err != nil {
+		c.JSON(dependencyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, todo)
+}
+
+// RemoveDependency handles DELETE requests to stop a todo from waiting for one of its blockers
+func RemoveDependency(c *gin.Context) {
+	todoID := c.Param("todoID")
+	blockerID := c.Param("blockerID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todo, err := services.RemoveDependency(todoID, blockerID, authorID.(string))
+	if err != nil {
+		c.JSON(dependencyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// GetDependencyGraph handles GET requests to fetch the dependencies of the todos of a project
+func GetDependencyGraph(c *gin.Context) {
+	projectID := c.Param("projectID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	graph, err := services.GetDependencyGraph(projectID, authorID.(string))
+	if err != nil {
+		c.JSON(dependencyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
+// dependencyErrorStatus maps the errors of the dependency services to a status code.
+func dependencyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrToDoNotFound), errors.Is(err, dto.ErrProjectNotFound), errors.Is(err, dto.ErrDependencyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dto.ErrUnauthToDo), errors.Is(err, dto.ErrUnauthProject):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrDependencyExists), errors.Is(err, dto.ErrDependencyCycle):
+		return http.StatusConflict
+	case errors.Is(err, dto.ErrAuthIdConv):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
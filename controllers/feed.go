@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTodoFeed handles GET requests to the calendar feed of a user, authenticated by its secret token
+func GetTodoFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	feed, err := services.GetTodoFeed(token, c.GetHeader("If-None-Match"))
+	if errors.Is(err, dto.ErrFeedNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", feed.ETag)
+	c.Header("Cache-Control", "private, no-cache")
+	if feed.NotModified {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", feed.Calendar)
+}
@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"go-feToDo/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShareTodo handles POST requests to invite a user to a todo
+func ShareTodo(c *gin.Context) {
+	todoID := c.Param("todoID")
+	var shareDTO dto.CreateShareDTO
+	if err := c.ShouldBindJSON(&shareDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(shareDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	share, err := services.ShareTodo(todoID, &shareDTO, authorID.(string))
+	if err != nil {
+		c.JSON(shareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, share)
+}
+
+// ShareProject handles POST requests to invite a user to a project
+func ShareProject(c *gin.Context) {
+	projectID := c.Param("projectID")
+	var shareDTO dto.CreateShareDTO
+	if err := c.ShouldBindJSON(&shareDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(shareDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	share, err := services.ShareProject(projectID, &shareDTO, authorID.(string))
+	if err != nil {
+		c.JSON(shareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, share)
+}
+
+// GetTodoShares handles GET requests to list the shares of a todo
+func GetTodoShares(c *gin.Context) {
+	todoID := c.Param("todoID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	shares, err := services.GetTodoShares(todoID, authorID.(string))
+	if err != nil {
+		c.JSON(shareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shares": shares,
+	})
+}
+
+// GetProjectShares handles GET requests to list the shares of a project
+func GetProjectShares(c *gin.Context) {
+	projectID := c.Param("projectID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	shares, err := services.GetProjectShares(projectID, authorID.(string))
+	if err != nil {
+		c.JSON(shareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shares": shares,
+	})
+}
+
+// RevokeTodoShare handles DELETE requests to remove a share of a todo
+func RevokeTodoShare(c *gin.Context) {
+	todoID := c.Param("todoID")
+	shareID := c.Param("shareID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	if err := services.RevokeTodoShare(todoID, shareID, authorID.(string)); err != nil {
+		c.JSON(shareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RevokeProjectShare handles DELETE requests to remove a share of a project
+func RevokeProjectShare(c *gin.Context) {
+	projectID := c.Param("projectID")
+	shareID := c.Param("shareID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	if err := services.RevokeProjectShare(projectID, shareID, authorID.(string)); err != nil {
+		c.JSON(shareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetUserShares handles GET requests to list the invitations of a user
+func GetUserShares(c *gin.Context) {
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	shares, err := services.GetUserShares(authorID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shares": shares,
+	})
+}
+
+// AcceptShare handles POST requests to accept an invitation
+func AcceptShare(c *gin.Context) {
+	shareID := c.Param("shareID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	share, err := services.AcceptShare(shareID, authorID.(string))
+	if err != nil {
+		c.JSON(shareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, share)
+}
+
+// DeclineShare handles DELETE requests to decline an invitation or leave a share
+func DeclineShare(c *gin.Context) {
+	shareID := c.Param("shareID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	if err := services.DeclineShare(shareID, authorID.(string)); err != nil {
+		c.JSON(shareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetSharedTodos handles GET requests to fetch the todos shared with a user
+func GetSharedTodos(c *gin.Context) {
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todos, err := services.GetSharedTodos(authorID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"todos": todos,
+	})
+}
+
+// shareErrorStatus maps the errors of the sharing services to a status code.
+func shareErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrToDoNotFound), errors.Is(err, dto.ErrProjectNotFound),
+		errors.Is(err, dto.ErrShareNotFound), errors.Is(err, dto.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dto.ErrUnauthToDo), errors.Is(err, dto.ErrUnauthProject), errors.Is(err, dto.ErrUnauthShare):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrShareSelf):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"go-feToDo/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddSubtask handles POST requests to add a subtask to a todo
+func AddSubtask(c *gin.Context) {
+	todoID := c.Param("todoID")
+	var subtaskDTO dto.CreateSubtaskDTO
+	if err := c.ShouldBindJSON(&subtaskDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(subtaskDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todo, err := services.AddSubtask(todoID, &subtaskDTO, authorID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, todo)
+}
+
+// UpdateSubtask handles PUT requests to edit a subtask of a todo
+func UpdateSubtask(c *gin.Context) {
+	todoID := c.Param("todoID")
+	subtaskID := c.Param("subtaskID")
+	var updateDTO dto.UpdateSubtaskDTO
+	if err := c.ShouldBindJSON(&updateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(updateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todo, err := services.UpdateSubtask(todoID, subtaskID, &updateDTO, authorID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// ToggleSubtask handles POST requests to check or uncheck a subtask
+func ToggleSubtask(c *gin.Context) {
+	todoID := c.Param("todoID")
+	subtaskID := c.Param("subtaskID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todo, err := services.ToggleSubtask(todoID, subtaskID, authorID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// ReorderSubtasks handles POST requests to change the order of the subtasks of a todo
+func ReorderSubtasks(c *gin.Context) {
+	todoID := c.Param("todoID")
+	var reorderDTO dto.ReorderSubtasksDTO
+	if err := c.ShouldBindJSON(&reorderDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(reorderDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todo, err := services.ReorderSubtasks(todoID, &reorderDTO, authorID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// DeleteSubtask handles DELETE requests to remove a subtask from a todo
+func DeleteSubtask(c *gin.Context) {
+	todoID := c.Param("todoID")
+	subtaskID := c.Param("subtaskID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	err := services.DeleteSubtask(todoID, subtaskID, authorID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
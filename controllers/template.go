@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"go-feToDo/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTemplates handles GET requests to fetch the templates of a user
+func GetTemplates(c *gin.Context) {
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	templates, err := services.GetTemplates(authorID.(string))
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+	})
+}
+
+// GetTemplateById handles GET requests to fetch a template by its ID
+func GetTemplateById(c *gin.Context) {
+	templateID := c.Param("templateID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	template, err := services.GetTemplateById(templateID, authorID.(string))
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// CreateTemplate handles POST requests to create a new template
+func CreateTemplate(c *gin.Context) {
+	var templateDTO dto.TemplateDTO
+	if err := c.ShouldBindJSON(&templateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(templateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	template, err := services.CreateTemplate(&templateDTO, authorID.(string))
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// SaveTodoAsTemplate handles POST requests to save a todo as a template
+func SaveTodoAsTemplate(c *gin.Context) {
+	todoID := c.Param("todoID")
+	var saveDTO dto.SaveAsTemplateDTO
+	if err := c.ShouldBindJSON(&saveDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(saveDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	template, err := services.SaveTodoAsTemplate(todoID, &saveDTO, authorID.(string))
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateTemplate handles PUT requests to replace the content of a template
+func UpdateTemplate(c *gin.Context) {
+	templateID := c.Param("templateID")
+	var templateDTO dto.TemplateDTO
+	if err := c.ShouldBindJSON(&templateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(templateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	template, err := services.UpdateTemplate(templateID, &templateDTO, authorID.(string))
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate handles DELETE requests to delete a template
+func DeleteTemplate(c *gin.Context) {
+	templateID := c.Param("templateID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	if err := services.DeleteTemplate(templateID, authorID.(string)); err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// InstantiateTemplate handles POST requests to create a todo from a template
+func InstantiateTemplate(c *gin.Context) {
+	templateID := c.Param("templateID")
+	var instantiateDTO dto.InstantiateTemplateDTO
+	if err := c.ShouldBindJSON(&instantiateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(instantiateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todo, err := services.InstantiateTemplate(templateID, &instantiateDTO, authorID.(string))
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, todo)
+}
+
+// templateErrorStatus maps the errors of the template services to a status code.
+func templateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrTemplateNotFound), errors.Is(err, dto.ErrToDoNotFound), errors.Is(err, dto.ErrTagNotFound), errors.Is(err, dto.ErrProjectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dto.ErrUnauthTemplate), errors.Is(err, dto.ErrUnauthToDo), errors.Is(err, dto.ErrUnauthProject):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrToDoTitleAlreadyExists), errors.Is(err, dto.ErrProjectArchived):
+		return http.StatusConflict
+	case errors.Is(err, dto.ErrTemplateVariables), errors.Is(err, dto.ErrTemplateTitle), errors.Is(err, dto.ErrToDoDueBeforeStart), errors.Is(err, dto.ErrAuthIdConv):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
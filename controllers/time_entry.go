@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"go-feToDo/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartTimer handles POST requests to start a timer on a todo
+func StartTimer(c *gin.Context) {
+	todoID := c.Param("todoID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	entry, err := services.StartTimer(todoID, authorID.(string))
+	if err != nil {
+		c.JSON(timeEntryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// StopTimer handles POST requests to stop the timer running on a todo
+func StopTimer(c *gin.Context) {
+	todoID := c.Param("todoID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	entry, err := services.StopTimer(todoID, authorID.(string))
+	if err != nil {
+		c.JSON(timeEntryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetTimeEntries handles GET requests to fetch the time entries of a todo
+func GetTimeEntries(c *gin.Context) {
+	todoID := c.Param("todoID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	entries, err := services.GetTimeEntries(todoID, authorID.(string))
+	if err != nil {
+		c.JSON(timeEntryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"time_entries": entries,
+	})
+}
+
+// CreateTimeEntry handles POST requests to record time spent on a todo
+func CreateTimeEntry(c *gin.Context) {
+	todoID := c.Param("todoID")
+	var entryDTO dto.CreateTimeEntryDTO
+	if err := c.ShouldBindJSON(&entryDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(entryDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	entry, err := services.CreateTimeEntry(todoID, &entryDTO, authorID.(string))
+	if err != nil {
+		c.JSON(timeEntryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateTimeEntry handles PUT requests to correct a time entry
+func UpdateTimeEntry(c *gin.Context) {
+	todoID := c.Param("todoID")
+	entryID := c.Param("entryID")
+	var updateDTO dto.UpdateTimeEntryDTO
+	if err := c.ShouldBindJSON(&updateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(updateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	entry, err := services.UpdateTimeEntry(todoID, entryID, &updateDTO, authorID.(string))
+	if err != nil {
+		c.JSON(timeEntryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteTimeEntry handles DELETE requests to remove a time entry
+func DeleteTimeEntry(c *gin.Context) {
+	todoID := c.Param("todoID")
+	entryID := c.Param("entryID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	if err := services.DeleteTimeEntry(todoID, entryID, authorID.(string)); err != nil {
+		c.JSON(timeEntryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetTimeReport handles GET requests to sum up the time a user spent on todos, as JSON or CSV
+func GetTimeReport(c *gin.Context) {
+	var query dto.TimeReportQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	report, err := services.GetTimeReport(&query, authorID.(string))
+	if err != nil {
+		c.JSON(timeEntryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if query.Format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="time-report.csv"`)
+		if err := utils.WriteTimeReportCSV(c.Writer, report); err != nil {
+			_ = c.Error(err)
+			c.Abort()
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// timeEntryErrorStatus maps the errors of the time tracking services to a status code.
+func timeEntryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrToDoNotFound), errors.Is(err, dto.ErrTimeEntryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dto.ErrUnauthToDo), errors.Is(err, dto.ErrUnauthTimeEntry):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrTimeEntryDates), errors.Is(err, dto.ErrInvalidReqPayload), errors.Is(err, dto.ErrAuthIdConv):
+		return http.StatusBadRequest
+	case errors.Is(err, dto.ErrTimerRunning), errors.Is(err, dto.ErrTimerNotRunning):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
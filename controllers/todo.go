@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	dto "go-feToDo/dtos"
 	"go-feToDo/services"
 	"go-feToDo/utils"
@@ -9,60 +10,171 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// maxImportSize bounds the size of an import upload.
+const maxImportSize = 5 << 20
+
 // GetAllToDo handles GET requests to fetch all todos for a user
 func GetAllToDo(c *gin.Context) {
+	listToDo(c, services.GetUserAllToDo)
+}
+
+// GetActiveToDo handles GET requests to fetch active todos for a user
+func GetActiveToDo(c *gin.Context) {
+	listToDo(c, services.GetUserActiveToDo)
+}
+
+// GetTrashToDo handles GET requests to fetch the trashed todos of a user
+func GetTrashToDo(c *gin.Context) {
+	listToDo(c, services.GetTrashedTodos)
+}
+
+// listToDo binds the list query shared by the todo lists and responds with a page
+func listToDo(c *gin.Context, list func(string, *dto.TodoQueryDTO) (*dto.TodoPageDTO, error)) {
+	var query dto.TodoQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
 	authorID, exists := c.Get("authorID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
 		return
 	}
 
-	todos, err := services.GetUserAllToDo(authorID.(string))
+	page, err := list(authorID.(string), &query)
+	if errors.Is(err, dto.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"todos": todos,
-	})
+	c.JSON(http.StatusOK, page)
 }
 
-// GetActiveToDo handles GET requests to fetch active todos for a user
-func GetActiveToDo(c *gin.Context) {
+// SearchTodos handles GET requests to search the todos of a user
+func SearchTodos(c *gin.Context) {
+	var query dto.SearchQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
 	authorID, exists := c.Get("authorID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
 		return
 	}
 
-	todos, err := services.GetUserActiveToDo(authorID.(string))
+	results, err := services.SearchTodos(&query, authorID.(string))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"todos": todos,
+		"results": results,
 	})
 }
 
-func GetTrashToDo(c *gin.Context) {
+// ExportTodos handles GET requests to download the todos of a user as a file
+func ExportTodos(c *gin.Context) {
+	var query dto.ExportQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
 	authorID, exists := c.Get("authorID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
 		return
 	}
 
-	todos, err := services.GetTrashedTodos(authorID.(string))
+	contentType, fileName := utils.ExportContentType(query.Format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+
+	err := services.ExportTodos(&query, authorID.(string), c.Writer)
+	if err != nil && !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		// The export is already streaming, the status can't change anymore
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// ImportTodos handles POST requests to create todos from an uploaded file
+func ImportTodos(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var query dto.ImportQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"todos": todos,
-	})
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if query.Format == "" {
+		query.Format = utils.ImportFormat(fileHeader.Filename)
+	}
+	if query.Format == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrImportFormat.Error()})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrImportFile.Error()})
+		return
+	}
+	defer file.Close()
+
+	report, err := services.ImportTodos(file, &query, authorID.(string))
+	if errors.Is(err, dto.ErrImportFile) || errors.Is(err, dto.ErrImportTooManyRows) || errors.Is(err, dto.ErrImportFormat) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
 }
 
 // GetTodoById handles GET requests to fetch a specific todo by ID for a user
@@ -102,6 +214,10 @@ func CreateTodo(c *gin.Context) {
 	}
 
 	todo, err := services.CreateTodo(&todoDTO, authorID.(string))
+	if isRecurrenceValidationError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -130,6 +246,46 @@ func UpdateTodo(c *gin.Context) {
 	}
 
 	todo, err := services.UpdateTodo(todoID, &updateDTO, authorID.(string))
+	if isRecurrenceValidationError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+	if errors.Is(err, dto.ErrToDoStatusUnknown) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, dto.ErrToDoStatusTransition) || errors.Is(err, dto.ErrToDoBlocked) || errors.Is(err, dto.ErrToDoSubtasksOpen) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// MoveTodo handles POST requests to move a todo between two neighbours of the list
+func MoveTodo(c *gin.Context) {
+	todoID := c.Param("todoID")
+	var moveDTO dto.MoveTodoDTO
+	if err := c.ShouldBindJSON(&moveDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(moveDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todo, err := services.MoveTodo(todoID, &moveDTO, authorID.(string))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -156,6 +312,99 @@ func SoftDeleteTodo(c *gin.Context) {
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// RestoreTodo handles POST requests to bring a todo back from the trash
+func RestoreTodo(c *gin.Context) {
+	todoID := c.Param("todoID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todo, err := services.RestoreTodo(todoID, authorID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// RestoreTodos handles POST requests to bring several todos back from the trash
+func RestoreTodos(c *gin.Context) {
+	var restoreDTO dto.RestoreTodosDTO
+	if err := c.ShouldBindJSON(&restoreDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(restoreDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	todos, err := services.RestoreTodos(&restoreDTO, authorID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"todos": todos,
+	})
+}
+
+// BulkUpdateTodos handles POST requests to apply operations to many todos at once
+func BulkUpdateTodos(c *gin.Context) {
+	var bulkDTO dto.BulkTodosDTO
+	if err := c.ShouldBindJSON(&bulkDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(bulkDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	results, err := services.BulkUpdateTodos(&bulkDTO, authorID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+	})
+}
+
+// EmptyTrash handles DELETE requests to permanently delete every trashed todo of a user
+func EmptyTrash(c *gin.Context) {
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	err := services.EmptyTrash(authorID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
 // DeleteTodo handles DELETE requests to permanently delete a todo
 func DeleteTodo(c *gin.Context) {
 	todoID := c.Param("todoID")
@@ -173,3 +422,9 @@ func DeleteTodo(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// isRecurrenceValidationError reports whether the recurrence of a todo payload was refused
+func isRecurrenceValidationError(err error) bool {
+	return errors.Is(err, dto.ErrRecurrenceInvalidRule) || errors.Is(err, dto.ErrRecurrenceFrequency) ||
+		errors.Is(err, dto.ErrRecurrenceNeedsDueDate)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	dto "go-feToDo/dtos"
 	"go-feToDo/services"
 	"go-feToDo/utils"
@@ -88,3 +89,110 @@ func DeleteUser(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// CreateFeedToken handles POST requests to create or replace the secret URL of the calendar feed
+func CreateFeedToken(c *gin.Context) {
+	userID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	token, err := services.CreateFeedToken(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	c.JSON(http.StatusCreated, dto.FeedTokenResponseDTO{
+		Token: token,
+		URL:   scheme + "://" + c.Request.Host + "/feeds/" + token + ".ics",
+	})
+}
+
+// RevokeFeedToken handles DELETE requests to turn the calendar feed off
+func RevokeFeedToken(c *gin.Context) {
+	userID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	err := services.RevokeFeedToken(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetAppPasswords handles GET requests to list the app passwords of the user
+func GetAppPasswords(c *gin.Context) {
+	userID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	passwords, err := services.GetAppPasswords(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, passwords)
+}
+
+// CreateAppPassword handles POST requests to create an app password for a CalDAV client
+func CreateAppPassword(c *gin.Context) {
+	var passwordDTO dto.CreateAppPasswordDTO
+	if err := c.ShouldBindJSON(&passwordDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(passwordDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	userID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	password, err := services.CreateAppPassword(userID.(string), &passwordDTO)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, password)
+}
+
+// DeleteAppPassword handles DELETE requests to revoke an app password
+func DeleteAppPassword(c *gin.Context) {
+	passwordID := c.Param("passwordID")
+	userID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	err := services.DeleteAppPassword(passwordID, userID.(string))
+	if errors.Is(err, dto.ErrAppPasswordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"go-feToDo/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWorkflows handles GET requests to fetch the workflows of a user
+func GetWorkflows(c *gin.Context) {
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	workflows, err := services.GetWorkflows(authorID.(string))
+	if err != nil {
+		c.JSON(workflowErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"workflows": workflows,
+	})
+}
+
+// CreateWorkflow handles POST requests to create a new workflow
+func CreateWorkflow(c *gin.Context) {
+	var workflowDTO dto.WorkflowDTO
+	if err := c.ShouldBindJSON(&workflowDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(workflowDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	workflow, err := services.CreateWorkflow(&workflowDTO, authorID.(string))
+	if err != nil {
+		c.JSON(workflowErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, workflow)
+}
+
+// UpdateWorkflow handles PUT requests to replace the statuses and transitions of a workflow
+func UpdateWorkflow(c *gin.Context) {
+	workflowID := c.Param("workflowID")
+	var workflowDTO dto.WorkflowDTO
+	if err := c.ShouldBindJSON(&workflowDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+	if err := validate.Struct(workflowDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"validation_errors": utils.ParseValidationErrors(err)})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	workflow, err := services.UpdateWorkflow(workflowID, &workflowDTO, authorID.(string))
+	if err != nil {
+		c.JSON(workflowErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
+
+// DeleteWorkflow handles DELETE requests to delete a workflow
+func DeleteWorkflow(c *gin.Context) {
+	workflowID := c.Param("workflowID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	if err := services.DeleteWorkflow(workflowID, authorID.(string)); err != nil {
+		c.JSON(workflowErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// SetDefaultWorkflow handles PUT requests to make a workflow the default one of a user
+func SetDefaultWorkflow(c *gin.Context) {
+	workflowID := c.Param("workflowID")
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	workflow, err := services.SetDefaultWorkflow(workflowID, authorID.(string))
+	if err != nil {
+		c.JSON(workflowErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
+
+// SetProjectWorkflow handles PUT requests to choose the workflow of a project
+func SetProjectWorkflow(c *gin.Context) {
+	projectID := c.Param("projectID")
+	var workflowDTO dto.SetProjectWorkflowDTO
+	if err := c.ShouldBindJSON(&workflowDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": dto.ErrInvalidReqPayload.Error()})
+		return
+	}
+
+	authorID, exists := c.Get("authorID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": dto.ErrJWTUnauthorizedAccess.Error()})
+		return
+	}
+
+	project, err := services.SetProjectWorkflow(projectID, &workflowDTO, authorID.(string))
+	if err != nil {
+		c.JSON(workflowErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// workflowErrorStatus maps the errors of the workflow services to a status code.
+func workflowErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrWorkflowNotFound), errors.Is(err, dto.ErrProjectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dto.ErrUnauthWorkflow), errors.Is(err, dto.ErrUnauthProject):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrWorkflowStatuses), errors.Is(err, dto.ErrWorkflowTransition), errors.Is(err, dto.ErrAuthIdConv):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
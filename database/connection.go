@@ -16,6 +16,29 @@ var (
 	once sync.Once
 )
 
+// SchemaModels are the models AutoMigrate creates the tables of. The tests of the
+// services create them on SQLite.
+var SchemaModels = []any{
+	&models.User{},
+	&models.Todo{},
+	&models.Tag{},
+	&models.Project{},
+	&models.Subtask{},
+	&models.Recurrence{},
+	&models.RecurrenceException{},
+	&models.AppPassword{},
+	&models.TodoShare{},
+	&models.TodoActivity{},
+	&models.Comment{},
+	&models.Attachment{},
+	&models.Workflow{},
+	&models.WorkflowStatus{},
+	&models.WorkflowTransition{},
+	&models.TimeEntry{},
+	&models.Template{},
+	&models.TemplateSubtask{},
+}
+
 // Connect initializes the database connection
 func Connect() *gorm.DB {
 	once.Do(func() {
@@ -49,13 +72,16 @@ func AutoMigrate(db *gorm.DB) {
 	if cfg.Env == "dev" {
 		log.Println("Running auto migrations for development...")
 
-		err := db.AutoMigrate(
-			&models.User{},
-			&models.Todo{},
-		)
+		err := db.AutoMigrate(SchemaModels...)
 		if err != nil {
 			log.Fatalf("Failed to auto migrate: %v", err)
 		}
+		if err := MigrateSearch(db); err != nil {
+			log.Fatalf("Failed to migrate the search vector: %v", err)
+		}
+		if err := migrateWorkflows(db); err != nil {
+			log.Fatalf("Failed to migrate the todo statuses: %v", err)
+		}
 
 		log.Println("Auto migrations completed successfully!")
 	} else {
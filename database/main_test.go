@@ -0,0 +1,49 @@
+package database
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB is the Postgres database the migrations are tested on, opened from
+// TEST_DATABASE_DSN. The tests are skipped when it isn't set.
+var testDB *gorm.DB
+
+func TestMain(m *testing.M) {
+	if dsn := os.Getenv("TEST_DATABASE_DSN"); dsn != "" {
+		connection, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if err != nil {
+			log.Fatalf("Failed to connect to the test database: %v", err)
+		}
+		testDB = connection
+	}
+	os.Exit(m.Run())
+}
+
+// newTestSchema starts a transaction working in a schema of its own. Postgres rolls back
+// schema changes too, so the transaction is rolled back after the test with everything
+// it created, and the database is left as it was.
+func newTestSchema(t *testing.T) *gorm.DB {
+	t.Helper()
+	if testDB == nil {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	tx := testDB.Begin()
+	if tx.Error != nil {
+		t.Fatalf("Begin() error = %v", tx.Error)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+
+	if err := tx.Exec("CREATE SCHEMA migration_test").Error; err != nil {
+		t.Fatalf("creating the schema: %v", err)
+	}
+	if err := tx.Exec("SET LOCAL search_path TO migration_test").Error; err != nil {
+		t.Fatalf("setting the search path: %v", err)
+	}
+	return tx
+}
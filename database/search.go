@@ -0,0 +1,24 @@
+package database
+
+import "gorm.io/gorm"
+
+// SearchConfig is the text search configuration of the todos search vector.
+// "simple" doesn't stem words, so it doesn't favor a language over another.
+const SearchConfig = "simple"
+
+// MigrateSearch adds the full-text search vector of the todos, kept up to date by
+// Postgres as a generated column, and its GIN index. Other stores search without it.
+// The tests of the search run it on a schema of their own.
+func MigrateSearch(db *gorm.DB) error {
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+	if err := db.Exec(`ALTER TABLE todos ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('` + SearchConfig + `', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('` + SearchConfig + `', coalesce(description, '')), 'B')
+		) STORED`).Error; err != nil {
+		return err
+	}
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_todos_search_vector ON todos USING GIN (search_vector)").Error
+}
@@ -0,0 +1,14 @@
+package database
+
+import "gorm.io/gorm"
+
+// migrateWorkflows moves the todos stored before workflows existed onto the default
+// workflow. Only rows without a status category are touched, so it runs once per row.
+// The category is read from the status before the update, as Postgres evaluates every
+// SET expression against the old row.
+func migrateWorkflows(db *gorm.DB) error {
+	return db.Exec(`UPDATE todos SET
+		status = CASE WHEN status IN ('pending', 'in_progress', 'completed') THEN status ELSE 'pending' END,
+		status_category = CASE status WHEN 'completed' THEN 'done' WHEN 'in_progress' THEN 'doing' ELSE 'todo' END
+		WHERE status_category IS NULL OR status_category = ''`).Error
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"testing"
+)
+
+func TestMigrateWorkflows(t *testing.T) {
+	tx := newTestSchema(t)
+
+	// Bring the todos table back to its shape before workflows: no status category and
+	// the three fixed statuses
+	if err := tx.AutoMigrate(SchemaModels...); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	if err := tx.Exec("ALTER TABLE todos DROP COLUMN status_category").Error; err != nil {
+		t.Fatal(err)
+	}
+	author := models.User{Username: "ada", Email: "ada@example.com", Password: "secret"}
+	if err := tx.Create(&author).Error; err != nil {
+		t.Fatal(err)
+	}
+	err := tx.Exec(`INSERT INTO todos (title, author_id, status) VALUES
+		('Pending', ?, 'pending'), ('In progress', ?, 'in_progress'), ('Completed', ?, 'completed'), ('Unknown', ?, 'archived')`,
+		author.ID, author.ID, author.ID, author.ID).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Upgrade like AutoMigrate does: the new column is NULL on the existing rows
+	if err := tx.AutoMigrate(SchemaModels...); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	if err := migrateWorkflows(tx); err != nil {
+		t.Fatalf("migrateWorkflows() error = %v", err)
+	}
+	// A todo moved to a custom status afterwards isn't touched by the next runs
+	review := models.Todo{Title: "Review", AuthorID: author.ID, Status: "review", StatusCategory: enums.StatusCategoryDoing}
+	if err := tx.Create(&review).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateWorkflows(tx); err != nil {
+		t.Fatalf("migrateWorkflows() second run error = %v", err)
+	}
+
+	want := map[string]models.Todo{
+		"Pending":     {Status: enums.TodoStatusPending, StatusCategory: enums.StatusCategoryTodo},
+		"In progress": {Status: enums.TodoStatusInProgress, StatusCategory: enums.StatusCategoryDoing},
+		"Completed":   {Status: enums.TodoStatusCompleted, StatusCategory: enums.StatusCategoryDone},
+		"Unknown":     {Status: enums.TodoStatusPending, StatusCategory: enums.StatusCategoryTodo},
+		"Review":      {Status: "review", StatusCategory: enums.StatusCategoryDoing},
+	}
+	var todos []models.Todo
+	if err := tx.Find(&todos).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(todos) != len(want) {
+		t.Fatalf("%d todos, want %d", len(todos), len(want))
+	}
+	for _, todo := range todos {
+		w := want[todo.Title]
+		if todo.Status != w.Status || todo.StatusCategory != w.StatusCategory {
+			t.Errorf("%q: status = %q, %q, want %q, %q", todo.Title, todo.Status, todo.StatusCategory, w.Status, w.StatusCategory)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"go-feToDo/enums"
+	"time"
+)
+
+// FieldChangeDTO represents the old and new value of a field changed on a todo.
+type FieldChangeDTO struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// HistoryQueryDTO represents the query parameters for reading the history of a todo.
+type HistoryQueryDTO struct {
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"` // next_cursor of the previous page
+}
+
+// TodoActivityResponseDTO represents an entry of the history of a todo. Actor is null
+// when the app made the change itself.
+type TodoActivityResponseDTO struct {
+	ID        uint                      `json:"id"`
+	TodoID    uint                      `json:"todo_id"`
+	Actor     *ShareUserDTO             `json:"actor"`
+	Action    enums.TodoActivityAction  `json:"action"`
+	Changes   map[string]FieldChangeDTO `json:"changes"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+// HistoryPageDTO represents a page of the history of a todo, newest first. NextCursor is
+// null on the last page.
+type HistoryPageDTO struct {
+	Entries    []*TodoActivityResponseDTO `json:"entries"`
+	NextCursor *string                    `json:"next_cursor"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+// CreateAppPasswordDTO represents the payload for creating an app password.
+type CreateAppPasswordDTO struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// AppPasswordResponseDTO represents an app password, without the password itself.
+type AppPasswordResponseDTO struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// AppPasswordCreatedDTO represents a new app password, the password is only shown here.
+type AppPasswordCreatedDTO struct {
+	AppPasswordResponseDTO
+	Password string `json:"password"`
+}
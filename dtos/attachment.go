@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// AttachmentResponseDTO represents a file attached to a todo.
+type AttachmentResponseDTO struct {
+	ID          uint         `json:"id"`
+	TodoID      uint         `json:"todo_id"`
+	Uploader    ShareUserDTO `json:"uploader"`
+	FileName    string       `json:"file_name"`
+	ContentType string       `json:"content_type"`
+	Size        int64        `json:"size"` // bytes
+	CreatedAt   time.Time    `json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "go-feToDo/enums"
+
+// BoardQueryDTO represents the query params of a board. Without a project, the board
+// holds the todos following the default workflow of the user.
+type BoardQueryDTO struct {
+	ProjectID uint `form:"project_id"`
+}
+
+// BoardColumnDTO represents the column of a status on a board, its todos in order.
+type BoardColumnDTO struct {
+	Status   enums.TodoStatus     `json:"status"`
+	Category enums.StatusCategory `json:"category"`
+	WIPLimit int                  `json:"wip_limit"`
+	Todos    []*TodoResponseDTO   `json:"todos"`
+}
+
+// BoardResponseDTO represents the board of a workflow, a column for each of its statuses.
+type BoardResponseDTO struct {
+	Workflow  *WorkflowResponseDTO `json:"workflow"`
+	ProjectID *uint                `json:"project_id"`
+	Columns   []*BoardColumnDTO    `json:"columns"`
+}
+
+// MoveCardDTO represents the payload for moving a todo to a column of its board, between
+// the todo that will come before it and the one that will come after it.
+type MoveCardDTO struct {
+	TodoID   uint             `json:"todo_id" validate:"required"`
+	Status   enums.TodoStatus `json:"status" validate:"required,max=50"`
+	BeforeID *uint            `json:"before_id"`
+	AfterID  *uint            `json:"after_id"`
+	// Force completes the todo even though some of its blockers are still open
+	Force bool `json:"force,omitempty"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "go-feToDo/enums"
+
+// bulk operations payload, operations are applied in order.
+type BulkTodosDTO struct {
+	Operations []BulkOperationDTO `json:"operations" validate:"required,min=1,max=50,dive"`
+}
+
+// one operation applied to many todos.
+type BulkOperationDTO struct {
+	Op        string            `json:"op" validate:"required,oneof=update_status trash restore delete move_project add_tag"`
+	TodoIDs   []uint            `json:"todo_ids" validate:"required,min=1,max=500"`
+	Status    *enums.TodoStatus `json:"status,omitempty" validate:"required_if=Op update_status,omitempty,max=50"`
+	ProjectID *uint             `json:"project_id,omitempty" validate:"required_if=Op move_project"` // 0 removes the todos from their project
+	TagID     *uint             `json:"tag_id,omitempty" validate:"required_if=Op add_tag"`
+	// CompleteSubtasks and Force apply to update_status, like on a single todo update
+	CompleteSubtasks bool `json:"complete_subtasks,omitempty"`
+	Force            bool `json:"force,omitempty"`
+}
+
+// result of an operation on one todo.
+type BulkResultDTO struct {
+	Operation int    `json:"operation"` // index of the operation in the payload
+	Op        string `json:"op"`
+	TodoID    uint   `json:"todo_id"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+}
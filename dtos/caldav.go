@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+// DavTodoDTO represents a todo as a calendar object resource of the CalDAV collection.
+type DavTodoDTO struct {
+	Name         string
+	ETag         string
+	LastModified time.Time
+	Calendar     []byte
+}
+
+// DavCollectionDTO represents the CalDAV collection of the todos of a user.
+type DavCollectionDTO struct {
+	CTag  string
+	Todos []*DavTodoDTO
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// CreateCommentDTO represents the payload for posting a comment on a todo.
+type CreateCommentDTO struct {
+	Body     string `json:"body" validate:"required,max=10000"` // Markdown
+	ParentID *uint  `json:"parent_id,omitempty"`                // comment to reply to
+}
+
+// UpdateCommentDTO represents the payload for editing a comment.
+type UpdateCommentDTO struct {
+	Body string `json:"body" validate:"required,max=10000"`
+}
+
+// CommentQueryDTO represents the query parameters for listing the comments of a todo.
+type CommentQueryDTO struct {
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"` // next_cursor of the previous page
+}
+
+// CommentResponseDTO represents a comment, a deleted one has no body.
+type CommentResponseDTO struct {
+	ID        uint         `json:"id"`
+	TodoID    uint         `json:"todo_id"`
+	ParentID  *uint        `json:"parent_id"`
+	Author    ShareUserDTO `json:"author"`
+	Body      string       `json:"body"`
+	Deleted   bool         `json:"deleted"`
+	EditedAt  *time.Time   `json:"edited_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// CommentPageDTO represents a page of comments, oldest first. NextCursor is null on the last page.
+type CommentPageDTO struct {
+	Comments   []*CommentResponseDTO `json:"comments"`
+	NextCursor *string               `json:"next_cursor"`
+}
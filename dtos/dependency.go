@@ -0,0 +1,20 @@
+package dto
+
+// AddDependencyDTO represents the payload for making a todo wait for another one.
+type AddDependencyDTO struct {
+	BlockerID uint `json:"blocker_id" validate:"required"`
+}
+
+// DependencyEdgeDTO represents a todo waiting for one of its blockers.
+type DependencyEdgeDTO struct {
+	TodoID    uint `json:"todo_id"`
+	BlockerID uint `json:"blocker_id"`
+}
+
+// DependencyGraphDTO represents the dependencies of the todos of a project. The todos come
+// in topological order, each after the blockers it has in the project.
+type DependencyGraphDTO struct {
+	ProjectID uint                 `json:"project_id"`
+	Todos     []*TodoResponseDTO   `json:"todos"`
+	Edges     []*DependencyEdgeDTO `json:"edges"` // blockers outside the project included
+}
@@ -28,18 +28,171 @@ var (
 	ErrUserUpdate            = errors.New("user Updating Error")
 	ErrEmailAlreadyExists    = errors.New("email already exists")
 	ErrUsernameAlreadyExists = errors.New("username already exists")
+	ErrFeedNotFound          = errors.New("calendar feed not found")
+	ErrFeedTokenCreate       = errors.New("calendar feed token Creating Error")
+	ErrFeedTokenDelete       = errors.New("calendar feed token Revoking Error")
+	ErrAppPasswordNotFound   = errors.New("app password Not Found")
+	ErrAppPasswordCreate     = errors.New("app password Creating Error")
+	ErrAppPasswordDelete     = errors.New("app password Deleting Error")
 )
 
 // CRUD ToDO Errors
 var (
-	ErrToDoNotFound           = errors.New("To-Do Not Found")
-	ErrToDoCreate             = errors.New("To-Do Creating Error")
-	ErrToDoDelete             = errors.New("To-Do Deleting Error")
-	ErrToDoTrash              = errors.New("To-Do Trashing Error")
-	ErrToDoUpdate             = errors.New("To-Do Updating Error")
-	ErrAuthIdConv             = errors.New("failed to parse User Id")
-	ErrUnauthToDo             = errors.New("unauthorized to access this ToDo")
-	ErrToDoTitleAlreadyExists = errors.New("you have already a To Do with that title")
+	ErrToDoNotFound             = errors.New("To-Do Not Found")
+	ErrToDoCreate               = errors.New("To-Do Creating Error")
+	ErrToDoDelete               = errors.New("To-Do Deleting Error")
+	ErrToDoTrash                = errors.New("To-Do Trashing Error")
+	ErrToDoUpdate               = errors.New("To-Do Updating Error")
+	ErrAuthIdConv               = errors.New("failed to parse User Id")
+	ErrUnauthToDo               = errors.New("unauthorized to access this ToDo")
+	ErrToDoTitleAlreadyExists   = errors.New("you have already a To Do with that title")
+	ErrToDoSubtasksOpen         = errors.New("To-Do has required subtasks that are not done")
+	ErrToDoDueBeforeStart       = errors.New("To-Do due date can't be before its start date")
+	ErrToDoMoveNeighbours       = errors.New("the To-Do can't be placed between these neighbours")
+	ErrInvalidCursor            = errors.New("invalid pagination cursor")
+	ErrToDoRestore              = errors.New("To-Do Restoring Error")
+	ErrToDoSearch               = errors.New("To-Do Searching Error")
+	ErrToDoNotTrashed           = errors.New("To-Do is not in the trash")
+	ErrToDoRestoreTitleConflict = errors.New("an active To-Do already has the title of the To-Do to restore")
+	ErrToDoAssign               = errors.New("To-Do Assigning Error")
+	ErrToDoAssigneeNoAccess     = errors.New("the assignee has no access to this To-Do")
+	ErrToDoExport               = errors.New("To-Do Exporting Error")
+	ErrToDoImport               = errors.New("To-Do Importing Error")
+	ErrImportFormat             = errors.New("unknown import format, use csv, json or todotxt")
+	ErrImportFile               = errors.New("the import file can't be read")
+	ErrImportTooManyRows        = errors.New("the import file has too many rows")
+	ErrImportProjectName        = errors.New("the project name is blank or longer than 100 characters")
+	ErrImportTagName            = errors.New("a tag name is blank or longer than 50 characters")
+	ErrDavInvalidCalendar       = errors.New("the calendar data is not a single valid VTODO")
+	ErrDavPrecondition          = errors.New("the To-Do has changed since it was last read")
+	ErrDavUIDConflict           = errors.New("another To-Do already has the UID of the VTODO")
+)
+
+// Recurrence Errors
+var (
+	ErrRecurrenceInvalidRule    = errors.New("invalid recurrence rule")
+	ErrRecurrenceNeedsDueDate   = errors.New("a recurring To-Do needs a due date")
+	ErrRecurrenceFrequency      = errors.New("a recurrence can't repeat more often than daily")
+	ErrRecurrenceCreate         = errors.New("Recurrence Creating Error")
+	ErrRecurrenceUpdate         = errors.New("Recurrence Updating Error")
+	ErrToDoNotRecurring         = errors.New("To-Do is not recurring")
+	ErrRecurrenceNoOccurrence   = errors.New("the recurrence has no occurrence at that date")
+	ErrRecurrenceOccurrenceLive = errors.New("this occurrence already exists as a To-Do, update the To-Do instead")
+	ErrRecurrenceWindow         = errors.New("the occurrence window must end after it starts and span at most a year")
+)
+
+// CRUD Subtask Errors
+var (
+	ErrSubtaskNotFound = errors.New("Subtask Not Found")
+	ErrSubtaskCreate   = errors.New("Subtask Creating Error")
+	ErrSubtaskDelete   = errors.New("Subtask Deleting Error")
+	ErrSubtaskUpdate   = errors.New("Subtask Updating Error")
+)
+
+// CRUD Tag Errors
+var (
+	ErrTagNotFound          = errors.New("Tag Not Found")
+	ErrTagCreate            = errors.New("Tag Creating Error")
+	ErrTagDelete            = errors.New("Tag Deleting Error")
+	ErrTagUpdate            = errors.New("Tag Updating Error")
+	ErrUnauthTag            = errors.New("unauthorized to access this Tag")
+	ErrTagNameAlreadyExists = errors.New("you have already a Tag with that name")
+	ErrTagNameEmpty         = errors.New("the name of the Tag can't be blank")
+)
+
+// CRUD Project Errors
+var (
+	ErrProjectNotFound          = errors.New("Project Not Found")
+	ErrProjectCreate            = errors.New("Project Creating Error")
+	ErrProjectDelete            = errors.New("Project Deleting Error")
+	ErrProjectUpdate            = errors.New("Project Updating Error")
+	ErrUnauthProject            = errors.New("unauthorized to access this Project")
+	ErrProjectNameAlreadyExists = errors.New("you have already a Project with that name")
+	ErrProjectArchived          = errors.New("Project is archived")
+	ErrProjectInbox             = errors.New("the Inbox project can't be archived or deleted")
+)
+
+// Workflow Errors
+var (
+	ErrWorkflowNotFound     = errors.New("Workflow Not Found")
+	ErrWorkflowCreate       = errors.New("Workflow Creating Error")
+	ErrWorkflowUpdate       = errors.New("Workflow Updating Error")
+	ErrWorkflowDelete       = errors.New("Workflow Deleting Error")
+	ErrUnauthWorkflow       = errors.New("unauthorized to access this Workflow")
+	ErrWorkflowStatuses     = errors.New("a workflow needs unique status names and at least one status of each category")
+	ErrWorkflowTransition   = errors.New("a transition refers to a status that isn't in the workflow")
+	ErrToDoStatusUnknown    = errors.New("the status isn't in the workflow of the To-Do")
+	ErrToDoStatusTransition = errors.New("the workflow of the To-Do doesn't allow this status change")
+)
+
+// Dependency Errors
+var (
+	ErrDependencyNotFound = errors.New("Dependency Not Found")
+	ErrDependencyCreate   = errors.New("Dependency Creating Error")
+	ErrDependencyDelete   = errors.New("Dependency Deleting Error")
+	ErrDependencyExists   = errors.New("the To-Do already depends on this To-Do")
+	ErrDependencyCycle    = errors.New("the dependency would make a To-Do wait for itself")
+	ErrToDoBlocked        = errors.New("To-Do has blockers that are not done")
+)
+
+// Time Tracking Errors
+var (
+	ErrTimeEntryNotFound = errors.New("Time Entry Not Found")
+	ErrTimeEntryCreate   = errors.New("Time Entry Creating Error")
+	ErrTimeEntryUpdate   = errors.New("Time Entry Updating Error")
+	ErrTimeEntryDelete   = errors.New("Time Entry Deleting Error")
+	ErrUnauthTimeEntry   = errors.New("unauthorized to access this Time Entry")
+	ErrTimeEntryDates    = errors.New("a time entry must end after it starts, and not in the future")
+	ErrTimerRunning      = errors.New("a timer is already running")
+	ErrTimerNotRunning   = errors.New("no timer is running on this To-Do")
+)
+
+// Template Errors
+var (
+	ErrTemplateNotFound  = errors.New("Template Not Found")
+	ErrTemplateCreate    = errors.New("Template Creating Error")
+	ErrTemplateUpdate    = errors.New("Template Updating Error")
+	ErrTemplateDelete    = errors.New("Template Deleting Error")
+	ErrUnauthTemplate    = errors.New("unauthorized to access this Template")
+	ErrTemplateVariables = errors.New("a value is missing for a variable of the template")
+	ErrTemplateTitle     = errors.New("the title of the To-Do would be empty")
+)
+
+// Board Errors
+var (
+	ErrBoardWIPLimit = errors.New("the column has reached its WIP limit")
+)
+
+// Comment Errors
+var (
+	ErrCommentNotFound   = errors.New("Comment Not Found")
+	ErrCommentCreate     = errors.New("Comment Creating Error")
+	ErrCommentUpdate     = errors.New("Comment Updating Error")
+	ErrCommentDelete     = errors.New("Comment Deleting Error")
+	ErrCommentDeleted    = errors.New("the comment has been deleted")
+	ErrCommentEditWindow = errors.New("the comment can no longer be edited")
+	ErrCommentParent     = errors.New("the parent comment isn't in the thread of this To-Do")
+	ErrUnauthComment     = errors.New("unauthorized to change this comment")
+)
+
+// Attachment Errors
+var (
+	ErrAttachmentNotFound = errors.New("Attachment Not Found")
+	ErrAttachmentCreate   = errors.New("Attachment Uploading Error")
+	ErrAttachmentDelete   = errors.New("Attachment Deleting Error")
+	ErrAttachmentTooLarge = errors.New("the file is larger than the attachment size limit")
+	ErrAttachmentType     = errors.New("this type of file can't be attached")
+	ErrAttachmentFile     = errors.New("the uploaded file can't be read")
+)
+
+// Share Errors
+var (
+	ErrShareNotFound = errors.New("Share Not Found")
+	ErrShareCreate   = errors.New("Share Creating Error")
+	ErrShareDelete   = errors.New("Share Deleting Error")
+	ErrShareAccept   = errors.New("Share Accepting Error")
+	ErrShareSelf     = errors.New("a To-Do or Project can't be shared with its owner")
+	ErrUnauthShare   = errors.New("only owners can manage the shares")
 )
 
 // other
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// query parameters for exporting todos.
+type ExportQueryDTO struct {
+	Format         string `form:"format" validate:"required,oneof=csv json markdown todotxt"`
+	IncludeTrashed bool   `form:"include_trashed"`
+}
+
+// structure of a todo in a JSON export, trashed todos carry their deletion date.
+type TodoExportDTO struct {
+	*TodoResponseDTO
+	DeletedAt *time.Time `json:"deleted_at"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// TodoFeedDTO represents the calendar feed of the todos of a user.
+// The calendar is left empty when the client already has this version of the feed.
+type TodoFeedDTO struct {
+	ETag        string
+	NotModified bool
+	Calendar    []byte
+}
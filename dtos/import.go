@@ -0,0 +1,29 @@
+package dto
+
+// query parameters of a todo import, the file itself is sent in the "file" form field.
+// The format is guessed from the file extension when it isn't given.
+type ImportQueryDTO struct {
+	Format     string `form:"format" validate:"omitempty,oneof=csv json todotxt"`
+	DryRun     bool   `form:"dry_run"`
+	OnConflict string `form:"on_conflict" validate:"omitempty,oneof=skip rename overwrite"`
+}
+
+// result of the import of one row.
+type ImportResultDTO struct {
+	Row    int      `json:"row"` // line in CSV and todo.txt files, element in JSON arrays, from 1
+	Title  string   `json:"title"`
+	Action string   `json:"action"` // created, renamed, overwritten, skipped or failed
+	TodoID *uint    `json:"todo_id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// response structure for an import.
+type ImportReportDTO struct {
+	DryRun      bool               `json:"dry_run"`
+	Created     int                `json:"created"`
+	Renamed     int                `json:"renamed"`
+	Overwritten int                `json:"overwritten"`
+	Skipped     int                `json:"skipped"`
+	Failed      int                `json:"failed"`
+	Results     []*ImportResultDTO `json:"results"`
+}
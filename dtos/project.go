@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+
+// create project payload.
+type CreateProjectDTO struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// update project payload.
+type UpdateProjectDTO struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+// reorder projects payload, project IDs in their new order.
+type ReorderProjectsDTO struct {
+	ProjectIDs []uint `json:"project_ids" validate:"required,min=1"`
+}
+
+// move todos into a project payload.
+type MoveTodosDTO struct {
+	TodoIDs []uint `json:"todo_ids" validate:"required,min=1"`
+}
+
+// query parameters for listing projects.
+type ProjectQueryDTO struct {
+	Archived bool `form:"archived"`
+}
+
+// query parameters for deleting a project, decides what happens to its todos.
+type DeleteProjectQueryDTO struct {
+	Todos string `form:"todos" validate:"omitempty,oneof=trash inbox"`
+}
+
+// response structure for a project.
+type ProjectResponseDTO struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Position   int        `json:"position"`
+	IsInbox    bool       `json:"is_inbox"`
+	Archived   bool       `json:"archived"`
+	WorkflowID *uint      `json:"workflow_id"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// recurrence of a todo, an RFC 5545 RRULE evaluated in an IANA timezone.
+type RecurrenceDTO struct {
+	Rule     string `json:"rule" validate:"required"` // e.g FREQ=WEEKLY;BYDAY=MO
+	Timezone string `json:"timezone,omitempty" validate:"omitempty,timezone"`
+}
+
+// query parameters for expanding the occurrences of a series.
+type OccurrenceQueryDTO struct {
+	From *time.Time `form:"from"`
+	To   *time.Time `form:"to"`
+}
+
+// skip or edit a single occurrence payload.
+type UpdateOccurrenceDTO struct {
+	OccurrenceAt time.Time  `json:"occurrence_at" validate:"required"`
+	Skip         *bool      `json:"skip,omitempty"`
+	Title        *string    `json:"title,omitempty" validate:"omitempty,min=1"`
+	Description  *string    `json:"description,omitempty"`
+	DueAt        *time.Time `json:"due_at,omitempty"`
+}
+
+// response structure for the recurrence of a todo.
+type RecurrenceResponseDTO struct {
+	ID       uint      `json:"id"`
+	Rule     string    `json:"rule"`
+	Timezone string    `json:"timezone"`
+	Dtstart  time.Time `json:"dtstart"`
+}
+
+// response structure for an occurrence of a series.
+type OccurrenceResponseDTO struct {
+	OccurrenceAt time.Time `json:"occurrence_at"`
+	DueAt        time.Time `json:"due_at"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
+	Skipped      bool      `json:"skipped"`
+	TodoID       *uint     `json:"todo_id,omitempty"` // set once the occurrence exists as a todo
+}
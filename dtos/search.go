@@ -0,0 +1,18 @@
+package dto
+
+// query parameters for searching todos.
+// Words are all required, "quoted words" match as a phrase and word* matches as a prefix.
+type SearchQueryDTO struct {
+	Q      string `form:"q" validate:"required,max=200"`
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" validate:"omitempty,min=0"`
+}
+
+// response structure for a search hit, snippets are HTML escaped with the
+// matches wrapped in <mark> tags.
+type SearchResultDTO struct {
+	Todo               *TodoResponseDTO `json:"todo"`
+	Rank               float64          `json:"rank"`
+	TitleSnippet       string           `json:"title_snippet"`
+	DescriptionSnippet string           `json:"description_snippet"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"go-feToDo/enums"
+	"time"
+)
+
+// CreateShareDTO represents the payload for sharing a todo or a project with a user.
+type CreateShareDTO struct {
+	// username or email of the user to share with
+	User string          `json:"user" validate:"required"`
+	Role enums.ShareRole `json:"role" validate:"required,oneof=viewer editor owner"`
+}
+
+// ShareUserDTO represents a user taking part in a share.
+type ShareUserDTO struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+// ShareResponseDTO represents a share of a todo or a project.
+type ShareResponseDTO struct {
+	ID          uint            `json:"id"`
+	TodoID      *uint           `json:"todo_id"`
+	TodoTitle   *string         `json:"todo_title,omitempty"`
+	ProjectID   *uint           `json:"project_id"`
+	ProjectName *string         `json:"project_name,omitempty"`
+	User        ShareUserDTO    `json:"user"`
+	InvitedBy   ShareUserDTO    `json:"invited_by"`
+	Role        enums.ShareRole `json:"role"`
+	AcceptedAt  *time.Time      `json:"accepted_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// SharedTodoResponseDTO represents a todo of another user with the role the user has on it.
+type SharedTodoResponseDTO struct {
+	*TodoResponseDTO
+	Role enums.ShareRole `json:"role"`
+}
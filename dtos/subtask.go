@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// create subtask payload.
+type CreateSubtaskDTO struct {
+	Title    string `json:"title" validate:"required,max=255"`
+	Required *bool  `json:"required,omitempty"` // defaults to true
+}
+
+// update subtask payload.
+type UpdateSubtaskDTO struct {
+	Title    *string `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
+	Required *bool   `json:"required,omitempty"`
+	Done     *bool   `json:"done,omitempty"`
+}
+
+// reorder subtasks payload, subtask IDs in their new order.
+type ReorderSubtasksDTO struct {
+	SubtaskIDs []uint `json:"subtask_ids" validate:"required,min=1"`
+}
+
+// response structure for a subtask.
+type SubtaskResponseDTO struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Done      bool      `json:"done"`
+	Required  bool      `json:"required"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// progress of a todo based on its subtasks.
+type TodoProgressDTO struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
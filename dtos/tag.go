@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// create tag payload.
+type CreateTagDTO struct {
+	Name  string `json:"name" validate:"required,max=50"`
+	Color string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+}
+
+// update tag payload.
+type UpdateTagDTO struct {
+	Name  *string `json:"name,omitempty" validate:"omitempty,min=1,max=50"`
+	Color *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+}
+
+// response structure for a tag.
+type TagResponseDTO struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
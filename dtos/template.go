@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"go-feToDo/enums"
+	"time"
+)
+
+// TemplateSubtaskDTO represents a subtask of a template.
+type TemplateSubtaskDTO struct {
+	Title    string `json:"title" validate:"required,max=255"`
+	Required *bool  `json:"required,omitempty"` // defaults to true
+}
+
+// TemplateDTO represents the payload for creating a template or replacing its content.
+// The title and description can hold {{variables}}. Offsets are in seconds from the
+// moment the template is instantiated, and stay within ten years.
+type TemplateDTO struct {
+	Name        string               `json:"name" validate:"required,max=100"`
+	Title       string               `json:"title" validate:"required,max=255"`
+	Description string               `json:"description,omitempty" validate:"max=10000"`
+	Priority    enums.TodoPriority   `json:"priority,omitempty" validate:"omitempty,oneof=none low medium high urgent"`
+	ProjectID   *uint                `json:"project_id,omitempty"`
+	TagIDs      []uint               `json:"tag_ids,omitempty"`
+	StartOffset *int64               `json:"start_offset,omitempty" validate:"omitempty,min=-315360000,max=315360000"`
+	DueOffset   *int64               `json:"due_offset,omitempty" validate:"omitempty,min=-315360000,max=315360000"`
+	Subtasks    []TemplateSubtaskDTO `json:"subtasks,omitempty" validate:"max=100,dive"`
+}
+
+// SaveAsTemplateDTO represents the payload for saving a todo as a template.
+type SaveAsTemplateDTO struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// InstantiateTemplateDTO represents the payload for creating a todo from a template.
+// A title already used by one of the user's todos fails, unless on_conflict is rename.
+type InstantiateTemplateDTO struct {
+	Variables  map[string]string `json:"variables,omitempty" validate:"max=50,dive,keys,max=50,endkeys,max=1000"`
+	ProjectID  *uint             `json:"project_id,omitempty"` // replaces the project of the template, 0 for none
+	From       *time.Time        `json:"from,omitempty"`       // moment the offsets count from, now by default
+	OnConflict string            `json:"on_conflict,omitempty" validate:"omitempty,oneof=fail rename"`
+}
+
+// TemplateSubtaskResponseDTO represents a subtask of a template.
+type TemplateSubtaskResponseDTO struct {
+	ID       uint   `json:"id"`
+	Title    string `json:"title"`
+	Required bool   `json:"required"`
+	Position int    `json:"position"`
+}
+
+// TemplateResponseDTO represents a template, with the variables its title and
+// description use.
+type TemplateResponseDTO struct {
+	ID          uint                         `json:"id"`
+	Name        string                       `json:"name"`
+	Title       string                       `json:"title"`
+	Description string                       `json:"description"`
+	Priority    enums.TodoPriority           `json:"priority"`
+	ProjectID   *uint                        `json:"project_id"`
+	Tags        []TagResponseDTO             `json:"tags"`
+	StartOffset *int64                       `json:"start_offset"`
+	DueOffset   *int64                       `json:"due_offset"`
+	Subtasks    []TemplateSubtaskResponseDTO `json:"subtasks"`
+	Variables   []string                     `json:"variables"`
+	CreatedAt   time.Time                    `json:"created_at"`
+	UpdatedAt   time.Time                    `json:"updated_at"`
+}
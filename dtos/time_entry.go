@@ -0,0 +1,59 @@
+package dto
+
+import "time"
+
+// CreateTimeEntryDTO represents the payload for recording time spent on a todo by hand.
+type CreateTimeEntryDTO struct {
+	StartedAt time.Time `json:"started_at" validate:"required"`
+	EndedAt   time.Time `json:"ended_at" validate:"required,gtfield=StartedAt"`
+	Note      string    `json:"note" validate:"max=500"`
+}
+
+// UpdateTimeEntryDTO represents the payload for correcting a time entry. Ending a
+// running timer stops it.
+type UpdateTimeEntryDTO struct {
+	StartedAt *time.Time `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Note      *string    `json:"note" validate:"omitempty,max=500"`
+}
+
+// TimeEntryResponseDTO represents a time entry, a running timer has no end.
+type TimeEntryResponseDTO struct {
+	ID        uint         `json:"id"`
+	TodoID    uint         `json:"todo_id"`
+	User      ShareUserDTO `json:"user"`
+	StartedAt time.Time    `json:"started_at"`
+	EndedAt   *time.Time   `json:"ended_at"`
+	Seconds   int64        `json:"seconds"` // 0 while the timer runs
+	Running   bool         `json:"running"`
+	Note      string       `json:"note"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// TimeReportQueryDTO represents the query params of a time report. Entries count in the
+// range they start in, which includes its start and excludes its end.
+type TimeReportQueryDTO struct {
+	From    time.Time `form:"from" validate:"required"`
+	To      time.Time `form:"to" validate:"required,gtfield=From"`
+	GroupBy string    `form:"group_by" validate:"omitempty,oneof=day project tag"` // day by default
+	TZ      string    `form:"tz" validate:"omitempty,timezone"`                    // IANA timezone of the days, UTC by default
+	Format  string    `form:"format" validate:"omitempty,oneof=json csv"`
+}
+
+// TimeReportRowDTO represents the time spent on a day, a project or a tag. The row of the
+// todos without a project or a tag has an empty key.
+type TimeReportRowDTO struct {
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	Entries int    `json:"entries"`
+	Seconds int64  `json:"seconds"`
+}
+
+// TimeReportDTO represents the time the user spent on todos over a range of dates.
+type TimeReportDTO struct {
+	From         time.Time           `json:"from"`
+	To           time.Time           `json:"to"`
+	GroupBy      string              `json:"group_by"`
+	TotalSeconds int64               `json:"total_seconds"`
+	Rows         []*TimeReportRowDTO `json:"rows"`
+}
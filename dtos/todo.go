@@ -7,24 +7,108 @@ import (
 
 // create todo payload.
 type CreateTodoDTO struct {
-	Title       string `json:"title" validate:"required"`
-	Description string `json:"description,omitempty"`
+	Title       string             `json:"title" validate:"required"`
+	Description string             `json:"description,omitempty"`
+	TagIDs      []uint             `json:"tag_ids,omitempty"`
+	ProjectID   *uint              `json:"project_id,omitempty"`
+	Priority    enums.TodoPriority `json:"priority,omitempty" validate:"omitempty,oneof=none low medium high urgent"`
+	// dates are RFC 3339 timestamps carrying their timezone offset
+	StartAt *time.Time `json:"start_at,omitempty"`
+	DueAt   *time.Time `json:"due_at,omitempty"`
+	// a recurring todo needs a due date, it is the first occurrence of the series
+	Recurrence *RecurrenceDTO `json:"recurrence,omitempty"`
 }
 
 // update todo payload.
 type UpdateTodoDTO struct {
-	Title       *string           `json:"title,omitempty" validate:"omitempty"`
-	Description *string           `json:"description,omitempty"`
-	Status      *enums.TodoStatus `json:"status,omitempty" validate:"omitempty,oneof=pending in_progress completed"`
+	Title        *string             `json:"title,omitempty" validate:"omitempty"`
+	Description  *string             `json:"description,omitempty"`
+	Status       *enums.TodoStatus   `json:"status,omitempty" validate:"omitempty,max=50"`
+	Priority     *enums.TodoPriority `json:"priority,omitempty" validate:"omitempty,oneof=none low medium high urgent"`
+	AddTagIDs    []uint              `json:"add_tag_ids,omitempty"`
+	RemoveTagIDs []uint              `json:"remove_tag_ids,omitempty"`
+	ProjectID    *uint               `json:"project_id,omitempty"` // 0 removes the todo from its project
+	StartAt      *time.Time          `json:"start_at,omitempty"`
+	DueAt        *time.Time          `json:"due_at,omitempty"`
+	ClearStartAt bool                `json:"clear_start_at,omitempty"`
+	ClearDueAt   bool                `json:"clear_due_at,omitempty"`
+	// Recurrence restarts the series from this todo, ClearRecurrence stops it
+	Recurrence      *RecurrenceDTO `json:"recurrence,omitempty"`
+	ClearRecurrence bool           `json:"clear_recurrence,omitempty"`
+	// CompleteSubtasks marks every subtask as done when the todo is completed
+	CompleteSubtasks bool `json:"complete_subtasks,omitempty"`
+	// Force completes the todo even though some of its blockers are still open
+	Force bool `json:"force,omitempty"`
+}
+
+// query parameters for listing todos.
+type TodoQueryDTO struct {
+	Tags      string `form:"tags"`
+	TagsMatch string `form:"tags_match" validate:"omitempty,oneof=any all"`
+	ProjectID *uint  `form:"project_id"`
+	Due       string `form:"due" validate:"omitempty,oneof=today overdue this_week no_date"`
+	TZ        string `form:"tz" validate:"omitempty,timezone"` // IANA timezone used by the due views, UTC by default
+	Sort      string `form:"sort" validate:"omitempty,oneof=priority position due created_at"`
+	Order     string `form:"order" validate:"omitempty,oneof=asc desc"`
+	Status    string `form:"status" validate:"omitempty,max=50"`
+	Category  string `form:"category" validate:"omitempty,oneof=todo doing done"` // status category
+	Q         string `form:"q" validate:"omitempty,max=200"`                      // case-insensitive title substring
+	// created and updated ranges include their start and exclude their end
+	CreatedFrom *time.Time `form:"created_from"`
+	CreatedTo   *time.Time `form:"created_to"`
+	UpdatedFrom *time.Time `form:"updated_from"`
+	UpdatedTo   *time.Time `form:"updated_to"`
+	Limit       int        `form:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor      string     `form:"cursor"` // next_cursor of the previous page
+}
+
+// bulk restore payload.
+type RestoreTodosDTO struct {
+	TodoIDs []uint `json:"todo_ids" validate:"required,min=1"`
+}
+
+// a page of todos, NextCursor is null on the last page.
+type TodoPageDTO struct {
+	Todos      []*TodoResponseDTO `json:"todos"`
+	NextCursor *string            `json:"next_cursor"`
+}
+
+// move todo payload, the todo is placed between its new neighbours.
+// Giving a single neighbour places the todo right next to it.
+type MoveTodoDTO struct {
+	BeforeID *uint `json:"before_id,omitempty" validate:"required_without=AfterID"` // todo that will come just before
+	AfterID  *uint `json:"after_id,omitempty" validate:"required_without=BeforeID"` // todo that will come just after
+}
+
+// assign todo payload.
+type AssignTodoDTO struct {
+	User string `json:"user" validate:"required"` // username or email of the assignee
 }
 
 // response structure for a todo item.
 type TodoResponseDTO struct {
-	ID          uint             `json:"id"`
-	Title       string           `json:"title"`
-	Description string           `json:"description,omitempty"`
-	Status      enums.TodoStatus `json:"status"`
-	AuthorID    uint             `json:"author_id"`
-	CreatedAt   time.Time        `json:"created_at"`
-	UpdatedAt   time.Time        `json:"updated_at"`
+	ID             uint                   `json:"id"`
+	Title          string                 `json:"title"`
+	Description    string                 `json:"description,omitempty"`
+	Status         enums.TodoStatus       `json:"status"`
+	Category       enums.StatusCategory   `json:"status_category"`
+	Priority       enums.TodoPriority     `json:"priority"`
+	Position       string                 `json:"position"`
+	BoardPosition  string                 `json:"board_position"`
+	AuthorID       uint                   `json:"author_id"`
+	ProjectID      *uint                  `json:"project_id"`
+	AssigneeID     *uint                  `json:"assignee_id"`
+	StartAt        *time.Time             `json:"start_at"`
+	DueAt          *time.Time             `json:"due_at"`
+	Overdue        bool                   `json:"overdue"`
+	Blocked        bool                   `json:"blocked"`         // one of the blockers of the todo isn't done yet
+	BlockedBy      []uint                 `json:"blocked_by"`      // IDs of the active todos to finish before this one
+	TrackedSeconds int64                  `json:"tracked_seconds"` // time spent on the todo, running timers excluded
+	Recurrence     *RecurrenceResponseDTO `json:"recurrence"`
+	OccurrenceAt   *time.Time             `json:"occurrence_at,omitempty"`
+	Tags           []TagResponseDTO       `json:"tags"`
+	Subtasks       []SubtaskResponseDTO   `json:"subtasks"`
+	Progress       TodoProgressDTO        `json:"progress"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
 }
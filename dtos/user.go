@@ -17,9 +17,16 @@ type UpdateUserDTO struct {
 
 // UserResponseDTO represents the response structure for a user.
 type UserResponseDTO struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uint      `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	FeedEnabled bool      `json:"feed_enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// FeedTokenResponseDTO represents the secret URL of a calendar feed, shown once.
+type FeedTokenResponseDTO struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
 }
@@ -0,0 +1,41 @@
+package dto
+
+import "go-feToDo/enums"
+
+// WorkflowStatusDTO represents a status of a workflow.
+type WorkflowStatusDTO struct {
+	Name     enums.TodoStatus     `json:"name" validate:"required,max=50"`
+	Category enums.StatusCategory `json:"category" validate:"required,oneof=todo doing done"`
+	WIPLimit int                  `json:"wip_limit" validate:"min=0,max=10000"` // 0 for no limit
+}
+
+// WorkflowTransitionDTO represents a status change allowed by a workflow.
+type WorkflowTransitionDTO struct {
+	From enums.TodoStatus `json:"from" validate:"required,max=50"`
+	To   enums.TodoStatus `json:"to" validate:"required,max=50"`
+}
+
+// WorkflowDTO represents the payload for creating a workflow or replacing its definition.
+// Statuses are in order, the first one is the status of new todos. Without transitions
+// any status change is allowed.
+type WorkflowDTO struct {
+	Name        string                  `json:"name" validate:"required,max=100"`
+	Statuses    []WorkflowStatusDTO     `json:"statuses" validate:"required,min=3,max=50,dive"`
+	Transitions []WorkflowTransitionDTO `json:"transitions" validate:"max=2500,dive"`
+}
+
+// SetProjectWorkflowDTO represents the payload for choosing the workflow of a project,
+// 0 to follow the default workflow of its author.
+type SetProjectWorkflowDTO struct {
+	WorkflowID uint `json:"workflow_id"`
+}
+
+// WorkflowResponseDTO represents a workflow. The built-in workflow has the ID 0.
+type WorkflowResponseDTO struct {
+	ID          uint                    `json:"id"`
+	Name        string                  `json:"name"`
+	Builtin     bool                    `json:"builtin"`
+	Default     bool                    `json:"default"` // followed by the todos outside a project with a workflow
+	Statuses    []WorkflowStatusDTO     `json:"statuses"`
+	Transitions []WorkflowTransitionDTO `json:"transitions"`
+}
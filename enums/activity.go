@@ -0,0 +1,13 @@
+package enums
+
+const (
+	TodoActivityCreated    TodoActivityAction = "created"
+	TodoActivityUpdated    TodoActivityAction = "updated"
+	TodoActivityTrashed    TodoActivityAction = "trashed"
+	TodoActivityRestored   TodoActivityAction = "restored"
+	TodoActivityDeleted    TodoActivityAction = "deleted"
+	TodoActivityAssigned   TodoActivityAction = "assigned"
+	TodoActivityUnassigned TodoActivityAction = "unassigned"
+)
+
+type TodoActivityAction string
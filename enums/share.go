@@ -0,0 +1,21 @@
+package enums
+
+const (
+	ShareRoleViewer ShareRole = "viewer"
+	ShareRoleEditor ShareRole = "editor"
+	ShareRoleOwner  ShareRole = "owner"
+)
+
+type ShareRole string
+
+// shareRoleRanks orders the roles, each one allows what the ones below it do.
+var shareRoleRanks = map[ShareRole]int{
+	ShareRoleViewer: 1,
+	ShareRoleEditor: 2,
+	ShareRoleOwner:  3,
+}
+
+// Allows tells whether the role grants at least the permissions of another one.
+func (r ShareRole) Allows(needed ShareRole) bool {
+	return shareRoleRanks[r] > 0 && shareRoleRanks[r] >= shareRoleRanks[needed]
+}
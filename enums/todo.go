@@ -1,8 +1,20 @@
 package enums
 
+// Statuses of the default workflow. Other workflows name their own statuses.
 const (
+	TodoStatusPending    TodoStatus = "pending"
 	TodoStatusInProgress TodoStatus = "in_progress"
 	TodoStatusCompleted  TodoStatus = "completed"
 )
 
 type TodoStatus string
+
+const (
+	TodoPriorityNone   TodoPriority = "none"
+	TodoPriorityLow    TodoPriority = "low"
+	TodoPriorityMedium TodoPriority = "medium"
+	TodoPriorityHigh   TodoPriority = "high"
+	TodoPriorityUrgent TodoPriority = "urgent"
+)
+
+type TodoPriority string
@@ -0,0 +1,10 @@
+package enums
+
+// Categories group the statuses of every workflow, the rest of the app only knows them.
+const (
+	StatusCategoryTodo  StatusCategory = "todo"
+	StatusCategoryDoing StatusCategory = "doing"
+	StatusCategoryDone  StatusCategory = "done"
+)
+
+type StatusCategory string
@@ -1,15 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"go-feToDo/config"
 	"go-feToDo/database"
 	"go-feToDo/routes"
+	"go-feToDo/storage"
 	"go-feToDo/utils"
+	"go-feToDo/workers"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// shutdownTimeout is how long in-flight requests get to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	utils.InitLogger("debug", "dev")
 	// Load application configuration
@@ -21,17 +34,44 @@ func main() {
 	// Run database migrations if in development mode
 	database.AutoMigrate(db)
 
+	// Initialize the storage of uploaded files
+	storage.Connect()
+
 	// Create a Gin router instance
 	router := gin.Default()
 
 	// Initialize application routes
 	initializeRoutes(router)
 
+	// Stop on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Start background workers
+	var workersDone sync.WaitGroup
+	startWorkers(ctx, cfg, &workersDone)
+
 	// Start the server
-	log.Printf("Starting server on port %s...", cfg.AppPort)
-	if err := router.Run(":" + cfg.AppPort); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	server := &http.Server{
+		Addr:    ":" + cfg.AppPort,
+		Handler: router,
+	}
+	go func() {
+		log.Printf("Starting server on port %s...", cfg.AppPort)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shut down the server gracefully: %v", err)
 	}
+	workersDone.Wait()
 }
 
 // initializeRoutes sets up all application routes
@@ -40,4 +80,25 @@ func initializeRoutes(router *gin.Engine) {
 	routes.AuthRoutes(router)
 	routes.UserRoutes(router)
 	routes.TodoRoutes(router)
+	routes.TagRoutes(router)
+	routes.ProjectRoutes(router)
+	routes.FeedRoutes(router)
+	routes.DavRoutes(router)
+	routes.ShareRoutes(router)
+	routes.WorkflowRoutes(router)
+	routes.BoardRoutes(router)
+	routes.ReportRoutes(router)
+	routes.TemplateRoutes(router)
+}
+
+// startWorkers runs the background workers until the context is cancelled
+func startWorkers(ctx context.Context, cfg *config.Config, wg *sync.WaitGroup) {
+	if cfg.TrashRetentionDays > 0 && cfg.TrashPurgeInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			retention := time.Duration(cfg.TrashRetentionDays) * 24 * time.Hour
+			workers.RunTrashPurge(ctx, retention, time.Duration(cfg.TrashPurgeInterval)*time.Second)
+		}()
+	}
 }
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	dto "go-feToDo/dtos"
+	"go-feToDo/services"
+	"go-feToDo/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// davRealm is the realm CalDAV clients are asked to log in to
+const davRealm = `Basic realm="go-feToDo", charset="UTF-8"`
+
+// IsDAVAuthenticated lets CalDAV clients in with HTTP Basic auth, the username or email
+// of the user and one of their app passwords, or with the access token of the JWT login.
+func IsDAVAuthenticated() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload *dto.JWTPayloadDTO
+		var err error
+
+		authHeader := c.GetHeader("Authorization")
+		if login, password, ok := c.Request.BasicAuth(); ok {
+			payload, err = services.AuthenticateAppPassword(login, password)
+		} else if token, ok := strings.CutPrefix(authHeader, "Bearer "); ok {
+			payload, err = utils.DecodeToken(token, false)
+			// Check if the token is expired
+			if err == nil && time.Now().Unix() > payload.Expires {
+				err = dto.ErrJWTExpiredToken
+			}
+		} else {
+			err = dto.ErrJWTMissingAuthHeader
+		}
+
+		if err != nil {
+			// Clients only prompt for credentials when asked to
+			c.Header("WWW-Authenticate", davRealm)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("username", payload.Username)
+		c.Set("authorID", payload.Id)
+		c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AppPassword lets a client that can't go through the JWT login, like a CalDAV
+// task app, authenticate as the user with HTTP Basic auth.
+type AppPassword struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Name       string     `json:"name" gorm:"not null"`
+	Hash       string     `json:"-" gorm:"uniqueIndex;not null"` // SHA-256 of the password, which is shown once
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	User       User       `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the AppPassword model
+func (AppPassword) TableName() string {
+	return "app_passwords"
+}
+
+// BeforeCreate hook to handle timestamps
+func (p *AppPassword) BeforeCreate(tx *gorm.DB) error {
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate hook to handle timestamps
+func (p *AppPassword) BeforeUpdate(tx *gorm.DB) error {
+	p.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Attachment is a file uploaded on a todo. Its content lives in the storage backend
+// under StorageKey, the row only describes it.
+type Attachment struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	TodoID      uint      `json:"todo_id" gorm:"not null;index"`
+	UploaderID  uint      `json:"uploader_id" gorm:"not null"`
+	Uploader    User      `json:"-" gorm:"foreignKey:UploaderID;constraint:OnDelete:CASCADE"`
+	FileName    string    `json:"file_name" gorm:"type:varchar(255);not null"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(255);not null"`
+	Size        int64     `json:"size" gorm:"not null"`
+	StorageKey  string    `json:"-" gorm:"type:varchar(255);uniqueIndex;not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the Attachment model
+func (Attachment) TableName() string {
+	return "attachments"
+}
+
+// BeforeCreate hook to handle timestamps
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	a.CreatedAt = time.Now()
+	return nil
+}
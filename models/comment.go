@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment is a message of the discussion thread of a todo. A deleted comment is kept
+// as a tombstone, without its body, so that the replies to it keep their place.
+type Comment struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	TodoID    uint       `json:"todo_id" gorm:"not null;index"`
+	Todo      Todo       `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	AuthorID  uint       `json:"author_id" gorm:"not null"`
+	Author    User       `json:"-" gorm:"foreignKey:AuthorID;constraint:OnDelete:CASCADE"`
+	ParentID  *uint      `json:"parent_id" gorm:"index"` // comment this one replies to
+	Parent    *Comment   `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+	Body      string     `json:"body" gorm:"type:text"` // Markdown
+	EditedAt  *time.Time `json:"edited_at"`
+	DeletedAt *time.Time `json:"deleted_at"` // tombstone, the row itself is never removed
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Comment model
+func (Comment) TableName() string {
+	return "comments"
+}
+
+// BeforeCreate hook to handle timestamps
+func (c *Comment) BeforeCreate(tx *gorm.DB) error {
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate hook to handle timestamps
+func (c *Comment) BeforeUpdate(tx *gorm.DB) error {
+	c.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Project struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Name       string     `json:"name" gorm:"not null"`
+	Position   int        `json:"position" gorm:"not null;default:0"`
+	IsInbox    bool       `json:"is_inbox" gorm:"not null;default:false"`
+	ArchivedAt *time.Time `json:"archived_at"`
+	AuthorID   uint       `json:"author_id" gorm:"not null;index"`
+	WorkflowID *uint      `json:"workflow_id" gorm:"index"` // nil follows the author's default workflow
+	Workflow   *Workflow  `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+	Author     User       `json:"-" gorm:"foreignKey:AuthorID"`
+	Todos      []Todo     `json:"-" gorm:"foreignKey:ProjectID;constraint:OnDelete:SET NULL"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Project model
+func (Project) TableName() string {
+	return "projects"
+}
+
+// BeforeCreate hook to handle timestamps
+func (p *Project) BeforeCreate(tx *gorm.DB) error {
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate hook to handle timestamps
+func (p *Project) BeforeUpdate(tx *gorm.DB) error {
+	p.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Recurrence is a series of todos repeating after an RFC 5545 rule.
+// Only the next occurrence of a series exists as a todo at any time.
+type Recurrence struct {
+	ID         uint                  `json:"id" gorm:"primaryKey"`
+	Title      string                `json:"title" gorm:"not null;default:''"` // title of the next occurrences, numbered when taken
+	Rule       string                `json:"rule" gorm:"not null"`             // RRULE without DTSTART, e.g FREQ=WEEKLY;BYDAY=MO
+	Timezone   string                `json:"timezone" gorm:"not null;default:'UTC'"`
+	Dtstart    time.Time             `json:"dtstart" gorm:"not null"`
+	AuthorID   uint                  `json:"author_id" gorm:"not null;index"`
+	Exceptions []RecurrenceException `json:"exceptions" gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt  time.Time             `json:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+}
+
+// RecurrenceException skips or edits a single occurrence of a series.
+type RecurrenceException struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	RecurrenceID uint       `json:"recurrence_id" gorm:"not null;uniqueIndex:idx_recurrence_occurrence"`
+	OccurrenceAt time.Time  `json:"occurrence_at" gorm:"not null;uniqueIndex:idx_recurrence_occurrence"`
+	Skipped      bool       `json:"skipped" gorm:"not null"`
+	Title        *string    `json:"title"`
+	Description  *string    `json:"description"`
+	DueAt        *time.Time `json:"due_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Recurrence model
+func (Recurrence) TableName() string {
+	return "recurrences"
+}
+
+// BeforeCreate hook to handle timestamps
+func (r *Recurrence) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate hook to handle timestamps
+func (r *Recurrence) BeforeUpdate(tx *gorm.DB) error {
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// TableName specifies the table name for the RecurrenceException model
+func (RecurrenceException) TableName() string {
+	return "recurrence_exceptions"
+}
+
+// BeforeCreate hook to handle timestamps
+func (e *RecurrenceException) BeforeCreate(tx *gorm.DB) error {
+	e.CreatedAt = time.Now()
+	e.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate hook to handle timestamps
+func (e *RecurrenceException) BeforeUpdate(tx *gorm.DB) error {
+	e.UpdatedAt = time.Now()
+	return nil
+}
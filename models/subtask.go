@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Subtask struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TodoID    uint      `json:"todo_id" gorm:"not null;index"`
+	Title     string    `json:"title" gorm:"not null"`
+	Done      bool      `json:"done" gorm:"not null"`
+	Required  bool      `json:"required" gorm:"not null"`
+	Position  int       `json:"position" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Subtask model
+func (Subtask) TableName() string {
+	return "subtasks"
+}
+
+// BeforeCreate hook to handle timestamps
+func (s *Subtask) BeforeCreate(tx *gorm.DB) error {
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate hook to handle timestamps
+func (s *Subtask) BeforeUpdate(tx *gorm.DB) error {
+	s.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"go-feToDo/enums"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Template is a todo saved to be created again, with its subtasks and tags. Its dates are
+// offsets from the moment it is instantiated, its title and description can hold
+// {{variables}} filled in at that moment.
+type Template struct {
+	ID          uint               `json:"id" gorm:"primaryKey"`
+	Name        string             `json:"name" gorm:"not null"`
+	Title       string             `json:"title" gorm:"not null"`
+	Description string             `json:"description"`
+	Priority    enums.TodoPriority `json:"priority" gorm:"type:varchar(10);default:'none'"`
+	ProjectID   *uint              `json:"project_id" gorm:"index"`
+	Project     *Project           `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+	StartOffset *int64             `json:"start_offset"` // seconds between the instantiation and the start date
+	DueOffset   *int64             `json:"due_offset"`   // seconds between the instantiation and the due date
+	AuthorID    uint               `json:"author_id" gorm:"not null;index"`
+	Author      User               `json:"-" gorm:"foreignKey:AuthorID;constraint:OnDelete:CASCADE"`
+	Tags        []Tag              `json:"tags" gorm:"many2many:template_tags;constraint:OnDelete:CASCADE"`
+	Subtasks    []TemplateSubtask  `json:"subtasks" gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// TemplateSubtask is a subtask created with the todos of a template.
+type TemplateSubtask struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	TemplateID uint   `json:"template_id" gorm:"not null;index"`
+	Title      string `json:"title" gorm:"not null"`
+	Required   bool   `json:"required" gorm:"not null"`
+	Position   int    `json:"position" gorm:"not null;default:0"`
+}
+
+// TableName specifies the table name for the Template model
+func (Template) TableName() string {
+	return "templates"
+}
+
+// TableName specifies the table name for the TemplateSubtask model
+func (TemplateSubtask) TableName() string {
+	return "template_subtasks"
+}
+
+// BeforeCreate hook to handle timestamps and default priority
+func (t *Template) BeforeCreate(tx *gorm.DB) error {
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = time.Now()
+	if t.Priority == "" {
+		t.Priority = enums.TodoPriorityNone
+	}
+	return nil
+}
+
+// BeforeUpdate hook to handle timestamps
+func (t *Template) BeforeUpdate(tx *gorm.DB) error {
+	t.UpdatedAt = time.Now()
+	return nil
+}
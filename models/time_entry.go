@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TimeEntry is a span of time a user spent on a todo. A running timer is an entry
+// without an end, a user has at most one.
+type TimeEntry struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	TodoID    uint       `json:"todo_id" gorm:"not null;index"`
+	Todo      Todo       `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	UserID    uint       `json:"user_id" gorm:"not null;index;uniqueIndex:idx_time_entry_running,where:ended_at IS NULL"`
+	User      User       `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	StartedAt time.Time  `json:"started_at" gorm:"not null;index"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Note      string     `json:"note" gorm:"type:varchar(500)"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the TimeEntry model
+func (TimeEntry) TableName() string {
+	return "time_entries"
+}
+
+// BeforeCreate hook to handle timestamps
+func (e *TimeEntry) BeforeCreate(tx *gorm.DB) error {
+	e.CreatedAt = time.Now()
+	e.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate hook to handle timestamps
+func (e *TimeEntry) BeforeUpdate(tx *gorm.DB) error {
+	e.UpdatedAt = time.Now()
+	return nil
+}
+
+// Seconds returns the duration of a finished entry, 0 for a running timer.
+func (e *TimeEntry) Seconds() int64 {
+	if e.EndedAt == nil {
+		return 0
+	}
+	return int64(e.EndedAt.Sub(e.StartedAt) / time.Second)
+}
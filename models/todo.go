@@ -1,6 +1,8 @@
 package models
 
 import (
+	"crypto/rand"
+	"fmt"
 	"go-feToDo/enums"
 	"time"
 
@@ -8,15 +10,35 @@ import (
 )
 
 type Todo struct {
-	ID          uint             `json:"id" gorm:"primaryKey"`
-	Title       string           `json:"title" gorm:"not null"`
-	Description string           `json:"description"`
-	Status      enums.TodoStatus `json:"status" gorm:"type:varchar(20);default:'pending'"`
-	AuthorID    uint             `json:"author_id" gorm:"not null"`
-	Author      User             `json:"author" gorm:"foreignKey:AuthorID"`
-	CreatedAt   time.Time        `json:"created_at"`
-	UpdatedAt   time.Time        `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt   `json:"-" gorm:"index"`
+	ID             uint                 `json:"id" gorm:"primaryKey"`
+	Title          string               `json:"title" gorm:"not null"`
+	Description    string               `json:"description"`
+	Status         enums.TodoStatus     `json:"status" gorm:"type:varchar(50);default:'pending'"` // name of a status of the todo's workflow
+	StatusCategory enums.StatusCategory `json:"status_category" gorm:"type:varchar(10);index"`
+	Priority       enums.TodoPriority   `json:"priority" gorm:"type:varchar(10);default:'none'"`
+	Position       string               `json:"position" gorm:"type:text;index"`       // lexicographic rank of the todo in the author's list
+	BoardPosition  string               `json:"board_position" gorm:"type:text;index"` // lexicographic rank of the todo in its column of the board, empty until placed
+	AuthorID       uint                 `json:"author_id" gorm:"not null"`
+	ProjectID      *uint                `json:"project_id" gorm:"index"`
+	AssigneeID     *uint                `json:"assignee_id" gorm:"index"` // user responsible for the todo, not necessarily its author
+	Assignee       *User                `json:"-" gorm:"foreignKey:AssigneeID;constraint:OnDelete:SET NULL"`
+	StartAt        *time.Time           `json:"start_at"`
+	DueAt          *time.Time           `json:"due_at" gorm:"index"`
+	RecurrenceID   *uint                `json:"recurrence_id" gorm:"index"`
+	Recurrence     *Recurrence          `json:"recurrence,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	OccurrenceAt   *time.Time           `json:"occurrence_at"`                    // date the recurrence rule planned this occurrence for
+	UID            string               `json:"-" gorm:"type:varchar(255);index"` // iCalendar UID the todo is synced under
+	DavName        string               `json:"-" gorm:"type:varchar(255);index"` // name of the .ics resource of the todo in the CalDAV collection
+	Author         User                 `json:"author" gorm:"foreignKey:AuthorID"`
+	Tags           []Tag                `json:"tags" gorm:"many2many:todo_tags;constraint:OnDelete:CASCADE"`
+	Subtasks       []Subtask            `json:"subtasks" gorm:"constraint:OnDelete:CASCADE"`
+	Attachments    []Attachment         `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	TimeEntries    []TimeEntry          `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	TrackedSeconds int64                `json:"tracked_seconds" gorm:"not null;default:0"`                                                                       // total duration of the finished time entries
+	Blockers       []Todo               `json:"-" gorm:"many2many:todo_dependencies;joinForeignKey:TodoID;joinReferences:BlockerID;constraint:OnDelete:CASCADE"` // todos to finish before this one can be
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt       `json:"-" gorm:"index"`
 }
 
 // TableName specifies the table name for the Todo model
@@ -29,13 +51,56 @@ func (t *Todo) BeforeCreate(tx *gorm.DB) error {
 	t.CreatedAt = time.Now()
 	t.UpdatedAt = time.Now()
 	if t.Status == "" {
-		t.Status = enums.TodoStatusInProgress
+		t.Status = enums.TodoStatusPending
+	}
+	if t.StatusCategory == "" {
+		t.StatusCategory = enums.StatusCategoryTodo
+	}
+	if t.Priority == "" {
+		t.Priority = enums.TodoPriorityNone
+	}
+	if t.UID == "" {
+		uid, err := NewTodoUID()
+		if err != nil {
+			return err
+		}
+		t.UID = uid
+	}
+	if t.DavName == "" {
+		t.DavName = t.UID + ".ics"
 	}
 	return nil
 }
 
+// Done reports whether the todo is in a status of the done category.
+func (t *Todo) Done() bool {
+	return t.StatusCategory == enums.StatusCategoryDone
+}
+
+// Blocked reports whether one of the blockers of the todo isn't done yet. Trashed
+// blockers don't block.
+func (t *Todo) Blocked() bool {
+	for i := range t.Blockers {
+		if !t.Blockers[i].Done() && !t.Blockers[i].DeletedAt.Valid {
+			return true
+		}
+	}
+	return false
+}
+
 // BeforeUpdate hook to handle timestamps
 func (t *Todo) BeforeUpdate(tx *gorm.DB) error {
 	t.UpdatedAt = time.Now()
 	return nil
 }
+
+// NewTodoUID returns a random UUID to use as the iCalendar UID of a todo.
+func NewTodoUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
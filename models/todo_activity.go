@@ -0,0 +1,31 @@
+package models
+
+import (
+	"go-feToDo/enums"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TodoActivity is an entry of the history of a todo. Entries are only ever appended, and
+// have no foreign key on the todo so that they outlive its permanent deletion.
+type TodoActivity struct {
+	ID        uint                     `json:"id" gorm:"primaryKey"`
+	TodoID    uint                     `json:"todo_id" gorm:"not null;index"`
+	ActorID   *uint                    `json:"actor_id" gorm:"index"` // nil when the app made the change, like the purge of the trash
+	Actor     *User                    `json:"-" gorm:"foreignKey:ActorID;constraint:OnDelete:SET NULL"`
+	Action    enums.TodoActivityAction `json:"action" gorm:"type:varchar(20);not null"`
+	Changes   string                   `json:"changes" gorm:"type:text"` // JSON object of the changed fields with their old and new values
+	CreatedAt time.Time                `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for the TodoActivity model
+func (TodoActivity) TableName() string {
+	return "todo_activities"
+}
+
+// BeforeCreate hook to handle timestamps
+func (a *TodoActivity) BeforeCreate(tx *gorm.DB) error {
+	a.CreatedAt = time.Now()
+	return nil
+}
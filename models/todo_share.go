@@ -0,0 +1,44 @@
+package models
+
+import (
+	"go-feToDo/enums"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TodoShare gives a user a role on a todo, or on every todo of a project, that belongs
+// to someone else. It only counts once the user has accepted the invitation.
+type TodoShare struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	TodoID      *uint           `json:"todo_id" gorm:"index"`
+	Todo        *Todo           `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	ProjectID   *uint           `json:"project_id" gorm:"index"`
+	Project     *Project        `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	UserID      uint            `json:"user_id" gorm:"not null;index"`
+	User        User            `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	InvitedByID uint            `json:"invited_by_id" gorm:"not null"`
+	InvitedBy   User            `json:"-" gorm:"foreignKey:InvitedByID;constraint:OnDelete:CASCADE"`
+	Role        enums.ShareRole `json:"role" gorm:"type:varchar(10);not null"`
+	AcceptedAt  *time.Time      `json:"accepted_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// TableName specifies the table name for the TodoShare model
+func (TodoShare) TableName() string {
+	return "todo_shares"
+}
+
+// BeforeCreate hook to handle timestamps
+func (s *TodoShare) BeforeCreate(tx *gorm.DB) error {
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate hook to handle timestamps
+func (s *TodoShare) BeforeUpdate(tx *gorm.DB) error {
+	s.UpdatedAt = time.Now()
+	return nil
+}
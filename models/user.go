@@ -9,13 +9,14 @@ import (
 )
 
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Username  string    `json:"username" gorm:"uniqueIndex;not null"`
-	Email     string    `json:"email" gorm:"uniqueIndex;not null"`
-	Password  string    `json:"-" gorm:"not null"` // "-" ensures this field isn't exposed in JSON
-	Todos     []Todo    `json:"todos" gorm:"foreignKey:AuthorID"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Username      string    `json:"username" gorm:"uniqueIndex;not null"`
+	Email         string    `json:"email" gorm:"uniqueIndex;not null"`
+	Password      string    `json:"-" gorm:"not null"`    // "-" ensures this field isn't exposed in JSON
+	FeedTokenHash *string   `json:"-" gorm:"uniqueIndex"` // SHA-256 of the secret token of the calendar feed
+	Todos         []Todo    `json:"todos" gorm:"foreignKey:AuthorID"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 func (User) TableName() string {
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"go-feToDo/enums"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Workflow is a set of named statuses todos go through, each in a category, and the
+// transitions allowed between them. Without transitions any status change is allowed.
+type Workflow struct {
+	ID          uint                 `json:"id" gorm:"primaryKey"`
+	Name        string               `json:"name" gorm:"not null"`
+	IsDefault   bool                 `json:"is_default" gorm:"not null;default:false"` // followed by the author's todos outside a project with a workflow
+	AuthorID    uint                 `json:"author_id" gorm:"not null;index"`
+	Author      User                 `json:"-" gorm:"foreignKey:AuthorID;constraint:OnDelete:CASCADE"`
+	Statuses    []WorkflowStatus     `json:"statuses" gorm:"constraint:OnDelete:CASCADE"`
+	Transitions []WorkflowTransition `json:"transitions" gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// WorkflowStatus is a status of a workflow. The first one by position is the status of new todos.
+type WorkflowStatus struct {
+	ID         uint                 `json:"id" gorm:"primaryKey"`
+	WorkflowID uint                 `json:"workflow_id" gorm:"not null;uniqueIndex:idx_workflow_status_name"`
+	Name       enums.TodoStatus     `json:"name" gorm:"type:varchar(50);not null;uniqueIndex:idx_workflow_status_name"`
+	Category   enums.StatusCategory `json:"category" gorm:"type:varchar(10);not null"`
+	Position   int                  `json:"position" gorm:"not null;default:0"`
+	WIPLimit   int                  `json:"wip_limit" gorm:"not null;default:0"` // most todos following the workflow in the status at once, 0 for no limit
+}
+
+// WorkflowTransition allows todos to go from a status of a workflow to another one.
+type WorkflowTransition struct {
+	ID         uint             `json:"id" gorm:"primaryKey"`
+	WorkflowID uint             `json:"workflow_id" gorm:"not null;index"`
+	From       enums.TodoStatus `json:"from" gorm:"column:from_status;type:varchar(50);not null"`
+	To         enums.TodoStatus `json:"to" gorm:"column:to_status;type:varchar(50);not null"`
+}
+
+// TableName specifies the table name for the Workflow model
+func (Workflow) TableName() string {
+	return "workflows"
+}
+
+// TableName specifies the table name for the WorkflowStatus model
+func (WorkflowStatus) TableName() string {
+	return "workflow_statuses"
+}
+
+// TableName specifies the table name for the WorkflowTransition model
+func (WorkflowTransition) TableName() string {
+	return "workflow_transitions"
+}
+
+// BeforeCreate hook to handle timestamps
+func (w *Workflow) BeforeCreate(tx *gorm.DB) error {
+	w.CreatedAt = time.Now()
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate hook to handle timestamps
+func (w *Workflow) BeforeUpdate(tx *gorm.DB) error {
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// DefaultWorkflow returns the built-in workflow, followed by the todos of users who
+// haven't chosen one. It isn't stored, its ID is 0.
+func DefaultWorkflow() *Workflow {
+	return &Workflow{
+		Name: "Default",
+		Statuses: []WorkflowStatus{
+			{Name: enums.TodoStatusPending, Category: enums.StatusCategoryTodo, Position: 0},
+			{Name: enums.TodoStatusInProgress, Category: enums.StatusCategoryDoing, Position: 1},
+			{Name: enums.TodoStatusCompleted, Category: enums.StatusCategoryDone, Position: 2},
+		},
+	}
+}
+
+// Status returns the status of the workflow with a name, nil if there is none.
+func (w *Workflow) Status(name enums.TodoStatus) *WorkflowStatus {
+	for i := range w.Statuses {
+		if w.Statuses[i].Name == name {
+			return &w.Statuses[i]
+		}
+	}
+	return nil
+}
+
+// Initial returns the status new todos start in. Statuses are kept ordered by position.
+func (w *Workflow) Initial() *WorkflowStatus {
+	return &w.Statuses[0]
+}
+
+// FirstOf returns the first status of a category, nil if the workflow has none.
+func (w *Workflow) FirstOf(category enums.StatusCategory) *WorkflowStatus {
+	for i := range w.Statuses {
+		if w.Statuses[i].Category == category {
+			return &w.Statuses[i]
+		}
+	}
+	return nil
+}
+
+// Allows reports whether todos can go from a status to another one.
+func (w *Workflow) Allows(from enums.TodoStatus, to enums.TodoStatus) bool {
+	if from == to || len(w.Transitions) == 0 {
+		return true
+	}
+	for _, transition := range w.Transitions {
+		if transition.From == from && transition.To == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"go-feToDo/controllers"
+	"go-feToDo/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BoardRoutes sets up board-related routes
+func BoardRoutes(router *gin.Engine) {
+	boardGroup := router.Group("/boards")
+	boardGroup.Use(middleware.IsAuthenticated())
+	{
+		boardGroup.GET("/", controllers.GetBoard)
+		boardGroup.POST("/move", controllers.MoveCard)
+	}
+}
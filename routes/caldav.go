@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"go-feToDo/controllers"
+	"go-feToDo/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DavRoutes sets up the CalDAV server, clients log in with an app password or an access token
+func DavRoutes(router *gin.Engine) {
+	router.GET("/.well-known/caldav", controllers.WellKnownCalDav)
+	router.Handle("PROPFIND", "/.well-known/caldav", controllers.WellKnownCalDav)
+
+	davGroup := router.Group("/dav")
+	davGroup.Use(middleware.IsDAVAuthenticated())
+	{
+		davGroup.OPTIONS("/*path", controllers.DavOptions)
+		davGroup.Handle("PROPFIND", "/*path", controllers.DavPropfind)
+		davGroup.Handle("REPORT", "/*path", controllers.DavReport)
+		davGroup.GET("/*path", controllers.DavGet)
+		davGroup.HEAD("/*path", controllers.DavGet)
+		davGroup.PUT("/*path", controllers.DavPut)
+		davGroup.DELETE("/*path", controllers.DavDelete)
+	}
+}
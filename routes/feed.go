@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"go-feToDo/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeedRoutes sets up the calendar feed routes, they are authenticated by the secret token of the URL
+func FeedRoutes(router *gin.Engine) {
+	feedGroup := router.Group("/feeds")
+	{
+		feedGroup.GET("/:token", controllers.GetTodoFeed)
+	}
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"go-feToDo/controllers"
+	"go-feToDo/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProjectRoutes sets up project-related routes
+func ProjectRoutes(router *gin.Engine) {
+	projectGroup := router.Group("/projects")
+	projectGroup.Use(middleware.IsAuthenticated())
+	{
+		projectGroup.GET("/", controllers.GetProjects)
+		projectGroup.GET("/:projectID", controllers.GetProjectById)
+		projectGroup.POST("/", controllers.CreateProject)
+		projectGroup.POST("/reorder", controllers.ReorderProjects)
+		projectGroup.PUT("/:projectID", controllers.RenameProject)
+		projectGroup.POST("/:projectID/archive", controllers.ArchiveProject)
+		projectGroup.POST("/:projectID/unarchive", controllers.UnarchiveProject)
+		projectGroup.POST("/:projectID/todos", controllers.MoveTodosToProject)
+		projectGroup.PUT("/:projectID/workflow", controllers.SetProjectWorkflow)
+		projectGroup.GET("/:projectID/dependencies", controllers.GetDependencyGraph)
+		projectGroup.DELETE("/:projectID", controllers.DeleteProject)
+		projectGroup.GET("/:projectID/shares", controllers.GetProjectShares)
+		projectGroup.POST("/:projectID/shares", controllers.ShareProject)
+		projectGroup.DELETE("/:projectID/shares/:shareID", controllers.RevokeProjectShare)
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"go-feToDo/controllers"
+	"go-feToDo/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportRoutes sets up report-related routes
+func ReportRoutes(router *gin.Engine) {
+	reportGroup := router.Group("/reports")
+	reportGroup.Use(middleware.IsAuthenticated())
+	{
+		reportGroup.GET("/time", controllers.GetTimeReport)
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"go-feToDo/controllers"
+	"go-feToDo/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShareRoutes sets up the routes of the invitations a user received
+func ShareRoutes(router *gin.Engine) {
+	shareGroup := router.Group("/shares")
+	shareGroup.Use(middleware.IsAuthenticated())
+	{
+		shareGroup.GET("/", controllers.GetUserShares)
+		shareGroup.POST("/:shareID/accept", controllers.AcceptShare)
+		shareGroup.DELETE("/:shareID", controllers.DeclineShare)
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"go-feToDo/controllers"
+	"go-feToDo/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagRoutes sets up tag-related routes
+func TagRoutes(router *gin.Engine) {
+	tagGroup := router.Group("/tags")
+	tagGroup.Use(middleware.IsAuthenticated())
+	{
+		tagGroup.GET("/", controllers.GetTags)
+		tagGroup.GET("/:tagID", controllers.GetTagById)
+		tagGroup.POST("/", controllers.CreateTag)
+		tagGroup.PUT("/:tagID", controllers.UpdateTag)
+		tagGroup.DELETE("/:tagID", controllers.DeleteTag)
+	}
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"go-feToDo/controllers"
+	"go-feToDo/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateRoutes sets up template-related routes
+func TemplateRoutes(router *gin.Engine) {
+	templateGroup := router.Group("/templates")
+	templateGroup.Use(middleware.IsAuthenticated())
+	{
+		templateGroup.GET("/", controllers.GetTemplates)
+		templateGroup.GET("/:templateID", controllers.GetTemplateById)
+		templateGroup.POST("/", controllers.CreateTemplate)
+		templateGroup.PUT("/:templateID", controllers.UpdateTemplate)
+		templateGroup.DELETE("/:templateID", controllers.DeleteTemplate)
+		templateGroup.POST("/:templateID/instantiate", controllers.InstantiateTemplate)
+	}
+}
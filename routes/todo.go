@@ -15,10 +15,50 @@ func TodoRoutes(router *gin.Engine) {
 		todoGroup.GET("/", controllers.GetActiveToDo)
 		todoGroup.GET("/all", controllers.GetAllToDo)
 		todoGroup.GET("/trash", controllers.GetTrashToDo)
+		todoGroup.GET("/search", controllers.SearchTodos)
+		todoGroup.GET("/export", controllers.ExportTodos)
+		todoGroup.GET("/shared", controllers.GetSharedTodos)
+		todoGroup.GET("/assigned", controllers.GetAssignedTodos)
 		todoGroup.GET("/:todoID", controllers.GetTodoById)
 		todoGroup.POST("/", controllers.CreateTodo)
+		todoGroup.POST("/restore", controllers.RestoreTodos)
+		todoGroup.POST("/bulk", controllers.BulkUpdateTodos)
+		todoGroup.POST("/import", controllers.ImportTodos)
+		todoGroup.DELETE("/trash", controllers.EmptyTrash)
 		todoGroup.PUT("/:todoID", controllers.UpdateTodo)
+		todoGroup.POST("/:todoID/move", controllers.MoveTodo)
 		todoGroup.DELETE("/:todoID/trash", controllers.SoftDeleteTodo)
+		todoGroup.POST("/:todoID/restore", controllers.RestoreTodo)
 		todoGroup.DELETE("/:todoID/permanent", controllers.DeleteTodo)
+		todoGroup.GET("/:todoID/occurrences", controllers.GetOccurrences)
+		todoGroup.PUT("/:todoID/occurrences", controllers.UpdateOccurrence)
+		todoGroup.POST("/:todoID/subtasks", controllers.AddSubtask)
+		todoGroup.POST("/:todoID/subtasks/reorder", controllers.ReorderSubtasks)
+		todoGroup.PUT("/:todoID/subtasks/:subtaskID", controllers.UpdateSubtask)
+		todoGroup.POST("/:todoID/subtasks/:subtaskID/toggle", controllers.ToggleSubtask)
+		todoGroup.DELETE("/:todoID/subtasks/:subtaskID", controllers.DeleteSubtask)
+		todoGroup.GET("/:todoID/comments", controllers.GetComments)
+		todoGroup.POST("/:todoID/comments", controllers.CreateComment)
+		todoGroup.PUT("/:todoID/comments/:commentID", controllers.UpdateComment)
+		todoGroup.DELETE("/:todoID/comments/:commentID", controllers.DeleteComment)
+		todoGroup.GET("/:todoID/attachments", controllers.GetAttachments)
+		todoGroup.POST("/:todoID/attachments", controllers.UploadAttachment)
+		todoGroup.GET("/:todoID/attachments/:attachmentID", controllers.DownloadAttachment)
+		todoGroup.DELETE("/:todoID/attachments/:attachmentID", controllers.DeleteAttachment)
+		todoGroup.GET("/:todoID/history", controllers.GetTodoHistory)
+		todoGroup.POST("/:todoID/template", controllers.SaveTodoAsTemplate)
+		todoGroup.POST("/:todoID/timer/start", controllers.StartTimer)
+		todoGroup.POST("/:todoID/timer/stop", controllers.StopTimer)
+		todoGroup.GET("/:todoID/time-entries", controllers.GetTimeEntries)
+		todoGroup.POST("/:todoID/time-entries", controllers.CreateTimeEntry)
+		todoGroup.PUT("/:todoID/time-entries/:entryID", controllers.UpdateTimeEntry)
+		todoGroup.DELETE("/:todoID/time-entries/:entryID", controllers.DeleteTimeEntry)
+		todoGroup.POST("/:todoID/dependencies", controllers.AddDependency)
+		todoGroup.DELETE("/:todoID/dependencies/:blockerID", controllers.RemoveDependency)
+		todoGroup.PUT("/:todoID/assignee", controllers.AssignTodo)
+		todoGroup.DELETE("/:todoID/assignee", controllers.UnassignTodo)
+		todoGroup.GET("/:todoID/shares", controllers.GetTodoShares)
+		todoGroup.POST("/:todoID/shares", controllers.ShareTodo)
+		todoGroup.DELETE("/:todoID/shares/:shareID", controllers.RevokeTodoShare)
 	}
 }
@@ -15,5 +15,10 @@ func UserRoutes(router *gin.Engine) {
 		userGroup.GET("/", controllers.GetUserById)
 		userGroup.PUT("/", controllers.UpdateUser)
 		userGroup.DELETE("/", controllers.DeleteUser)
+		userGroup.POST("/feed-token", controllers.CreateFeedToken)
+		userGroup.DELETE("/feed-token", controllers.RevokeFeedToken)
+		userGroup.GET("/app-passwords", controllers.GetAppPasswords)
+		userGroup.POST("/app-passwords", controllers.CreateAppPassword)
+		userGroup.DELETE("/app-passwords/:passwordID", controllers.DeleteAppPassword)
 	}
 }
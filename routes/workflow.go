@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"go-feToDo/controllers"
+	"go-feToDo/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkflowRoutes sets up workflow-related routes
+func WorkflowRoutes(router *gin.Engine) {
+	workflowGroup := router.Group("/workflows")
+	workflowGroup.Use(middleware.IsAuthenticated())
+	{
+		workflowGroup.GET("/", controllers.GetWorkflows)
+		workflowGroup.POST("/", controllers.CreateWorkflow)
+		workflowGroup.PUT("/:workflowID", controllers.UpdateWorkflow)
+		workflowGroup.DELETE("/:workflowID", controllers.DeleteWorkflow)
+		workflowGroup.PUT("/:workflowID/default", controllers.SetDefaultWorkflow)
+	}
+}
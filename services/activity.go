@@ -0,0 +1,197 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"reflect"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetTodoHistory reads one page of the history of a todo the user can see, newest first.
+// Only owners see the history of a trashed todo, like the todo itself.
+func GetTodoHistory(todoID string, query *dto.HistoryQueryDTO, authorID string) (*dto.HistoryPageDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	var todo models.Todo
+	err = db.Unscoped().Where("id = ?", todoIDUint).First(&todo).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrToDoNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	needed := enums.ShareRoleViewer
+	if todo.DeletedAt.Valid {
+		needed = enums.ShareRoleOwner
+	}
+	if err := authorizeTodo(db, &todo, authorIDUint, needed); err != nil {
+		return nil, err
+	}
+
+	tx := db.Preload("Actor").Where("todo_id = ?", todo.ID)
+	if query.Cursor != "" {
+		cursor, err := decodeIDCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Where("id < ?", cursor.ID)
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	// One more entry tells whether there is a next page
+	var activities []models.TodoActivity
+	if err := tx.Order("id DESC").Limit(limit + 1).Find(&activities).Error; err != nil {
+		return nil, err
+	}
+
+	page := &dto.HistoryPageDTO{Entries: make([]*dto.TodoActivityResponseDTO, 0, len(activities))}
+	if len(activities) > limit {
+		activities = activities[:limit]
+		next := encodeIDCursor(activities[limit-1].ID)
+		page.NextCursor = &next
+	}
+
+	for i := range activities {
+		page.Entries = append(page.Entries, utils.ToTodoActivityResponseDTO(&activities[i]))
+	}
+
+	return page, nil
+}
+
+// recordActivity appends an entry to the history of a todo. It runs on the transaction
+// of the change it records. A nil actor means the app made the change.
+func recordActivity(tx *gorm.DB, todoID uint, actorID *uint, action enums.TodoActivityAction, changes map[string]dto.FieldChangeDTO) error {
+	if changes == nil {
+		changes = map[string]dto.FieldChangeDTO{}
+	}
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.TodoActivity{
+		TodoID:  todoID,
+		ActorID: actorID,
+		Action:  action,
+		Changes: string(raw),
+	}).Error
+}
+
+// trackTodoUpdate runs an update of a todo, loaded with its tags, and records the fields
+// it changed in the history of the todo. Nothing is recorded when nothing changed.
+func trackTodoUpdate(tx *gorm.DB, todo *models.Todo, actorID *uint, update func() error) error {
+	before := todoSnapshot(todo)
+	if err := update(); err != nil {
+		return err
+	}
+	return recordTodoUpdate(tx, todo.ID, before, actorID)
+}
+
+// recordTodoUpdate reads a todo back and records the fields that changed since a
+// snapshot of it was taken.
+func recordTodoUpdate(tx *gorm.DB, todoID uint, before map[string]any, actorID *uint) error {
+	var todo models.Todo
+	if err := tx.Unscoped().Preload("Tags").First(&todo, todoID).Error; err != nil {
+		return err
+	}
+	changes := diffSnapshots(before, todoSnapshot(&todo))
+	if len(changes) == 0 {
+		return nil
+	}
+	return recordActivity(tx, todoID, actorID, enums.TodoActivityUpdated, changes)
+}
+
+// todoSnapshot captures the fields of a todo its history tracks, as the API shows them.
+// The tags of the todo must be loaded.
+func todoSnapshot(todo *models.Todo) map[string]any {
+	tagIDs := make([]uint, 0, len(todo.Tags))
+	for _, tag := range todo.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	sort.Slice(tagIDs, func(i, j int) bool { return tagIDs[i] < tagIDs[j] })
+
+	return map[string]any{
+		"title":         todo.Title,
+		"description":   todo.Description,
+		"status":        todo.Status,
+		"priority":      todo.Priority,
+		"project_id":    snapshotID(todo.ProjectID),
+		"assignee_id":   snapshotID(todo.AssigneeID),
+		"recurrence_id": snapshotID(todo.RecurrenceID),
+		"start_at":      snapshotTime(todo.StartAt),
+		"due_at":        snapshotTime(todo.DueAt),
+		"tag_ids":       tagIDs,
+	}
+}
+
+// diffSnapshots returns the fields that differ between two snapshots of a todo. A nil
+// snapshot stands for a todo that doesn't exist: its creation or deletion only lists the
+// fields that are set.
+func diffSnapshots(before map[string]any, after map[string]any) map[string]dto.FieldChangeDTO {
+	fields := after
+	if fields == nil {
+		fields = before
+	}
+
+	changes := map[string]dto.FieldChangeDTO{}
+	for field := range fields {
+		old, value := before[field], after[field]
+		if reflect.DeepEqual(old, value) {
+			continue
+		}
+		if (before == nil && emptyValue(value)) || (after == nil && emptyValue(old)) {
+			continue
+		}
+		changes[field] = dto.FieldChangeDTO{Old: old, New: value}
+	}
+	return changes
+}
+
+// snapshotID unwraps an optional id so that snapshots compare by value.
+func snapshotID(id *uint) any {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+// snapshotTime unwraps an optional date, in UTC so that snapshots compare by instant.
+func snapshotTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// emptyValue reports whether a snapshot value is unset.
+func emptyValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Slice {
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// appPasswordUseInterval is how stale the last use of an app password may get before
+// it is written again, so a client syncing every few seconds doesn't write every time.
+const appPasswordUseInterval = time.Minute
+
+// GetAppPasswords retrieves the app passwords of a user, without the passwords themselves.
+func GetAppPasswords(userID string) ([]*dto.AppPasswordResponseDTO, error) {
+	db := database.GetDB()
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	var passwords []models.AppPassword
+	if err := db.Where("user_id = ?", userIDUint).Order("id").Find(&passwords).Error; err != nil {
+		return nil, err
+	}
+
+	passwordDTOs := make([]*dto.AppPasswordResponseDTO, 0, len(passwords))
+	for i := range passwords {
+		passwordDTOs = append(passwordDTOs, utils.ToAppPasswordResponseDTO(&passwords[i]))
+	}
+
+	return passwordDTOs, nil
+}
+
+// CreateAppPassword generates a new app password for the user and returns it.
+// Only its hash is kept, so the password is shown once.
+func CreateAppPassword(userID string, passwordDTO *dto.CreateAppPasswordDTO) (*dto.AppPasswordCreatedDTO, error) {
+	db := database.GetDB()
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	password, hash, err := utils.NewSecretToken()
+	if err != nil {
+		return nil, dto.ErrAppPasswordCreate
+	}
+
+	appPassword := models.AppPassword{Name: passwordDTO.Name, Hash: hash, UserID: userIDUint}
+	if err := db.Create(&appPassword).Error; err != nil {
+		return nil, dto.ErrAppPasswordCreate
+	}
+
+	return &dto.AppPasswordCreatedDTO{
+		AppPasswordResponseDTO: *utils.ToAppPasswordResponseDTO(&appPassword),
+		Password:               password,
+	}, nil
+}
+
+// DeleteAppPassword revokes an app password of the user.
+func DeleteAppPassword(passwordID string, userID string) error {
+	db := database.GetDB()
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	passwordIDUint, err := utils.ConvId(passwordID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	result := db.Where("id = ? AND user_id = ?", passwordIDUint, userIDUint).Delete(&models.AppPassword{})
+	if result.Error != nil {
+		return dto.ErrAppPasswordDelete
+	}
+	if result.RowsAffected == 0 {
+		return dto.ErrAppPasswordNotFound
+	}
+
+	return nil
+}
+
+// AuthenticateAppPassword finds the user logging in with an app password. The login
+// is the username or the email of the user the password belongs to.
+func AuthenticateAppPassword(login string, password string) (*dto.JWTPayloadDTO, error) {
+	db := database.GetDB()
+
+	var appPassword models.AppPassword
+	err := db.Preload("User").Where("hash = ?", utils.HashSecretToken(password)).First(&appPassword).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrJWTInvalidCreds
+	}
+	if err != nil {
+		return nil, err
+	}
+	if login != appPassword.User.Username && login != appPassword.User.Email {
+		return nil, dto.ErrJWTInvalidCreds
+	}
+
+	now := time.Now()
+	if appPassword.LastUsedAt == nil || now.Sub(*appPassword.LastUsedAt) > appPasswordUseInterval {
+		if err := db.Model(&appPassword).UpdateColumn("last_used_at", now).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.JWTPayloadDTO{Id: fmt.Sprint(appPassword.User.ID), Username: appPassword.User.Username}, nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+
+	"gorm.io/gorm"
+)
+
+// AssignTodo makes a user responsible for a todo the user can edit. The assignee must
+// have access to the todo, the reassignment is recorded in its history.
+func AssignTodo(todoID string, assignDTO *dto.AssignTodoDTO, authorID string) (*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	assignee, err := findUserByLogin(db, assignDTO.User)
+	if err != nil {
+		return nil, err
+	}
+	role, err := todoRole(db, todo, assignee.ID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, dto.ErrToDoAssigneeNoAccess
+	}
+
+	if err := setAssignee(db, todo, &assignee.ID, authorIDUint); err != nil {
+		return nil, err
+	}
+
+	return reloadTodo(db, todo.ID)
+}
+
+// UnassignTodo removes the assignee of a todo the user can edit, recording it in its history.
+func UnassignTodo(todoID string, authorID string) (*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setAssignee(db, todo, nil, authorIDUint); err != nil {
+		return nil, err
+	}
+
+	return reloadTodo(db, todo.ID)
+}
+
+// GetAssignedTodos reads one page of the active todos assigned to the user that they still
+// have access to, filtered and sorted like the todo lists. They come due first by default,
+// their manual positions belonging to different authors.
+func GetAssignedTodos(authorID string, query *dto.TodoQueryDTO) (*dto.TodoPageDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	if query.Sort == "" {
+		query.Sort = "due"
+	}
+
+	// A share revoked since the assignment hides the todo again
+	tx := db.Where("todos.assignee_id = ?", authorIDUint).Scopes(accessibleTodos(authorIDUint))
+	return pageTodos(tx, query)
+}
+
+// setAssignee changes the assignee of a todo and records the change in the same
+// transaction. Assigning the current assignee again changes nothing.
+func setAssignee(db *gorm.DB, todo *models.Todo, assigneeID *uint, actorID uint) error {
+	if equalIDs(todo.AssigneeID, assigneeID) {
+		return nil
+	}
+
+	action := enums.TodoActivityAssigned
+	if assigneeID == nil {
+		action = enums.TodoActivityUnassigned
+	}
+
+	previousID := todo.AssigneeID
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(todo).Update("assignee_id", assigneeID).Error; err != nil {
+			return dto.ErrToDoAssign
+		}
+		return recordActivity(tx, todo.ID, &actorID, action, map[string]dto.FieldChangeDTO{
+			"assignee_id": {Old: previousID, New: assigneeID},
+		})
+	})
+}
+
+// equalIDs reports whether two optional ids are both unset or the same.
+func equalIDs(a *uint, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-feToDo/config"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/storage"
+	"go-feToDo/utils"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GetAttachments lists the files attached to a todo the user can see, oldest first.
+func GetAttachments(todoID string, authorID string) ([]*dto.AttachmentResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachments []models.Attachment
+	if err := db.Preload("Uploader").Where("todo_id = ?", todo.ID).Order("id").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+
+	attachmentDTOs := make([]*dto.AttachmentResponseDTO, 0, len(attachments))
+	for i := range attachments {
+		attachmentDTOs = append(attachmentDTOs, utils.ToAttachmentResponseDTO(&attachments[i]))
+	}
+	return attachmentDTOs, nil
+}
+
+// UploadAttachment attaches a file to a todo the user can edit. The type of the file is
+// detected from its content and must be one of the accepted types.
+func UploadAttachment(todoID string, fileName string, file io.ReadSeeker, size int64, authorID string) (*dto.AttachmentResponseDTO, error) {
+	db := database.GetDB()
+	cfg := config.LoadConfig()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > int64(cfg.AttachmentMaxSize) {
+		return nil, dto.ErrAttachmentTooLarge
+	}
+	mtype, err := mimetype.DetectReader(file)
+	if err != nil {
+		return nil, dto.ErrAttachmentFile
+	}
+	if !attachmentTypeAllowed(mtype, cfg.AttachmentTypes) {
+		return nil, dto.ErrAttachmentType
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, dto.ErrAttachmentFile
+	}
+
+	uid, err := models.NewTodoUID()
+	if err != nil {
+		return nil, err
+	}
+	attachment := models.Attachment{
+		TodoID:      todo.ID,
+		UploaderID:  authorIDUint,
+		FileName:    attachmentFileName(fileName),
+		ContentType: mtype.String(),
+		Size:        size,
+		StorageKey:  fmt.Sprintf("todos/%d/%s", todo.ID, uid),
+	}
+
+	// The file is stored first so that a row never points to a missing file
+	ctx := context.Background()
+	if err := storage.GetStorage().Put(ctx, attachment.StorageKey, file, size, attachment.ContentType); err != nil {
+		return nil, dto.ErrAttachmentCreate
+	}
+	if err := db.Create(&attachment).Error; err != nil {
+		removeBlobs([]string{attachment.StorageKey})
+		return nil, dto.ErrAttachmentCreate
+	}
+
+	if err := db.Preload("Uploader").First(&attachment, attachment.ID).Error; err != nil {
+		return nil, err
+	}
+	return utils.ToAttachmentResponseDTO(&attachment), nil
+}
+
+// OpenAttachment opens the content of a file attached to a todo the user can see.
+// The caller closes the reader.
+func OpenAttachment(todoID string, attachmentID string, authorID string) (*dto.AttachmentResponseDTO, io.ReadCloser, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, nil, dto.ErrAuthIdConv
+	}
+
+	attachment, _, err := findAttachment(db, todoID, attachmentID, authorIDUint, enums.ShareRoleViewer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := storage.GetStorage().Get(context.Background(), attachment.StorageKey)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return nil, nil, dto.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return utils.ToAttachmentResponseDTO(attachment), content, nil
+}
+
+// DeleteAttachment removes a file attached to a todo. Editors can remove the files they
+// uploaded, owners any file.
+func DeleteAttachment(todoID string, attachmentID string, authorID string) error {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	attachment, todo, err := findAttachment(db, todoID, attachmentID, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return err
+	}
+	if attachment.UploaderID != authorIDUint {
+		if err := authorizeTodo(db, todo, authorIDUint, enums.ShareRoleOwner); err != nil {
+			return err
+		}
+	}
+
+	if err := db.Delete(attachment).Error; err != nil {
+		return dto.ErrAttachmentDelete
+	}
+	removeBlobs([]string{attachment.StorageKey})
+	return nil
+}
+
+// findAttachment loads an attachment of a todo the user has at least a role on.
+func findAttachment(db *gorm.DB, todoID string, attachmentID string, userID uint, needed enums.ShareRole) (*models.Attachment, *models.Todo, error) {
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, nil, dto.ErrAuthIdConv
+	}
+	attachmentIDUint, err := utils.ConvId(attachmentID)
+	if err != nil {
+		return nil, nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, userID, needed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var attachment models.Attachment
+	err = db.Preload("Uploader").Where("id = ? AND todo_id = ?", attachmentIDUint, todo.ID).First(&attachment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, dto.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &attachment, todo, nil
+}
+
+// attachmentKeys returns the storage keys of the files attached to todos.
+func attachmentKeys(tx *gorm.DB, todos []models.Todo) ([]string, error) {
+	ids := make([]uint, 0, len(todos))
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+	var keys []string
+	err := tx.Model(&models.Attachment{}).Where("todo_id IN ?", ids).Pluck("storage_key", &keys).Error
+	return keys, err
+}
+
+// removeBlobs deletes stored files whose rows are gone. A file that can't be deleted is
+// only left behind in the storage, so failures are logged rather than returned.
+func removeBlobs(keys []string) {
+	store := storage.GetStorage()
+	for _, key := range keys {
+		if err := store.Delete(context.Background(), key); err != nil {
+			zap.L().Error("failed to delete a stored file", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// attachmentTypeAllowed reports whether a detected type is in a comma-separated list of types.
+func attachmentTypeAllowed(mtype *mimetype.MIME, allowed string) bool {
+	for _, t := range strings.Split(allowed, ",") {
+		if t = strings.TrimSpace(t); t != "" && mtype.Is(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// attachmentFileName keeps the base name of an uploaded file, within the column size.
+func attachmentFileName(name string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		name = strings.ToValidUTF8(name[:255], "")
+	}
+	return name
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"errors"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// boardOrder orders the todos of a column, those never placed on the board last in the
+// order of the list.
+const boardOrder = "CASE WHEN todos.board_position IS NULL OR todos.board_position = '' THEN 1 ELSE 0 END, todos.board_position, todos.position, todos.id"
+
+// GetBoard returns the active todos of a project of the user, or those following their
+// default workflow, in a column for each status of the workflow.
+func GetBoard(query *dto.BoardQueryDTO, authorID string) (*dto.BoardResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	var projectID *uint
+	if query.ProjectID != 0 {
+		project, err := findProject(db, query.ProjectID, authorIDUint)
+		if err != nil {
+			return nil, err
+		}
+		projectID = &project.ID
+	}
+	workflow, err := workflowOf(db, authorIDUint, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := db.Scopes(withTodoRelations)
+	if projectID != nil {
+		tx = tx.Where("todos.author_id = ? AND todos.project_id = ?", authorIDUint, *projectID)
+	} else {
+		tx = tx.Scopes(followingWorkflow(authorIDUint, workflow))
+	}
+	var todos []models.Todo
+	if err := tx.Order(boardOrder).Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	board := &dto.BoardResponseDTO{
+		Workflow:  utils.ToWorkflowResponseDTO(workflow),
+		ProjectID: projectID,
+		Columns:   make([]*dto.BoardColumnDTO, 0, len(workflow.Statuses)),
+	}
+	columns := map[string]*dto.BoardColumnDTO{}
+	for _, status := range workflow.Statuses {
+		column := &dto.BoardColumnDTO{
+			Status:   status.Name,
+			Category: status.Category,
+			WIPLimit: status.WIPLimit,
+			Todos:    []*dto.TodoResponseDTO{},
+		}
+		columns[string(status.Name)] = column
+		board.Columns = append(board.Columns, column)
+	}
+	for i := range todos {
+		if column, ok := columns[string(todos[i].Status)]; ok {
+			column.Todos = append(column.Todos, utils.ToTodoResponseDTO(&todos[i]))
+		}
+	}
+
+	return board, nil
+}
+
+// MoveCard moves a todo of the user to a column of its board and places it between two
+// todos of the column, in one go. A change of column follows the transitions of the
+// workflow and the WIP limit of the new column.
+func MoveCard(moveDTO *dto.MoveCardDTO, authorID string) (*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	return moveCard(db, moveDTO, authorIDUint)
+}
+
+// moveCard moves a card for MoveCard once the user is known.
+func moveCard(db *gorm.DB, moveDTO *dto.MoveCardDTO, authorIDUint uint) (*dto.TodoResponseDTO, error) {
+	// Editors of a shared todo or project move its cards too
+	todo, err := findTodo(db, moveDTO.TodoID, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := todoWorkflow(db, todo)
+	if err != nil {
+		return nil, err
+	}
+	// Moving a todo within its column needs no transition
+	status := workflow.Status(todo.Status)
+	if moveDTO.Status != todo.Status {
+		status, err = checkStatusChange(workflow, todo.Status, moveDTO.Status)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if status == nil {
+		return nil, dto.ErrToDoStatusUnknown
+	}
+	if !moveDTO.Force {
+		if err := checkBlockers(todo, status); err != nil {
+			return nil, err
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if todo.Status != status.Name {
+			if err := checkWIPLimit(tx, todo, workflow, status); err != nil {
+				return err
+			}
+			err := trackTodoUpdate(tx, todo, &authorIDUint, func() error {
+				return setTodoStatus(tx, todo, status)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// Without neighbours the todo stays where it is, at the end of a new column
+		if err := ensureBoardPositions(tx, todo, workflow); err != nil {
+			return err
+		}
+		if moveDTO.BeforeID == nil && moveDTO.AfterID == nil {
+			return nil
+		}
+		before, err := findCardNeighbour(tx, moveDTO.BeforeID, todo, workflow)
+		if err != nil {
+			return err
+		}
+		after, err := findCardNeighbour(tx, moveDTO.AfterID, todo, workflow)
+		if err != nil {
+			return err
+		}
+
+		// A single neighbour is completed with the todo next to it in the column
+		if before != nil && after == nil {
+			after, err = adjacentCard(tx, todo, workflow, "board_position > ?", before.BoardPosition, "board_position, id")
+		} else if after != nil && before == nil {
+			before, err = adjacentCard(tx, todo, workflow, "board_position < ?", after.BoardPosition, "board_position DESC, id DESC")
+		}
+		if err != nil {
+			return err
+		}
+
+		var lower, upper string
+		if before != nil {
+			lower = before.BoardPosition
+		}
+		if after != nil {
+			upper = after.BoardPosition
+		}
+		position, err := utils.RankBetween(lower, upper)
+		if err != nil {
+			return dto.ErrToDoMoveNeighbours
+		}
+
+		return tx.Model(&models.Todo{}).Where("id = ?", todo.ID).Update("board_position", position).Error
+	})
+	if errors.Is(err, dto.ErrToDoMoveNeighbours) || errors.Is(err, dto.ErrToDoNotFound) || errors.Is(err, dto.ErrUnauthToDo) ||
+		errors.Is(err, dto.ErrBoardWIPLimit) || errors.Is(err, dto.ErrToDoSubtasksOpen) || errors.Is(err, dto.ErrToDoBlocked) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, dto.ErrToDoUpdate
+	}
+
+	return reloadTodo(db, todo.ID)
+}
+
+// followingWorkflow matches the active todos of an author following a workflow: those of
+// the projects using it and, for the default workflow, those outside a project with a workflow.
+func followingWorkflow(authorID uint, workflow *models.Workflow) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		session := db.Session(&gorm.Session{NewDB: true})
+		projects := session.Model(&models.Project{}).Select("id").Where("author_id = ? AND workflow_id = ?", authorID, workflow.ID)
+		following := session.Where("todos.project_id IN (?)", projects)
+		if workflow.ID == 0 || workflow.IsDefault {
+			plain := session.Model(&models.Project{}).Select("id").Where("author_id = ? AND workflow_id IS NULL", authorID)
+			following = following.Or("todos.project_id IS NULL").Or("todos.project_id IN (?)", plain)
+		}
+		return db.Where("todos.author_id = ?", authorID).Where(following)
+	}
+}
+
+// checkWIPLimit makes sure the column of a status has room for one more todo. The limit
+// counts every active todo of the author following the workflow in the status.
+func checkWIPLimit(tx *gorm.DB, todo *models.Todo, workflow *models.Workflow, status *models.WorkflowStatus) error {
+	if status.WIPLimit == 0 {
+		return nil
+	}
+
+	// Moves into the columns of a same workflow wait for each other
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Workflow{}, workflow.ID).Error; err != nil {
+		return err
+	}
+
+	var count int64
+	err := tx.Model(&models.Todo{}).Scopes(followingWorkflow(todo.AuthorID, workflow)).
+		Where("todos.status = ? AND todos.id <> ?", status.Name, todo.ID).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count >= int64(status.WIPLimit) {
+		return dto.ErrBoardWIPLimit
+	}
+	return nil
+}
+
+// ensureBoardPositions places the active todos of the column of a todo never placed on the
+// board after the others, in the order of the list. A column holds the todos of the author
+// following the workflow in the status of the todo.
+func ensureBoardPositions(tx *gorm.DB, todo *models.Todo, workflow *models.Workflow) error {
+	column := boardColumn(todo, workflow)
+	var unplaced []models.Todo
+	err := tx.Scopes(column).Where("todos.board_position IS NULL OR todos.board_position = ''").
+		Order("todos.position, todos.id").Find(&unplaced).Error
+	if err != nil {
+		return err
+	}
+	if len(unplaced) == 0 {
+		return nil
+	}
+
+	var last *string
+	if err := tx.Model(&models.Todo{}).Scopes(column).Select("MAX(todos.board_position)").Scan(&last).Error; err != nil {
+		return err
+	}
+	var lower string
+	if last != nil {
+		lower = *last
+	}
+	for _, unplacedTodo := range unplaced {
+		position, err := utils.RankBetween(lower, "")
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Todo{}).Where("id = ?", unplacedTodo.ID).Update("board_position", position).Error; err != nil {
+			return err
+		}
+		lower = position
+	}
+	return nil
+}
+
+// boardColumn matches the active todos in the column of a todo on the board of its workflow.
+func boardColumn(todo *models.Todo, workflow *models.Workflow) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(followingWorkflow(todo.AuthorID, workflow)).Where("todos.status = ?", todo.Status)
+	}
+}
+
+// findCardNeighbour loads the todo of the column a todo is moved next to, if one was given.
+func findCardNeighbour(tx *gorm.DB, neighbourID *uint, todo *models.Todo, workflow *models.Workflow) (*models.Todo, error) {
+	neighbour, err := findNeighbour(tx, neighbourID, todo)
+	if err != nil || neighbour == nil {
+		return neighbour, err
+	}
+	if neighbour.Status != todo.Status {
+		return nil, dto.ErrToDoMoveNeighbours
+	}
+
+	// A status of the same name in another workflow is another column
+	neighbourWorkflow, err := todoWorkflow(tx, neighbour)
+	if err != nil {
+		return nil, err
+	}
+	if neighbourWorkflow.ID != workflow.ID {
+		return nil, dto.ErrToDoMoveNeighbours
+	}
+	return neighbour, nil
+}
+
+// adjacentCard finds the closest todo of the column on one side of a position, ignoring the moved todo.
+func adjacentCard(tx *gorm.DB, todo *models.Todo, workflow *models.Workflow, condition string, position string, order string) (*models.Todo, error) {
+	var adjacent models.Todo
+	err := tx.Scopes(boardColumn(todo, workflow)).Where("todos.id <> ?", todo.ID).
+		Where("todos."+condition, position).Order(order).Limit(1).Find(&adjacent).Error
+	if err != nil {
+		return nil, err
+	}
+	if adjacent.ID == 0 {
+		return nil, nil
+	}
+	return &adjacent, nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMoveCard(t *testing.T) {
+	db := newTestDB(t)
+	var ada, bob, cy models.User
+	for _, user := range []struct {
+		user *models.User
+		name string
+	}{{&ada, "ada"}, {&bob, "bob"}, {&cy, "cy"}} {
+		*user.user = models.User{Username: user.name, Email: user.name + "@example.com"}
+		if err := db.Create(user.user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	kanban := models.Workflow{Name: "Kanban", IsDefault: true, AuthorID: ada.ID, Statuses: []models.WorkflowStatus{
+		{Name: "todo", Category: enums.StatusCategoryTodo, Position: 0},
+		{Name: "doing", Category: enums.StatusCategoryDoing, Position: 1, WIPLimit: 2},
+		{Name: "done", Category: enums.StatusCategoryDone, Position: 2},
+	}}
+	sprint := models.Workflow{Name: "Sprint", AuthorID: ada.ID, Statuses: []models.WorkflowStatus{
+		{Name: "todo", Category: enums.StatusCategoryTodo, Position: 0},
+		{Name: "done", Category: enums.StatusCategoryDone, Position: 1},
+	}}
+	for _, workflow := range []*models.Workflow{&kanban, &sprint} {
+		if err := db.Create(workflow).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	project := models.Project{Name: "sprint", AuthorID: ada.ID, WorkflowID: &sprint.ID}
+	if err := db.Create(&project).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Cards in the todo column of the default workflow are unplaced and come in the
+	// order of the list. The trashed ones and the one in the project of another
+	// workflow, with a status of the same name, are in no column of the board.
+	todo := func(title string, status enums.TodoStatus, category enums.StatusCategory, position string) *models.Todo {
+		return &models.Todo{Title: title, Status: status, StatusCategory: category, Position: position, AuthorID: ada.ID}
+	}
+	a := todo("a", "todo", enums.StatusCategoryTodo, "b")
+	b := todo("b", "todo", enums.StatusCategoryTodo, "c")
+	c := todo("c", "todo", enums.StatusCategoryTodo, "d")
+	x := todo("x", "todo", enums.StatusCategoryTodo, "e")
+	trashed := todo("trashed", "todo", enums.StatusCategoryTodo, "a")
+	trashedDoing := todo("trashed doing", "doing", enums.StatusCategoryDoing, "a")
+	started := todo("started", "doing", enums.StatusCategoryDoing, "f")
+	started.BoardPosition = "i"
+	other := todo("other", "todo", enums.StatusCategoryTodo, "a0")
+	other.ProjectID = &project.ID
+	for _, todo := range []*models.Todo{a, b, c, x, trashed, trashedDoing, started, other} {
+		if err := db.Create(todo).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Delete(&models.Todo{}, []uint{trashed.ID, trashedDoing.ID}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Table("todo_dependencies").Create(map[string]any{"todo_id": x.ID, "blocker_id": b.ID}).Error; err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	shares := []models.TodoShare{
+		{TodoID: &a.ID, UserID: bob.ID, InvitedByID: ada.ID, Role: enums.ShareRoleEditor, AcceptedAt: &now},
+		{TodoID: &a.ID, UserID: cy.ID, InvitedByID: ada.ID, Role: enums.ShareRoleViewer, AcceptedAt: &now},
+	}
+	if err := db.Create(&shares).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// The moves run in order, each on the board the previous ones left
+	steps := []struct {
+		name    string
+		userID  uint
+		move    dto.MoveCardDTO
+		wantErr error
+		want    map[enums.TodoStatus][]string
+	}{
+		{
+			name:   "before the first card",
+			userID: ada.ID,
+			move:   dto.MoveCardDTO{TodoID: c.ID, Status: "todo", AfterID: &a.ID},
+			want:   map[enums.TodoStatus][]string{"todo": {"c", "a", "b", "x"}, "doing": {"started"}},
+		},
+		{
+			name:   "between two adjacent cards",
+			userID: ada.ID,
+			move:   dto.MoveCardDTO{TodoID: b.ID, Status: "todo", BeforeID: &c.ID, AfterID: &a.ID},
+			want:   map[enums.TodoStatus][]string{"todo": {"c", "b", "a", "x"}},
+		},
+		{
+			name:   "after a card, the next one completing the neighbours",
+			userID: ada.ID,
+			move:   dto.MoveCardDTO{TodoID: x.ID, Status: "todo", BeforeID: &c.ID},
+			want:   map[enums.TodoStatus][]string{"todo": {"c", "x", "b", "a"}},
+		},
+		{
+			name:    "next to a card of another workflow",
+			userID:  ada.ID,
+			move:    dto.MoveCardDTO{TodoID: a.ID, Status: "todo", BeforeID: &other.ID},
+			wantErr: dto.ErrToDoMoveNeighbours,
+		},
+		{
+			name:    "next to a trashed card",
+			userID:  ada.ID,
+			move:    dto.MoveCardDTO{TodoID: a.ID, Status: "todo", AfterID: &trashed.ID},
+			wantErr: dto.ErrToDoNotFound,
+		},
+		{
+			name:    "by a viewer",
+			userID:  cy.ID,
+			move:    dto.MoveCardDTO{TodoID: a.ID, Status: "doing"},
+			wantErr: dto.ErrUnauthToDo,
+		},
+		{
+			name:   "by an editor to the end of another column",
+			userID: bob.ID,
+			move:   dto.MoveCardDTO{TodoID: a.ID, Status: "doing"},
+			want:   map[enums.TodoStatus][]string{"todo": {"c", "x", "b"}, "doing": {"started", "a"}},
+		},
+		{
+			name:    "into a full column",
+			userID:  ada.ID,
+			move:    dto.MoveCardDTO{TodoID: b.ID, Status: "doing"},
+			wantErr: dto.ErrBoardWIPLimit,
+		},
+		{
+			name:   "within a full column",
+			userID: ada.ID,
+			move:   dto.MoveCardDTO{TodoID: a.ID, Status: "doing", AfterID: &started.ID},
+			want:   map[enums.TodoStatus][]string{"doing": {"a", "started"}},
+		},
+		{
+			name:    "to done while blocked",
+			userID:  ada.ID,
+			move:    dto.MoveCardDTO{TodoID: x.ID, Status: "done"},
+			wantErr: dto.ErrToDoBlocked,
+		},
+		{
+			name:   "to done while blocked, forced",
+			userID: ada.ID,
+			move:   dto.MoveCardDTO{TodoID: x.ID, Status: "done", Force: true},
+			want:   map[enums.TodoStatus][]string{"todo": {"c", "b"}, "done": {"x"}},
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			_, err := moveCard(db, &step.move, step.userID)
+			if !errors.Is(err, step.wantErr) {
+				t.Fatalf("moveCard() error = %v, want %v", err, step.wantErr)
+			}
+			for status, want := range step.want {
+				var got []string
+				err := db.Model(&models.Todo{}).Where("status = ? AND project_id IS NULL", status).
+					Order(boardOrder).Pluck("title", &got).Error
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("column %q = %v, want %v", status, got, want)
+				}
+			}
+		})
+	}
+
+	// Only the cards of the board got a rank
+	for _, todo := range []*models.Todo{trashed, trashedDoing, other} {
+		var boardPosition string
+		if err := db.Unscoped().Model(&models.Todo{}).Where("id = ?", todo.ID).Pluck("board_position", &boardPosition).Error; err != nil {
+			t.Fatal(err)
+		}
+		if boardPosition != "" {
+			t.Errorf("todo %q board position = %q, want none", todo.Title, boardPosition)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+
+	"gorm.io/gorm"
+)
+
+// bulkApply applies a bulk operation to one todo.
+type bulkApply func(tx *gorm.DB, todo *models.Todo) error
+
+// bulkRoles is the role each bulk operation needs on a todo, the same as its single-todo
+// endpoint: editors change todos, only owners trash, restore or delete them.
+var bulkRoles = map[string]enums.ShareRole{
+	"update_status": enums.ShareRoleEditor,
+	"add_tag":       enums.ShareRoleEditor,
+	"move_project":  enums.ShareRoleEditor,
+	"trash":         enums.ShareRoleOwner,
+	"restore":       enums.ShareRoleOwner,
+	"delete":        enums.ShareRoleOwner,
+}
+
+// BulkUpdateTodos applies a list of operations to many todos in a single transaction.
+// Every todo of every operation runs in its own savepoint: a failing item is rolled back
+// alone and reported in its result while the others go through.
+func BulkUpdateTodos(bulkDTO *dto.BulkTodosDTO, authorID string) ([]*dto.BulkResultDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	var results []*dto.BulkResultDTO
+	var blobs []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		blobs = nil
+		results = applyBulkOperations(tx, bulkDTO.Operations, authorIDUint, &blobs)
+		return nil
+	})
+	if err != nil {
+		return nil, dto.ErrToDoUpdate
+	}
+	removeBlobs(blobs)
+
+	return results, nil
+}
+
+// applyBulkOperations applies the operations in a transaction, each todo in a savepoint,
+// and returns the result of every todo of every operation.
+func applyBulkOperations(tx *gorm.DB, operations []dto.BulkOperationDTO, authorID uint, blobs *[]string) []*dto.BulkResultDTO {
+	var results []*dto.BulkResultDTO
+	for i, operation := range operations {
+		apply, opErr := bulkOperation(&operation, authorID, blobs)
+		for _, todoID := range uniqueIDs(operation.TodoIDs) {
+			itemErr := opErr
+			if itemErr == nil {
+				itemErr = tx.Transaction(func(tx *gorm.DB) error {
+					// Trashed todos are found too, for restore and delete
+					todo, err := findTodo(tx.Unscoped(), todoID, authorID, bulkRoles[operation.Op])
+					if err != nil {
+						return err
+					}
+					return apply(tx, todo)
+				})
+			}
+
+			result := &dto.BulkResultDTO{Operation: i, Op: operation.Op, TodoID: todoID, Success: itemErr == nil}
+			if itemErr != nil {
+				result.Error = itemErr.Error()
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// bulkOperation returns how to apply an operation to a todo. Projects and tags are looked
+// up among the ones of the author of each todo, like UpdateTodo does. Deletions add the
+// storage keys of the attached files to blobs, to remove once committed.
+func bulkOperation(operation *dto.BulkOperationDTO, authorID uint, blobs *[]string) (bulkApply, error) {
+	switch operation.Op {
+	case "update_status":
+		status, completeSubtasks, force := *operation.Status, operation.CompleteSubtasks, operation.Force
+		return func(tx *gorm.DB, todo *models.Todo) error {
+			return trackTodoUpdate(tx, todo, &authorID, func() error {
+				return bulkSetStatus(tx, todo, status, completeSubtasks, force)
+			})
+		}, nil
+
+	case "trash":
+		return func(tx *gorm.DB, todo *models.Todo) error {
+			if todo.DeletedAt.Valid {
+				return dto.ErrToDoNotFound
+			}
+			if err := tx.Delete(todo).Error; err != nil {
+				return err
+			}
+			return recordActivity(tx, todo.ID, &authorID, enums.TodoActivityTrashed, nil)
+		}, nil
+
+	case "restore":
+		return func(tx *gorm.DB, todo *models.Todo) error {
+			return restoreTodos(tx, []models.Todo{*todo}, &authorID)
+		}, nil
+
+	case "delete":
+		return func(tx *gorm.DB, todo *models.Todo) error {
+			keys, err := hardDeleteTodos(tx, []models.Todo{*todo}, &authorID)
+			if err != nil {
+				return err
+			}
+			*blobs = append(*blobs, keys...)
+			return nil
+		}, nil
+
+	case "move_project":
+		projectID := *operation.ProjectID
+		return func(tx *gorm.DB, todo *models.Todo) error {
+			if todo.DeletedAt.Valid {
+				return dto.ErrToDoNotFound
+			}
+			// Make sure the project belongs to the author of the todo and is not archived
+			var newProjectID *uint
+			if projectID != 0 {
+				project, err := findActiveProject(tx, projectID, todo.AuthorID)
+				if err != nil {
+					return err
+				}
+				newProjectID = &project.ID
+			}
+			// The todo keeps its status if the workflow of the project has it
+			workflow, err := workflowOf(tx, todo.AuthorID, newProjectID)
+			if err != nil {
+				return err
+			}
+			status := fitStatus(workflow, todo.Status, todo.StatusCategory)
+			boardPosition := todo.BoardPosition
+			if status.Name != todo.Status {
+				boardPosition = ""
+			}
+			return trackTodoUpdate(tx, todo, &authorID, func() error {
+				return tx.Model(&models.Todo{}).Where("id = ?", todo.ID).Updates(map[string]any{
+					"project_id":      newProjectID,
+					"status":          status.Name,
+					"status_category": status.Category,
+					"board_position":  boardPosition,
+				}).Error
+			})
+		}, nil
+
+	case "add_tag":
+		tagID := *operation.TagID
+		return func(tx *gorm.DB, todo *models.Todo) error {
+			if todo.DeletedAt.Valid {
+				return dto.ErrToDoNotFound
+			}
+			// Make sure the tag belongs to the author of the todo
+			tags, err := findUserTags(tx, []uint{tagID}, todo.AuthorID)
+			if err != nil {
+				return err
+			}
+			return trackTodoUpdate(tx, todo, &authorID, func() error {
+				return tx.Model(todo).Association("Tags").Append(tags)
+			})
+		}, nil
+	}
+	return nil, dto.ErrInvalidReqPayload
+}
+
+// bulkSetStatus changes the status of a todo with the same rules as UpdateTodo: completing
+// it can check its open subtasks with completeSubtasks, or skip its blockers with force.
+func bulkSetStatus(tx *gorm.DB, todo *models.Todo, name enums.TodoStatus, completeSubtasks bool, force bool) error {
+	if todo.DeletedAt.Valid {
+		return dto.ErrToDoNotFound
+	}
+	workflow, err := todoWorkflow(tx, todo)
+	if err != nil {
+		return err
+	}
+	status, err := checkStatusChange(workflow, todo.Status, name)
+	if err != nil {
+		return err
+	}
+	if !force {
+		if err := checkBlockers(todo, status); err != nil {
+			return err
+		}
+	}
+	if status.Category == enums.StatusCategoryDone && completeSubtasks {
+		if err := tx.Model(&models.Subtask{}).Where("todo_id = ? AND done = ?", todo.ID, false).Update("done", true).Error; err != nil {
+			return err
+		}
+		for i := range todo.Subtasks {
+			todo.Subtasks[i].Done = true
+		}
+	}
+	return setTodoStatus(tx, todo, status)
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestApplyBulkOperations(t *testing.T) {
+	db := newTestDB(t)
+	user := models.User{Username: "ada", Email: "ada@example.com"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	plain := models.Todo{Title: "plain", AuthorID: user.ID}
+	refused := models.Todo{Title: "refused", AuthorID: user.ID, Subtasks: []models.Subtask{{Title: "step"}}}
+	trashed := models.Todo{Title: "trashed", AuthorID: user.ID}
+	for _, todo := range []*models.Todo{&plain, &refused, &trashed} {
+		if err := db.Create(todo).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The history of one todo refuses new entries, so its update fails after its
+	// subtasks and status were written
+	trigger := fmt.Sprintf(`CREATE TRIGGER refuse_activity BEFORE INSERT ON todo_activities
+WHEN NEW.todo_id = %d BEGIN SELECT RAISE(ABORT, 'activity refused'); END`, refused.ID)
+	if err := db.Exec(trigger).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	completed := enums.TodoStatusCompleted
+	operations := []dto.BulkOperationDTO{
+		{Op: "update_status", TodoIDs: []uint{plain.ID, refused.ID, 999, plain.ID}, Status: &completed, CompleteSubtasks: true},
+		{Op: "trash", TodoIDs: []uint{trashed.ID}},
+		{Op: "update_status", TodoIDs: []uint{trashed.ID}, Status: &completed},
+	}
+	var results []*dto.BulkResultDTO
+	var blobs []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		results = applyBulkOperations(tx, operations, user.ID, &blobs)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		operation int
+		todoID    uint
+		success   bool
+	}{
+		{operation: 0, todoID: plain.ID, success: true},
+		{operation: 0, todoID: refused.ID},
+		{operation: 0, todoID: 999},
+		{operation: 1, todoID: trashed.ID, success: true},
+		{operation: 2, todoID: trashed.ID}, // trashed by the operation before
+	}
+	if len(results) != len(want) {
+		t.Fatalf("applyBulkOperations() gave %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		result := results[i]
+		if result.Operation != w.operation || result.TodoID != w.todoID || result.Success != w.success {
+			t.Errorf("result %d = %+v, want operation %d on todo %d, success %v", i, result, w.operation, w.todoID, w.success)
+		}
+		if !w.success && result.Error == "" {
+			t.Errorf("result %d has no error", i)
+		}
+	}
+
+	// The failed item is rolled back alone
+	tests := []struct {
+		name   string
+		todoID uint
+		status enums.TodoStatus
+		done   bool
+	}{
+		{name: "updated", todoID: plain.ID, status: enums.TodoStatusCompleted},
+		{name: "rolled back", todoID: refused.ID, status: enums.TodoStatusPending},
+		{name: "trashed first", todoID: trashed.ID, status: enums.TodoStatusPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var todo models.Todo
+			if err := db.Unscoped().Preload("Subtasks").First(&todo, tt.todoID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if todo.Status != tt.status {
+				t.Errorf("status = %q, want %q", todo.Status, tt.status)
+			}
+			for _, subtask := range todo.Subtasks {
+				if subtask.Done != tt.done {
+					t.Errorf("subtask %q done = %v, want %v", subtask.Title, subtask.Done, tt.done)
+				}
+			}
+		})
+	}
+	var trashedTodo models.Todo
+	if err := db.Unscoped().First(&trashedTodo, trashed.ID).Error; err != nil || !trashedTodo.DeletedAt.Valid {
+		t.Errorf("todo %d is not in the trash, error = %v", trashed.ID, err)
+	}
+}
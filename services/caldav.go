@@ -0,0 +1,429 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// davTodoFields holds what a VTODO sent by a CalDAV client sets on a todo.
+type davTodoFields struct {
+	UID      string
+	Todo     dto.CreateTodoDTO
+	Category enums.StatusCategory // VTODOs only tell the category of the status
+	Tags     []string
+}
+
+// GetDavCollection renders every active todo of the author as a resource of their
+// CalDAV collection. The CTag changes as soon as any of the resources does.
+func GetDavCollection(authorID string) (*dto.DavCollectionDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	if err := ensureTodoUIDs(db, authorIDUint); err != nil {
+		return nil, err
+	}
+
+	var todos []models.Todo
+	err = db.Preload("Tags").Preload("Subtasks").Where("author_id = ?", authorIDUint).Order("dav_name").Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &dto.DavCollectionDTO{Todos: make([]*dto.DavTodoDTO, 0, len(todos))}
+	for i := range todos {
+		collection.Todos = append(collection.Todos, toDavTodo(&todos[i]))
+	}
+	collection.CTag = davCTag(collection.Todos)
+
+	return collection, nil
+}
+
+// davCTag hashes the names and ETags of the resources of a collection, so a resource
+// added, removed, renamed or changed gives another CTag.
+func davCTag(resources []*dto.DavTodoDTO) string {
+	sum := sha256.New()
+	for _, resource := range resources {
+		sum.Write([]byte(resource.Name + " " + resource.ETag + "\n"))
+	}
+	return hex.EncodeToString(sum.Sum(nil)[:16])
+}
+
+// GetDavTodo renders the todo of the author stored under a resource name.
+func GetDavTodo(name string, authorID string) (*dto.DavTodoDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findDavTodo(db, name, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDavTodo(todo), nil
+}
+
+// PutDavTodo stores the VTODO of a CalDAV client under a resource name, creating the
+// todo or replacing the fields of the one already there, and tells which it did.
+// ifMatch and ifNoneMatch are the conditional headers of the request.
+//
+// Titles stay unique: a VTODO whose summary another todo already has is refused with
+// ErrToDoTitleAlreadyExists. The data is stored as todo fields, not as sent.
+func PutDavTodo(name string, calendar []byte, ifMatch string, ifNoneMatch string, authorID string) (bool, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return false, dto.ErrAuthIdConv
+	}
+
+	fields, err := parseDavTodo(calendar)
+	if err != nil {
+		return false, err
+	}
+
+	created := false
+	err = db.Transaction(func(tx *gorm.DB) error {
+		todo, err := findDavTodo(tx, name, authorIDUint)
+		if err != nil && !errors.Is(err, dto.ErrToDoNotFound) {
+			return err
+		}
+		if err := checkDavPreconditions(todo, ifMatch, ifNoneMatch); err != nil {
+			return err
+		}
+		if todo != nil && todo.UID != fields.UID {
+			return dto.ErrDavUIDConflict
+		}
+
+		// A UID is the same todo for the client, it can't live under two names
+		var taken int64
+		err = tx.Model(&models.Todo{}).Where("author_id = ? AND uid = ? AND dav_name <> ?", authorIDUint, fields.UID, name).Count(&taken).Error
+		if err != nil {
+			return err
+		}
+		if taken > 0 {
+			return dto.ErrDavUIDConflict
+		}
+
+		for _, tagName := range fields.Tags {
+			tag, err := importTag(tx, tagName, authorIDUint)
+			if errors.Is(err, dto.ErrImportTagName) {
+				return dto.ErrDavInvalidCalendar
+			}
+			if err != nil {
+				return err
+			}
+			fields.Todo.TagIDs = append(fields.Todo.TagIDs, tag.ID)
+		}
+
+		// A renamed todo would leave the client with a summary the server doesn't have
+		titleQuery := tx.Model(&models.Todo{}).Where("title = ? AND author_id = ?", fields.Todo.Title, authorIDUint)
+		if todo != nil {
+			titleQuery = titleQuery.Where("id <> ?", todo.ID)
+		}
+		var titleTaken int64
+		if err := titleQuery.Count(&titleTaken).Error; err != nil {
+			return err
+		}
+		if titleTaken > 0 {
+			return dto.ErrToDoTitleAlreadyExists
+		}
+
+		if todo == nil {
+			created = true
+			todo, err = createTodo(tx, &fields.Todo, authorIDUint)
+			if err != nil {
+				return err
+			}
+			todo.UID, todo.DavName = fields.UID, name
+			if err := tx.Model(todo).UpdateColumns(map[string]any{"uid": todo.UID, "dav_name": todo.DavName}).Error; err != nil {
+				return dto.ErrToDoCreate
+			}
+		}
+
+		return trackTodoUpdate(tx, todo, &authorIDUint, func() error {
+			if !created {
+				if err := overwriteDavTodo(tx, todo, &fields.Todo); err != nil {
+					return err
+				}
+			}
+			return setTodoCategory(tx, todo, fields.Category)
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
+
+// DeleteDavTodo moves the todo stored under a resource name to the trash.
+func DeleteDavTodo(name string, ifMatch string, authorID string) error {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	todo, err := findDavTodo(db, name, authorIDUint)
+	if err != nil {
+		return err
+	}
+	if err := checkDavPreconditions(todo, ifMatch, ""); err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(todo).Error; err != nil {
+			return err
+		}
+		return recordActivity(tx, todo.ID, &authorIDUint, enums.TodoActivityTrashed, nil)
+	})
+	if err != nil {
+		return dto.ErrToDoTrash
+	}
+	return nil
+}
+
+// findDavTodo loads the active todo of the author stored under a resource name.
+func findDavTodo(tx *gorm.DB, name string, authorID uint) (*models.Todo, error) {
+	var todo models.Todo
+	err := tx.Preload("Tags").Preload("Subtasks").Where("author_id = ? AND dav_name = ?", authorID, name).First(&todo).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrToDoNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
+
+// checkDavPreconditions checks the If-Match and If-None-Match headers of a request
+// against the current ETag of the resource, todo being nil when there is none yet.
+func checkDavPreconditions(todo *models.Todo, ifMatch string, ifNoneMatch string) error {
+	if todo == nil {
+		if ifMatch != "" {
+			return dto.ErrDavPrecondition
+		}
+		return nil
+	}
+
+	etag := toDavTodo(todo).ETag
+	if ifMatch != "" && !utils.ETagMatches(ifMatch, etag) {
+		return dto.ErrDavPrecondition
+	}
+	if ifNoneMatch != "" && utils.ETagMatches(ifNoneMatch, etag) {
+		return dto.ErrDavPrecondition
+	}
+	return nil
+}
+
+// ensureTodoUIDs gives a UID and a resource name to the todos of the author created
+// before they were synced over CalDAV. It doesn't touch their update dates.
+func ensureTodoUIDs(tx *gorm.DB, authorID uint) error {
+	var todos []models.Todo
+	if err := tx.Unscoped().Select("id").Where("author_id = ? AND (uid IS NULL OR uid = '')", authorID).Find(&todos).Error; err != nil {
+		return err
+	}
+	for _, todo := range todos {
+		uid, err := models.NewTodoUID()
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&todo).UpdateColumns(map[string]any{"uid": uid, "dav_name": uid + ".ics"}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// overwriteDavTodo replaces the fields of a todo with the ones of a VTODO. The project
+// has no VTODO property, so it stays.
+func overwriteDavTodo(tx *gorm.DB, todo *models.Todo, todoDTO *dto.CreateTodoDTO) error {
+	tags, err := findUserTags(tx, todoDTO.TagIDs, todo.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	renamed := todo.Title != todoDTO.Title
+	todo.Title = todoDTO.Title
+	todo.Description = todoDTO.Description
+	todo.Priority = todoDTO.Priority
+	todo.StartAt = todoDTO.StartAt
+	todo.DueAt = todoDTO.DueAt
+
+	if err := tx.Omit(clause.Associations).Save(todo).Error; err != nil {
+		return dto.ErrToDoUpdate
+	}
+	if renamed {
+		if err := renameSeries(tx, todo); err != nil {
+			return dto.ErrToDoUpdate
+		}
+	}
+	if err := tx.Model(todo).Association("Tags").Replace(tags); err != nil {
+		return dto.ErrToDoUpdate
+	}
+	return nil
+}
+
+// toDavTodo renders a todo as a calendar object resource. Its ETag is a hash of the
+// data, so it changes with anything the client can see, like the progress of subtasks.
+func toDavTodo(todo *models.Todo) *dto.DavTodoDTO {
+	w := &utils.ICalWriter{}
+	w.Line("BEGIN", "VCALENDAR")
+	w.Line("VERSION", "2.0")
+	w.Line("PRODID", feedProductID)
+	writeVTodo(w, todo, todo.UID)
+	w.Line("END", "VCALENDAR")
+
+	calendar := w.Bytes()
+	sum := sha256.Sum256(calendar)
+	return &dto.DavTodoDTO{
+		Name:         todo.DavName,
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: todo.UpdatedAt,
+		Calendar:     calendar,
+	}
+}
+
+// parseDavTodo reads the VTODO of a calendar object resource sent by a client.
+// Overridden occurrences of a recurring VTODO are left out, only the master is read.
+func parseDavTodo(calendar []byte) (*davTodoFields, error) {
+	root, err := utils.ParseICal(calendar)
+	if err != nil || root.Name != "VCALENDAR" {
+		return nil, dto.ErrDavInvalidCalendar
+	}
+
+	var vtodo *utils.ICalComponent
+	for _, component := range root.Components {
+		switch component.Name {
+		case "VTIMEZONE":
+		case "VTODO":
+			if component.Property("RECURRENCE-ID") != nil {
+				continue
+			}
+			if vtodo != nil {
+				return nil, dto.ErrDavInvalidCalendar
+			}
+			vtodo = component
+		default:
+			// A resource holds a single kind of component, the collection only takes VTODOs
+			return nil, dto.ErrDavInvalidCalendar
+		}
+	}
+	if vtodo == nil {
+		return nil, dto.ErrDavInvalidCalendar
+	}
+
+	fields := &davTodoFields{Category: enums.StatusCategoryTodo}
+	if uid := vtodo.Property("UID"); uid != nil {
+		fields.UID = strings.TrimSpace(uid.Value)
+	}
+	if summary := vtodo.Property("SUMMARY"); summary != nil {
+		fields.Todo.Title = strings.TrimSpace(summary.Text())
+	}
+	if fields.UID == "" || fields.Todo.Title == "" {
+		return nil, dto.ErrDavInvalidCalendar
+	}
+	if description := vtodo.Property("DESCRIPTION"); description != nil {
+		fields.Todo.Description = description.Text()
+	}
+
+	if status := vtodo.Property("STATUS"); status != nil {
+		switch strings.ToUpper(strings.TrimSpace(status.Value)) {
+		case "COMPLETED":
+			fields.Category = enums.StatusCategoryDone
+		case "IN-PROCESS":
+			fields.Category = enums.StatusCategoryDoing
+		}
+	} else if vtodo.Property("COMPLETED") != nil {
+		fields.Category = enums.StatusCategoryDone
+	}
+
+	fields.Todo.Priority = enums.TodoPriorityNone
+	if priority := vtodo.Property("PRIORITY"); priority != nil {
+		level, err := strconv.Atoi(strings.TrimSpace(priority.Value))
+		if err != nil || level < 0 || level > 9 {
+			return nil, dto.ErrDavInvalidCalendar
+		}
+		fields.Todo.Priority = davPriority(level)
+	}
+
+	if start := vtodo.Property("DTSTART"); start != nil {
+		startAt, err := start.Time()
+		if err != nil {
+			return nil, dto.ErrDavInvalidCalendar
+		}
+		fields.Todo.StartAt = &startAt
+	}
+	if due := vtodo.Property("DUE"); due != nil {
+		dueAt, err := due.Time()
+		if err != nil {
+			return nil, dto.ErrDavInvalidCalendar
+		}
+		fields.Todo.DueAt = &dueAt
+	} else if duration := vtodo.Property("DURATION"); duration != nil && fields.Todo.StartAt != nil {
+		length, err := duration.Duration()
+		if err != nil {
+			return nil, dto.ErrDavInvalidCalendar
+		}
+		dueAt := fields.Todo.StartAt.Add(length)
+		fields.Todo.DueAt = &dueAt
+	}
+	if err := checkTodoDates(fields.Todo.StartAt, fields.Todo.DueAt); err != nil {
+		return nil, dto.ErrDavInvalidCalendar
+	}
+
+	seen := map[string]bool{}
+	for _, categories := range vtodo.PropertiesNamed("CATEGORIES") {
+		for _, name := range categories.TextList() {
+			name = strings.TrimSpace(name)
+			if name != "" && !seen[name] {
+				seen[name] = true
+				fields.Tags = append(fields.Tags, name)
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+// davPriority maps a VTODO priority, where 1 is the highest and 0 is undefined, to
+// a priority. The priorities written by the feed map back to themselves.
+func davPriority(level int) enums.TodoPriority {
+	switch {
+	case level == 0:
+		return enums.TodoPriorityNone
+	case level <= 2:
+		return enums.TodoPriorityUrgent
+	case level <= 4:
+		return enums.TodoPriorityHigh
+	case level == 5:
+		return enums.TodoPriorityMedium
+	default:
+		return enums.TodoPriorityLow
+	}
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// davTime parses a time of the expected fields of a test.
+func davTime(t *testing.T, value string) *time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &parsed
+}
+
+// readDavFixture reads a request in the format of a CalDAV client, or the answer expected
+// to it. The requests are written by hand after what each client sends, not captured.
+func readDavFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "caldav", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// davFixtureTodo stores the fields of a VTODO on a todo, like PutDavTodo does.
+func davFixtureTodo(fields *davTodoFields) *models.Todo {
+	todo := &models.Todo{
+		Title:          fields.Todo.Title,
+		Description:    fields.Todo.Description,
+		StatusCategory: fields.Category,
+		Priority:       fields.Todo.Priority,
+		StartAt:        fields.Todo.StartAt,
+		DueAt:          fields.Todo.DueAt,
+		UID:            fields.UID,
+		DavName:        fields.UID + ".ics",
+		CreatedAt:      time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC),
+		UpdatedAt:      time.Date(2024, 6, 3, 7, 15, 0, 0, time.UTC),
+	}
+	for _, name := range fields.Tags {
+		todo.Tags = append(todo.Tags, models.Tag{Name: name})
+	}
+	return todo
+}
+
+// sameDavFields compares the fields read from two VTODOs, dates by the instant they name.
+func sameDavFields(got *davTodoFields, want *davTodoFields) bool {
+	sameTime := func(a *time.Time, b *time.Time) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && a.Equal(*b))
+	}
+	return got.UID == want.UID &&
+		got.Todo.Title == want.Todo.Title &&
+		got.Todo.Description == want.Todo.Description &&
+		got.Todo.Priority == want.Todo.Priority &&
+		sameTime(got.Todo.StartAt, want.Todo.StartAt) &&
+		sameTime(got.Todo.DueAt, want.Todo.DueAt) &&
+		got.Category == want.Category &&
+		reflect.DeepEqual(got.Tags, want.Tags)
+}
+
+func TestParseDavTodo(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		want    *davTodoFields
+		wantErr error
+	}{
+		{
+			name:    "apple reminders completed with a due date",
+			fixture: "put-apple-reminders.ics",
+			want: &davTodoFields{
+				UID: "7A3C1D2E-4B5F-4E6A-9C8D-1F2E3A4B5C6D",
+				Todo: dto.CreateTodoDTO{
+					Title:    "Renew passport",
+					Priority: enums.TodoPriorityUrgent,
+					DueAt:    davTime(t, "2024-06-14T00:00:00Z"),
+				},
+				Category: enums.StatusCategoryDone,
+			},
+		},
+		{
+			name:    "thunderbird in process with a timezone",
+			fixture: "put-thunderbird.ics",
+			want: &davTodoFields{
+				UID: "5e0f9a43-8c1b-4f3e-a7d2-96b1c4e8f021",
+				Todo: dto.CreateTodoDTO{
+					Title:       "Prepare quarterly report",
+					Description: "Numbers from the sales, ops and support teams.\nSend to Maria by Friday.",
+					Priority:    enums.TodoPriorityHigh,
+					StartAt:     davTime(t, "2024-06-03T09:00:00+02:00"),
+					DueAt:       davTime(t, "2024-06-07T17:00:00+02:00"),
+				},
+				Category: enums.StatusCategoryDoing,
+				Tags:     []string{"Work", "Finance"},
+			},
+		},
+		{
+			name:    "tasks.org through DAVx5 with repeated categories",
+			fixture: "put-davx5-tasks-org.ics",
+			want: &davTodoFields{
+				UID: "3962837812340912837",
+				Todo: dto.CreateTodoDTO{
+					Title:    "Buy milk, eggs & bread",
+					Priority: enums.TodoPriorityLow,
+					StartAt:  davTime(t, "2024-06-06T00:00:00Z"),
+					DueAt:    davTime(t, "2024-06-06T00:00:00Z"),
+				},
+				Category: enums.StatusCategoryTodo,
+				Tags:     []string{"Errands", "Home"},
+			},
+		},
+		{
+			name:    "evolution with a duration",
+			fixture: "put-evolution.ics",
+			want: &davTodoFields{
+				UID: "20240607T120000Z-1234-1000-1-0@laptop",
+				Todo: dto.CreateTodoDTO{
+					Title:    "Water the plants",
+					Priority: enums.TodoPriorityNone,
+					StartAt:  davTime(t, "2024-06-08T07:00:00Z"),
+					DueAt:    davTime(t, "2024-06-08T07:30:00Z"),
+				},
+				Category: enums.StatusCategoryTodo,
+			},
+		},
+		{
+			name:    "nextcloud recurring with an overridden occurrence",
+			fixture: "put-nextcloud-recurring.ics",
+			want: &davTodoFields{
+				UID: "b1c5f3e2-0d9a-4a7e-8f61-2c4d7e9a1b30",
+				Todo: dto.CreateTodoDTO{
+					Title:    "Weekly review",
+					Priority: enums.TodoPriorityNone,
+					DueAt:    davTime(t, "2024-06-07T16:00:00Z"),
+				},
+				Category: enums.StatusCategoryDone,
+			},
+		},
+		{name: "event instead of a todo", fixture: "put-thunderbird-event.ics", wantErr: dto.ErrDavInvalidCalendar},
+		{name: "todo without summary", fixture: "put-no-summary.ics", wantErr: dto.ErrDavInvalidCalendar},
+		{name: "due before the start", fixture: "put-due-before-start.ics", wantErr: dto.ErrDavInvalidCalendar},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDavTodo(readDavFixture(t, tt.fixture))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("parseDavTodo() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDavTodo() error = %v", err)
+			}
+			if !sameDavFields(got, tt.want) {
+				t.Errorf("parseDavTodo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDavTodoRoundTrip(t *testing.T) {
+	fixtures := []string{
+		"put-apple-reminders.ics",
+		"put-thunderbird.ics",
+		"put-davx5-tasks-org.ics",
+		"put-evolution.ics",
+		"put-nextcloud-recurring.ics",
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture, func(t *testing.T) {
+			sent, err := parseDavTodo(readDavFixture(t, fixture))
+			if err != nil {
+				t.Fatalf("parseDavTodo() error = %v", err)
+			}
+
+			// What the client reads back must set the todo to what it wrote
+			resource := toDavTodo(davFixtureTodo(sent))
+			read, err := parseDavTodo(resource.Calendar)
+			if err != nil {
+				t.Fatalf("parseDavTodo() of the rendered todo error = %v\n%s", err, resource.Calendar)
+			}
+			if !sameDavFields(read, sent) {
+				t.Errorf("round trip = %+v, want %+v\n%s", read, sent, resource.Calendar)
+			}
+			if resource.Name != sent.UID+".ics" {
+				t.Errorf("Name = %q, want %q", resource.Name, sent.UID+".ics")
+			}
+		})
+	}
+}
+
+func TestToDavTodoGet(t *testing.T) {
+	fields, err := parseDavTodo(readDavFixture(t, "put-thunderbird.ics"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	todo := davFixtureTodo(fields)
+	todo.Subtasks = []models.Subtask{{Title: "Sales", Done: true}, {Title: "Ops"}, {Title: "Support"}}
+
+	resource := toDavTodo(todo)
+	want := readDavFixture(t, "get-thunderbird.ics")
+	if string(resource.Calendar) != string(want) {
+		t.Errorf("Calendar =\n%s\nwant\n%s", resource.Calendar, want)
+	}
+	if !resource.LastModified.Equal(todo.UpdatedAt) {
+		t.Errorf("LastModified = %v, want %v", resource.LastModified, todo.UpdatedAt)
+	}
+}
+
+func TestToDavTodoETag(t *testing.T) {
+	fields, err := parseDavTodo(readDavFixture(t, "put-thunderbird.ics"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := func() *models.Todo {
+		todo := davFixtureTodo(fields)
+		todo.Subtasks = []models.Subtask{{Title: "Sales", Done: true}, {Title: "Ops"}}
+		return todo
+	}
+	etag := toDavTodo(base()).ETag
+	if len(etag) < 3 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		t.Fatalf("ETag = %s, want a quoted strong ETag", etag)
+	}
+
+	tests := []struct {
+		name    string
+		change  func(todo *models.Todo)
+		changed bool
+	}{
+		{name: "same todo", change: func(todo *models.Todo) {}, changed: false},
+		{name: "subtask title", change: func(todo *models.Todo) { todo.Subtasks[1].Title = "Operations" }, changed: false},
+		{name: "subtask done", change: func(todo *models.Todo) { todo.Subtasks[1].Done = true }, changed: true},
+		{name: "subtask added", change: func(todo *models.Todo) { todo.Subtasks = append(todo.Subtasks, models.Subtask{Title: "Support"}) }, changed: true},
+		{name: "title", change: func(todo *models.Todo) { todo.Title = "Prepare yearly report" }, changed: true},
+		{name: "status", change: func(todo *models.Todo) { todo.StatusCategory = enums.StatusCategoryDone }, changed: true},
+		{name: "tag", change: func(todo *models.Todo) { todo.Tags = todo.Tags[:1] }, changed: true},
+		{name: "update date", change: func(todo *models.Todo) { todo.UpdatedAt = todo.UpdatedAt.Add(time.Second) }, changed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := base()
+			tt.change(todo)
+			got := toDavTodo(todo).ETag
+			if (got != etag) != tt.changed {
+				t.Errorf("ETag = %s, base %s, want changed = %v", got, etag, tt.changed)
+			}
+		})
+	}
+}
+
+func TestDavCTag(t *testing.T) {
+	resources := func() []*dto.DavTodoDTO {
+		return []*dto.DavTodoDTO{
+			{Name: "a.ics", ETag: `"1f0c"`},
+			{Name: "b.ics", ETag: `"9e2d"`},
+		}
+	}
+	ctag := davCTag(resources())
+
+	tests := []struct {
+		name      string
+		resources []*dto.DavTodoDTO
+		changed   bool
+	}{
+		{name: "same resources", resources: resources(), changed: false},
+		{name: "resource changed", resources: []*dto.DavTodoDTO{{Name: "a.ics", ETag: `"1f0c"`}, {Name: "b.ics", ETag: `"77aa"`}}, changed: true},
+		{name: "resource renamed", resources: []*dto.DavTodoDTO{{Name: "a.ics", ETag: `"1f0c"`}, {Name: "c.ics", ETag: `"9e2d"`}}, changed: true},
+		{name: "resource removed", resources: resources()[:1], changed: true},
+		{name: "resource added", resources: append(resources(), &dto.DavTodoDTO{Name: "c.ics", ETag: `"3b4c"`}), changed: true},
+		{name: "empty collection", resources: nil, changed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := davCTag(tt.resources)
+			if (got != ctag) != tt.changed {
+				t.Errorf("davCTag() = %s, base %s, want changed = %v", got, ctag, tt.changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"errors"
+	"go-feToDo/config"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetComments reads one page of the thread of a todo the user can see, oldest first.
+// Deleted comments stay in the thread as tombstones.
+func GetComments(todoID string, query *dto.CommentQueryDTO, authorID string) (*dto.CommentPageDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := db.Preload("Author").Where("todo_id = ?", todo.ID)
+	if query.Cursor != "" {
+		cursor, err := decodeIDCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Where("id > ?", cursor.ID)
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	// One more comment tells whether there is a next page
+	var comments []models.Comment
+	if err := tx.Order("id").Limit(limit + 1).Find(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	page := &dto.CommentPageDTO{Comments: make([]*dto.CommentResponseDTO, 0, len(comments))}
+	if len(comments) > limit {
+		comments = comments[:limit]
+		next := encodeIDCursor(comments[limit-1].ID)
+		page.NextCursor = &next
+	}
+
+	for i := range comments {
+		page.Comments = append(page.Comments, utils.ToCommentResponseDTO(&comments[i]))
+	}
+
+	return page, nil
+}
+
+// CreateComment posts a comment on a todo the user can see, possibly as a reply to
+// another comment of its thread.
+func CreateComment(todoID string, commentDTO *dto.CreateCommentDTO, authorID string) (*dto.CommentResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if commentDTO.ParentID != nil {
+		parent, err := findComment(db, todo.ID, *commentDTO.ParentID)
+		if errors.Is(err, dto.ErrCommentNotFound) {
+			return nil, dto.ErrCommentParent
+		}
+		if err != nil {
+			return nil, err
+		}
+		if parent.DeletedAt != nil {
+			return nil, dto.ErrCommentDeleted
+		}
+	}
+
+	comment := models.Comment{
+		TodoID:   todo.ID,
+		AuthorID: authorIDUint,
+		ParentID: commentDTO.ParentID,
+		Body:     commentDTO.Body,
+	}
+	if err := db.Create(&comment).Error; err != nil {
+		return nil, dto.ErrCommentCreate
+	}
+
+	return reloadComment(db, comment.ID)
+}
+
+// UpdateComment edits the body of a comment. Only its author can, for the edit window
+// after posting it.
+func UpdateComment(todoID string, commentID string, updateDTO *dto.UpdateCommentDTO, authorID string) (*dto.CommentResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	commentIDUint, err := utils.ConvId(commentID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := findComment(db, todo.ID, commentIDUint)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.AuthorID != authorIDUint {
+		return nil, dto.ErrUnauthComment
+	}
+	if comment.DeletedAt != nil {
+		return nil, dto.ErrCommentDeleted
+	}
+	window := time.Duration(config.LoadConfig().CommentEditWindow) * time.Second
+	now := time.Now()
+	if window > 0 && now.Sub(comment.CreatedAt) > window {
+		return nil, dto.ErrCommentEditWindow
+	}
+
+	if comment.Body != updateDTO.Body {
+		err := db.Model(comment).Updates(map[string]interface{}{"body": updateDTO.Body, "edited_at": now}).Error
+		if err != nil {
+			return nil, dto.ErrCommentUpdate
+		}
+	}
+
+	return reloadComment(db, comment.ID)
+}
+
+// DeleteComment turns a comment into a tombstone. Its author and the owners of the
+// todo can delete it.
+func DeleteComment(todoID string, commentID string, authorID string) error {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	commentIDUint, err := utils.ConvId(commentID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleViewer)
+	if err != nil {
+		return err
+	}
+	comment, err := findComment(db, todo.ID, commentIDUint)
+	if err != nil {
+		return err
+	}
+
+	if comment.AuthorID != authorIDUint {
+		if err := authorizeTodo(db, todo, authorIDUint, enums.ShareRoleOwner); err != nil {
+			return dto.ErrUnauthComment
+		}
+	}
+	if comment.DeletedAt != nil {
+		return nil
+	}
+
+	err = db.Model(comment).Updates(map[string]interface{}{"body": "", "deleted_at": time.Now()}).Error
+	if err != nil {
+		return dto.ErrCommentDelete
+	}
+	return nil
+}
+
+// findComment loads a comment of the thread of a todo.
+func findComment(db *gorm.DB, todoID uint, commentID uint) (*models.Comment, error) {
+	var comment models.Comment
+	err := db.Where("id = ? AND todo_id = ?", commentID, todoID).First(&comment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// reloadComment reads a comment back with its author for the response.
+func reloadComment(db *gorm.DB, commentID uint) (*dto.CommentResponseDTO, error) {
+	var comment models.Comment
+	if err := db.Preload("Author").First(&comment, commentID).Error; err != nil {
+		return nil, err
+	}
+	return utils.ToCommentResponseDTO(&comment), nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"errors"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// todoDependency is a row of the todo_dependencies table: a todo waiting for a blocker.
+type todoDependency struct {
+	TodoID    uint
+	BlockerID uint
+}
+
+// AddDependency makes a todo the user can edit wait for another todo of the same author.
+// A dependency making a todo wait for itself, even through other todos, is refused.
+func AddDependency(todoID string, dependencyDTO *dto.AddDependencyDTO, authorID string) (*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	var blocker models.Todo
+	err = db.Where("id = ?", dependencyDTO.BlockerID).First(&blocker).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrToDoNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// A blocker the user can't see is answered like a missing one, so its ID tells nothing
+	if err := authorizeTodo(db, &blocker, authorIDUint, enums.ShareRoleViewer); err != nil {
+		if errors.Is(err, dto.ErrUnauthToDo) {
+			return nil, dto.ErrToDoNotFound
+		}
+		return nil, err
+	}
+
+	// A todo can only wait for another todo of its author
+	if blocker.AuthorID != todo.AuthorID {
+		return nil, dto.ErrUnauthToDo
+	}
+	if blocker.ID == todo.ID {
+		return nil, dto.ErrDependencyCycle
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// The dependencies of an author are added one at a time, so that two of them
+		// can't make a cycle together
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.User{}, todo.AuthorID).Error; err != nil {
+			return err
+		}
+
+		dependencies, err := authorDependencies(tx, todo.AuthorID)
+		if err != nil {
+			return err
+		}
+		for _, dependency := range dependencies {
+			if dependency.TodoID == todo.ID && dependency.BlockerID == blocker.ID {
+				return dto.ErrDependencyExists
+			}
+		}
+		if waitsFor(dependencies, blocker.ID, todo.ID) {
+			return dto.ErrDependencyCycle
+		}
+
+		return tx.Table("todo_dependencies").Create(map[string]any{"todo_id": todo.ID, "blocker_id": blocker.ID}).Error
+	})
+	if errors.Is(err, dto.ErrDependencyExists) || errors.Is(err, dto.ErrDependencyCycle) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, dto.ErrDependencyCreate
+	}
+
+	return reloadTodo(db, todo.ID)
+}
+
+// RemoveDependency stops a todo the user can edit from waiting for one of its blockers.
+func RemoveDependency(todoID string, blockerID string, authorID string) (*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	blockerIDUint, err := utils.ConvId(blockerID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := db.Table("todo_dependencies").Where("todo_id = ? AND blocker_id = ?", todo.ID, blockerIDUint).Delete(&todoDependency{})
+	if result.Error != nil {
+		return nil, dto.ErrDependencyDelete
+	}
+	if result.RowsAffected == 0 {
+		return nil, dto.ErrDependencyNotFound
+	}
+
+	return reloadTodo(db, todo.ID)
+}
+
+// GetDependencyGraph returns the active todos of a project of the user with their
+// dependencies, each todo after the blockers it has in the project.
+func GetDependencyGraph(projectID string, authorID string) (*dto.DependencyGraphDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	projectIDUint, err := utils.ConvId(projectID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	project, err := findProject(db, projectIDUint, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+
+	var todos []models.Todo
+	if err := db.Scopes(withTodoRelations).Where("project_id = ?", project.ID).Order("position, id").Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	graph := &dto.DependencyGraphDTO{
+		ProjectID: project.ID,
+		Todos:     make([]*dto.TodoResponseDTO, 0, len(todos)),
+		Edges:     []*dto.DependencyEdgeDTO{},
+	}
+	for _, i := range topologicalOrder(todos) {
+		graph.Todos = append(graph.Todos, utils.ToTodoResponseDTO(&todos[i]))
+	}
+	for _, todo := range todos {
+		for _, blocker := range todo.Blockers {
+			graph.Edges = append(graph.Edges, &dto.DependencyEdgeDTO{TodoID: todo.ID, BlockerID: blocker.ID})
+		}
+	}
+
+	return graph, nil
+}
+
+// checkBlockers makes sure a todo can go to a status: it can't be done while one of its
+// blockers is still open.
+func checkBlockers(todo *models.Todo, status *models.WorkflowStatus) error {
+	if status.Category == enums.StatusCategoryDone && !todo.Done() && todo.Blocked() {
+		return dto.ErrToDoBlocked
+	}
+	return nil
+}
+
+// authorDependencies loads the dependencies between the todos of an author, trashed ones included.
+func authorDependencies(tx *gorm.DB, authorID uint) ([]todoDependency, error) {
+	var dependencies []todoDependency
+	err := tx.Table("todo_dependencies").Select("todo_dependencies.todo_id, todo_dependencies.blocker_id").
+		Joins("JOIN todos ON todos.id = todo_dependencies.todo_id").Where("todos.author_id = ?", authorID).
+		Scan(&dependencies).Error
+	return dependencies, err
+}
+
+// waitsFor reports whether a todo waits for another one, directly or through other todos.
+func waitsFor(dependencies []todoDependency, todoID uint, blockerID uint) bool {
+	blockers := map[uint][]uint{}
+	for _, dependency := range dependencies {
+		blockers[dependency.TodoID] = append(blockers[dependency.TodoID], dependency.BlockerID)
+	}
+
+	seen := map[uint]bool{todoID: true}
+	queue := []uint{todoID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, next := range blockers[id] {
+			if next == blockerID {
+				return true
+			}
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// topologicalOrder returns the indexes of todos, loaded with their blockers, ordered so
+// that every todo comes after its blockers among them. Todos without blockers among them
+// keep their order.
+func topologicalOrder(todos []models.Todo) []int {
+	index := make(map[uint]int, len(todos))
+	for i, todo := range todos {
+		index[todo.ID] = i
+	}
+
+	// waiting counts the blockers of each todo not placed yet
+	waiting := make([]int, len(todos))
+	blocks := map[int][]int{}
+	for i, todo := range todos {
+		for _, blocker := range todo.Blockers {
+			if j, ok := index[blocker.ID]; ok {
+				waiting[i]++
+				blocks[j] = append(blocks[j], i)
+			}
+		}
+	}
+
+	order := make([]int, 0, len(todos))
+	for i := range todos {
+		if waiting[i] == 0 {
+			order = append(order, i)
+		}
+	}
+	for k := 0; k < len(order); k++ {
+		for _, i := range blocks[order[k]] {
+			waiting[i]--
+			if waiting[i] == 0 {
+				order = append(order, i)
+			}
+		}
+	}
+	return order
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Due views accepted by the todo list filter
+const (
+	dueToday    = "today"
+	dueOverdue  = "overdue"
+	dueThisWeek = "this_week"
+	dueNoDate   = "no_date"
+)
+
+// filterByDue restricts a todo query to one of the due views. Day and week
+// boundaries are computed in the given location so "today" matches the user's day.
+func filterByDue(view string, loc *time.Location, now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		now = now.In(loc)
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+		switch view {
+		case dueToday:
+			return db.Where("due_at >= ? AND due_at < ?", startOfDay.UTC(), startOfDay.AddDate(0, 0, 1).UTC())
+		case dueOverdue:
+			return db.Where("due_at < ? AND status_category <> ?", now.UTC(), enums.StatusCategoryDone)
+		case dueThisWeek:
+			// Weeks start on Monday (ISO 8601)
+			offset := (int(now.Weekday()) + 6) % 7
+			startOfWeek := startOfDay.AddDate(0, 0, -offset)
+			return db.Where("due_at >= ? AND due_at < ?", startOfWeek.UTC(), startOfWeek.AddDate(0, 0, 7).UTC())
+		case dueNoDate:
+			return db.Where("due_at IS NULL")
+		}
+		return db
+	}
+}
+
+// loadLocation resolves the timezone of a query, defaulting to UTC.
+func loadLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, dto.ErrInvalidReqPayload
+	}
+	return loc, nil
+}
+
+// checkTodoDates ensures a todo is not due before it starts.
+func checkTodoDates(startAt *time.Time, dueAt *time.Time) error {
+	if startAt != nil && dueAt != nil && dueAt.Before(*startAt) {
+		return dto.ErrToDoDueBeforeStart
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"io"
+
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is the number of todos read from the database at once during an export.
+const exportBatchSize = 200
+
+// ExportTodos writes the todos of the author to w in the requested format, trashed todos
+// included if asked. Todos are read batch after batch and written as they come, so an
+// export never holds more than one batch in memory. When w can be flushed it is flushed
+// after every batch.
+func ExportTodos(query *dto.ExportQueryDTO, authorID string, w io.Writer) error {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	// Projects are few, their names are looked up once for every todo
+	var projects []models.Project
+	if err := db.Where("author_id = ?", authorIDUint).Find(&projects).Error; err != nil {
+		return dto.ErrToDoExport
+	}
+	projectNames := make(map[uint]string, len(projects))
+	for _, project := range projects {
+		projectNames[project.ID] = project.Name
+	}
+
+	exporter, err := utils.NewTodoExporter(query.Format, w)
+	if err != nil {
+		return err
+	}
+
+	tx := db.Scopes(withTodoRelations).Where("todos.author_id = ?", authorIDUint)
+	if query.IncludeTrashed {
+		tx = tx.Unscoped()
+	}
+
+	var todos []models.Todo
+	err = tx.FindInBatches(&todos, exportBatchSize, func(batch *gorm.DB, _ int) error {
+		for i := range todos {
+			var project string
+			if todos[i].ProjectID != nil {
+				project = projectNames[*todos[i].ProjectID]
+			}
+			if err := exporter.Write(&todos[i], project); err != nil {
+				return err
+			}
+		}
+		if flusher, ok := w.(interface{ Flush() }); ok {
+			flusher.Flush()
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	return exporter.Close()
+}
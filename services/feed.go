@@ -0,0 +1,308 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// feedProductID identifies the application in the calendars it serves
+	feedProductID = "-//go-feToDo//Todos//EN"
+	// feedUIDDomain ends the UIDs of the feed items
+	feedUIDDomain = "go-fetodo"
+)
+
+// feedStatuses maps the status categories to the VTODO statuses, anything else needs action.
+var feedStatuses = map[enums.StatusCategory]string{
+	enums.StatusCategoryDoing: "IN-PROCESS",
+	enums.StatusCategoryDone:  "COMPLETED",
+}
+
+// feedPriorities maps the priorities to the VTODO priorities, where 1 is the highest.
+var feedPriorities = map[enums.TodoPriority]int{
+	enums.TodoPriorityUrgent: 1,
+	enums.TodoPriorityHigh:   3,
+	enums.TodoPriorityMedium: 5,
+	enums.TodoPriorityLow:    7,
+}
+
+// CreateFeedToken gives the user a new secret token for their calendar feed and returns it.
+// Only its hash is kept, so the token is shown once and the previous one stops working.
+func CreateFeedToken(userID string) (string, error) {
+	db := database.GetDB()
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return "", dto.ErrAuthIdConv
+	}
+
+	token, hash, err := utils.NewSecretToken()
+	if err != nil {
+		return "", dto.ErrFeedTokenCreate
+	}
+
+	result := db.Model(&models.User{}).Where("id = ?", userIDUint).Update("feed_token_hash", hash)
+	if result.Error != nil {
+		return "", dto.ErrFeedTokenCreate
+	}
+	if result.RowsAffected == 0 {
+		return "", dto.ErrUserNotFound
+	}
+
+	return token, nil
+}
+
+// RevokeFeedToken turns the calendar feed of the user off.
+func RevokeFeedToken(userID string) error {
+	db := database.GetDB()
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	if err := db.Model(&models.User{}).Where("id = ?", userIDUint).Update("feed_token_hash", nil).Error; err != nil {
+		return dto.ErrFeedTokenDelete
+	}
+
+	return nil
+}
+
+// GetTodoFeed builds the iCalendar feed of the active todos of the user owning the token.
+// When ifNoneMatch already holds the ETag of the feed, the feed isn't sent again.
+func GetTodoFeed(token string, ifNoneMatch string) (*dto.TodoFeedDTO, error) {
+	db := database.GetDB()
+
+	var user models.User
+	err := db.Where("feed_token_hash = ?", utils.HashSecretToken(token)).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrFeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var todos []models.Todo
+	err = db.Preload("Tags").Preload("Subtasks").Preload("Recurrence.Exceptions").
+		Where("author_id = ?", user.ID).Order("position, id").Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	calendar, err := writeTodoFeed(&user, todos)
+	if err != nil {
+		return nil, err
+	}
+
+	etag := feedETag(calendar)
+	if utils.ETagMatches(ifNoneMatch, etag) {
+		return &dto.TodoFeedDTO{ETag: etag, NotModified: true}, nil
+	}
+	return &dto.TodoFeedDTO{ETag: etag, Calendar: calendar}, nil
+}
+
+// feedETag hashes a feed as written, so any change to what it holds gives another ETag.
+func feedETag(calendar []byte) string {
+	sum := sha256.Sum256(calendar)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// writeTodoFeed writes the VCALENDAR of a user with a VTODO for every todo.
+// The latest occurrence of a series carries the recurrence: its rule, the skipped
+// occurrences and the edited ones. Earlier occurrences are plain todos.
+func writeTodoFeed(user *models.User, todos []models.Todo) ([]byte, error) {
+	// The latest occurrence of every series
+	latest := map[uint]*models.Todo{}
+	for i := range todos {
+		todo := &todos[i]
+		if todo.Recurrence == nil || todo.OccurrenceAt == nil {
+			continue
+		}
+		if current, ok := latest[todo.Recurrence.ID]; !ok || todo.OccurrenceAt.After(*current.OccurrenceAt) {
+			latest[todo.Recurrence.ID] = todo
+		}
+	}
+
+	w := &utils.ICalWriter{}
+	w.Line("BEGIN", "VCALENDAR")
+	w.Line("VERSION", "2.0")
+	w.Line("PRODID", feedProductID)
+	w.Line("CALSCALE", "GREGORIAN")
+	w.Line("METHOD", "PUBLISH")
+	w.Text("X-WR-CALNAME", user.Username+" todos")
+
+	// Series in a local timezone need its definition
+	written := map[string]bool{}
+	for i := range todos {
+		todo := &todos[i]
+		if todo.Recurrence == nil || latest[todo.Recurrence.ID] != todo {
+			continue
+		}
+		loc, err := time.LoadLocation(todo.Recurrence.Timezone)
+		if err != nil {
+			return nil, dto.ErrRecurrenceInvalidRule
+		}
+		if !written[loc.String()] {
+			w.Timezone(loc, todo.Recurrence.Dtstart)
+			written[loc.String()] = true
+		}
+	}
+
+	for i := range todos {
+		todo := &todos[i]
+		if todo.Recurrence != nil && latest[todo.Recurrence.ID] == todo {
+			if err := writeSeriesVTodo(w, todo); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		writeVTodo(w, todo, fmt.Sprintf("todo-%d@%s", todo.ID, feedUIDDomain))
+	}
+
+	w.Line("END", "VCALENDAR")
+	return w.Bytes(), nil
+}
+
+// writeVTodo writes a todo as a single VTODO, without its recurrence.
+func writeVTodo(w *utils.ICalWriter, todo *models.Todo, uid string) {
+	w.Line("BEGIN", "VTODO")
+	w.Line("UID", uid)
+	writeVTodoFields(w, todo)
+	if todo.StartAt != nil {
+		w.Time("DTSTART", *todo.StartAt, nil)
+	}
+	if todo.StartAt != nil && todo.DueAt != nil && todo.DueAt.Equal(*todo.StartAt) {
+		// DUE must be after DTSTART, a todo due when it starts lasts no time
+		w.Line("DURATION", "PT0S")
+	} else if todo.DueAt != nil {
+		w.Time("DUE", *todo.DueAt, nil)
+	}
+	w.Line("END", "VTODO")
+}
+
+// writeSeriesVTodo writes the latest occurrence of a series as a recurring VTODO starting
+// at its due date, followed by a VTODO for every upcoming occurrence that was edited.
+func writeSeriesVTodo(w *utils.ICalWriter, todo *models.Todo) error {
+	series := todo.Recurrence
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		return dto.ErrRecurrenceInvalidRule
+	}
+	rule, err := feedRule(series)
+	if err != nil {
+		return err
+	}
+	uid := fmt.Sprintf("series-%d@%s", series.ID, feedUIDDomain)
+	dueAt := *todo.OccurrenceAt
+	if todo.DueAt != nil {
+		dueAt = *todo.DueAt
+	}
+
+	w.Line("BEGIN", "VTODO")
+	w.Line("UID", uid)
+	writeVTodoFields(w, todo)
+	// Occurrences are generated from DTSTART, it has to follow the rule like the due dates
+	w.Time("DTSTART", dueAt, loc)
+	w.Line("DURATION", "PT0S")
+	w.Line("RRULE", rule)
+
+	var skipped []time.Time
+	for _, exception := range series.Exceptions {
+		if exception.Skipped && exception.OccurrenceAt.After(*todo.OccurrenceAt) {
+			skipped = append(skipped, exception.OccurrenceAt)
+		}
+	}
+	if len(skipped) > 0 {
+		w.Times("EXDATE", skipped, loc)
+	}
+	w.Line("END", "VTODO")
+
+	for _, exception := range series.Exceptions {
+		if exception.Skipped || !exception.OccurrenceAt.After(*todo.OccurrenceAt) {
+			continue
+		}
+		occurrence := &dto.OccurrenceResponseDTO{
+			OccurrenceAt: exception.OccurrenceAt,
+			DueAt:        exception.OccurrenceAt,
+			Title:        todo.Title,
+			Description:  todo.Description,
+		}
+		applyException(occurrence, &exception)
+
+		w.Line("BEGIN", "VTODO")
+		w.Line("UID", uid)
+		w.Time("DTSTAMP", exception.UpdatedAt, nil)
+		w.Time("RECURRENCE-ID", occurrence.OccurrenceAt, loc)
+		w.Text("SUMMARY", occurrence.Title)
+		if occurrence.Description != "" {
+			w.Text("DESCRIPTION", occurrence.Description)
+		}
+		w.Line("STATUS", "NEEDS-ACTION")
+		w.Time("DTSTART", occurrence.DueAt, loc)
+		w.Line("DURATION", "PT0S")
+		w.Line("END", "VTODO")
+	}
+	return nil
+}
+
+// writeVTodoFields writes the properties every VTODO of a todo has.
+func writeVTodoFields(w *utils.ICalWriter, todo *models.Todo) {
+	w.Time("DTSTAMP", todo.UpdatedAt, nil)
+	w.Time("CREATED", todo.CreatedAt, nil)
+	w.Time("LAST-MODIFIED", todo.UpdatedAt, nil)
+	w.Text("SUMMARY", todo.Title)
+	if todo.Description != "" {
+		w.Text("DESCRIPTION", todo.Description)
+	}
+
+	status, ok := feedStatuses[todo.StatusCategory]
+	if !ok {
+		status = "NEEDS-ACTION"
+	}
+	w.Line("STATUS", status)
+	if todo.Done() {
+		// The completion date isn't tracked, the last update is the closest
+		w.Time("COMPLETED", todo.UpdatedAt, nil)
+		w.Line("PERCENT-COMPLETE", "100")
+	} else if progress := utils.ToTodoProgressDTO(todo.Subtasks); progress.Total > 0 {
+		w.Line("PERCENT-COMPLETE", fmt.Sprint(progress.Done*100/progress.Total))
+	}
+
+	if priority, ok := feedPriorities[todo.Priority]; ok {
+		w.Line("PRIORITY", fmt.Sprint(priority))
+	}
+	if len(todo.Tags) > 0 {
+		categories := make([]string, 0, len(todo.Tags))
+		for _, tag := range todo.Tags {
+			categories = append(categories, utils.EscapeICalText(tag.Name))
+		}
+		w.Line("CATEGORIES", strings.Join(categories, ","))
+	}
+}
+
+// feedRule returns the RRULE of a series for a feed. A feed starts the series at its latest
+// occurrence, so a COUNT would run past the end of the series and becomes an UNTIL.
+func feedRule(series *models.Recurrence) (string, error) {
+	rule, err := seriesRule(series)
+	if err != nil {
+		return "", err
+	}
+	options := rule.OrigOptions
+	if options.Count > 0 {
+		dates := rule.All()
+		options.Count = 0
+		if len(dates) > 0 {
+			options.Until = dates[len(dates)-1].UTC()
+		}
+	}
+	return options.RRuleString(), nil
+}
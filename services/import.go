@@ -0,0 +1,336 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxImportRows bounds the number of todos of a single import.
+const maxImportRows = 1000
+
+// errImportDryRun rolls back the transaction of a dry run.
+var errImportDryRun = errors.New("import dry run")
+
+// ImportTodos creates the todos of an import file for the author. Rows are imported
+// in order in a single transaction, each in its own savepoint so a failing row is
+// reported without stopping the others. A dry run goes through the same steps and
+// rolls everything back, so it reports exactly what the import would do.
+//
+// A row whose title is already used by an active todo follows the conflict policy:
+// skip leaves the existing todo alone, rename imports the row under a free title and
+// overwrite replaces the fields of the existing todo. Unknown tags and projects are created.
+func ImportTodos(file io.Reader, query *dto.ImportQueryDTO, authorID string) (*dto.ImportReportDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	rows, err := utils.ParseTodoImport(query.Format, file, maxImportRows)
+	if err != nil {
+		return nil, err
+	}
+
+	onConflict := query.OnConflict
+	if onConflict == "" {
+		onConflict = "skip"
+	}
+
+	var report *dto.ImportReportDTO
+	err = db.Transaction(func(tx *gorm.DB) error {
+		report = &dto.ImportReportDTO{DryRun: query.DryRun, Results: make([]*dto.ImportResultDTO, 0, len(rows))}
+		for i := range rows {
+			row := &rows[i]
+			result := &dto.ImportResultDTO{Row: row.Row, Title: row.Todo.Title}
+
+			rowErr := checkImportRow(row)
+			if rowErr == nil {
+				rowErr = tx.Transaction(func(tx *gorm.DB) error {
+					action, todo, err := importRow(tx, row, onConflict, authorIDUint)
+					if err != nil {
+						return err
+					}
+					result.Action, result.Title = action, todo.Title
+					// IDs of a dry run don't survive the rollback
+					if !query.DryRun || action == "skipped" || action == "overwritten" {
+						result.TodoID = &todo.ID
+					}
+					return nil
+				})
+			}
+			if rowErr != nil {
+				result.Action = "failed"
+				result.Errors = utils.ParseValidationErrors(rowErr)
+			}
+
+			switch result.Action {
+			case "created":
+				report.Created++
+			case "renamed":
+				report.Renamed++
+			case "overwritten":
+				report.Overwritten++
+			case "skipped":
+				report.Skipped++
+			case "failed":
+				report.Failed++
+			}
+			report.Results = append(report.Results, result)
+		}
+
+		if query.DryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		return nil, dto.ErrToDoImport
+	}
+
+	return report, nil
+}
+
+// checkImportRow validates a row like a create payload, before anything is written.
+func checkImportRow(row *utils.ImportRow) error {
+	if row.Err != nil {
+		return row.Err
+	}
+	if err := utils.Validate.Struct(row.Todo); err != nil {
+		return err
+	}
+	// The status is looked up in the workflow of the todo once it is saved
+	if len(row.Status) > 50 {
+		return fmt.Errorf("invalid status %q", row.Status)
+	}
+	return checkTodoDates(row.Todo.StartAt, row.Todo.DueAt)
+}
+
+// importRow saves one row and returns what was done with it and the todo it ended in.
+func importRow(tx *gorm.DB, row *utils.ImportRow, onConflict string, authorID uint) (string, *models.Todo, error) {
+	todoDTO := row.Todo
+	if todoDTO.ProjectID == nil && row.Project != "" {
+		project, err := importProject(tx, row.Project, authorID)
+		if err != nil {
+			return "", nil, err
+		}
+		todoDTO.ProjectID = &project.ID
+	}
+	for _, name := range row.Tags {
+		tag, err := importTag(tx, name, authorID)
+		if err != nil {
+			return "", nil, err
+		}
+		todoDTO.TagIDs = append(todoDTO.TagIDs, tag.ID)
+	}
+
+	// Check if a todo with the same title already exists for the user
+	var existingTodo models.Todo
+	err := tx.Scopes(withTodoRelations).Where("title = ? AND author_id = ?", todoDTO.Title, authorID).First(&existingTodo).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil, err
+	}
+
+	action := "created"
+	if err == nil {
+		switch onConflict {
+		case "skip":
+			return "skipped", &existingTodo, nil
+		case "overwrite":
+			err := trackTodoUpdate(tx, &existingTodo, &authorID, func() error {
+				if err := overwriteTodo(tx, &existingTodo, &todoDTO); err != nil {
+					return err
+				}
+				return setImportStatus(tx, &existingTodo, row.Status)
+			})
+			if err != nil {
+				return "", nil, err
+			}
+			return "overwritten", &existingTodo, nil
+		case "rename":
+			title, err := freeTodoTitle(tx, todoDTO.Title, authorID)
+			if err != nil {
+				return "", nil, err
+			}
+			todoDTO.Title, action = title, "renamed"
+		}
+	}
+
+	todo, err := createTodo(tx, &todoDTO, authorID)
+	if err != nil {
+		return "", nil, err
+	}
+	err = trackTodoUpdate(tx, todo, &authorID, func() error {
+		return setImportStatus(tx, todo, row.Status)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return action, todo, nil
+}
+
+// setImportStatus gives a todo the status of its row, if the row has one. The row names
+// a status of the workflow of the todo, a status of the default workflow or a category.
+// Imported todos don't follow the transitions, they state where the todo already is.
+func setImportStatus(tx *gorm.DB, todo *models.Todo, name enums.TodoStatus) error {
+	if name == "" || name == todo.Status {
+		return nil
+	}
+	workflow, err := todoWorkflow(tx, todo)
+	if err != nil {
+		return err
+	}
+	status, err := aliasedStatus(workflow, name)
+	if err != nil {
+		return err
+	}
+	if status.Name == todo.Status {
+		return nil
+	}
+	return setTodoStatus(tx, todo, status)
+}
+
+// overwriteTodo replaces the fields of an existing todo with an imported row.
+func overwriteTodo(tx *gorm.DB, todo *models.Todo, todoDTO *dto.CreateTodoDTO) error {
+	tags, err := findUserTags(tx, todoDTO.TagIDs, todo.AuthorID)
+	if err != nil {
+		return err
+	}
+	if todoDTO.ProjectID != nil {
+		if _, err := findActiveProject(tx, *todoDTO.ProjectID, todo.AuthorID); err != nil {
+			return err
+		}
+	}
+
+	todo.Description = todoDTO.Description
+	todo.Priority = todoDTO.Priority
+	if todo.Priority == "" {
+		todo.Priority = enums.TodoPriorityNone
+	}
+	todo.ProjectID = todoDTO.ProjectID
+	todo.StartAt = todoDTO.StartAt
+	todo.DueAt = todoDTO.DueAt
+
+	// The todo keeps its status if the workflow of its project has it
+	workflow, err := todoWorkflow(tx, todo)
+	if err != nil {
+		return err
+	}
+	status := fitStatus(workflow, todo.Status, todo.StatusCategory)
+	todo.Status, todo.StatusCategory = status.Name, status.Category
+
+	if err := tx.Omit(clause.Associations).Save(todo).Error; err != nil {
+		return dto.ErrToDoUpdate
+	}
+	if err := tx.Model(todo).Association("Tags").Replace(tags); err != nil {
+		return dto.ErrToDoUpdate
+	}
+	return nil
+}
+
+// availableTodoTitle returns the title if no active todo of the author uses it, or the
+// first numbered one that is free.
+func availableTodoTitle(tx *gorm.DB, title string, authorID uint) (string, error) {
+	var taken int64
+	if err := tx.Model(&models.Todo{}).Where("title = ? AND author_id = ?", title, authorID).Count(&taken).Error; err != nil {
+		return "", err
+	}
+	if taken == 0 {
+		return title, nil
+	}
+	return freeTodoTitle(tx, title, authorID)
+}
+
+// freeTodoTitle returns the first of "title (2)", "title (3)"... no active todo of the author uses.
+// The numbered titles in use are read at once, series of recurring todos number many of them.
+func freeTodoTitle(tx *gorm.DB, title string, authorID uint) (string, error) {
+	prefix := title + " ("
+	var titles []string
+	err := tx.Model(&models.Todo{}).Where("author_id = ? AND SUBSTR(title, 1, ?) = ?", authorID, utf8.RuneCountInString(prefix), prefix).
+		Pluck("title", &titles).Error
+	if err != nil {
+		return "", err
+	}
+	taken := make(map[string]bool, len(titles))
+	for _, title := range titles {
+		taken[title] = true
+	}
+
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", title, n)
+		if !taken[candidate] {
+			return candidate, nil
+		}
+	}
+}
+
+// importProject finds the project of the author with a name, or creates it. A todo.txt
+// +project has its spaces replaced by underscores, so it matches that way too. The name
+// is trimmed and checked like the name of a created project.
+func importProject(tx *gorm.DB, name string, authorID uint) (*models.Project, error) {
+	name = strings.TrimSpace(name)
+	if err := utils.Validate.Struct(dto.CreateProjectDTO{Name: name}); err != nil {
+		return nil, dto.ErrImportProjectName
+	}
+
+	var project models.Project
+	err := tx.Scopes(matchImportName(name, authorID)).First(&project).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		project = models.Project{Name: name, AuthorID: authorID}
+		if err := createProject(tx, &project); err != nil {
+			return nil, dto.ErrProjectCreate
+		}
+		return &project, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if project.ArchivedAt != nil {
+		return nil, dto.ErrProjectArchived
+	}
+	return &project, nil
+}
+
+// importTag finds the tag of the author with a name, or creates it.
+// Like projects, todo.txt @contexts match tags with spaces replaced by underscores, and
+// names are trimmed and checked like the name of a created tag.
+func importTag(tx *gorm.DB, name string, authorID uint) (*models.Tag, error) {
+	name = strings.TrimSpace(name)
+	if err := utils.Validate.Struct(dto.CreateTagDTO{Name: name}); err != nil {
+		return nil, dto.ErrImportTagName
+	}
+
+	var tag models.Tag
+	err := tx.Scopes(matchImportName(name, authorID)).First(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tag = models.Tag{Name: name, AuthorID: authorID}
+		if err := tx.Create(&tag).Error; err != nil {
+			return nil, dto.ErrTagCreate
+		}
+		return &tag, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// matchImportName looks a project or tag of the author up by name, an exact match first.
+func matchImportName(name string, authorID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("author_id = ? AND (name = ? OR REPLACE(name, ' ', '_') = ?)", authorID, name, name).
+			Order(clause.Expr{SQL: "CASE WHEN name = ? THEN 0 ELSE 1 END, id", Vars: []any{name}})
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/models"
+	"strings"
+	"testing"
+)
+
+func TestImportNames(t *testing.T) {
+	db := newTestDB(t)
+	user := models.User{Username: "ada", Email: "ada@example.com"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Projects allow longer names than tags
+	tests := []struct {
+		name           string
+		input          string
+		want           string
+		wantTagErr     bool
+		wantProjectErr bool
+	}{
+		{name: "trimmed", input: "  home ", want: "home"},
+		{name: "blank", input: " ", wantTagErr: true, wantProjectErr: true},
+		{name: "empty", input: "", wantTagErr: true, wantProjectErr: true},
+		{name: "longest tag", input: strings.Repeat("t", 50), want: strings.Repeat("t", 50)},
+		{name: "tag too long", input: strings.Repeat("t", 51), want: strings.Repeat("t", 51), wantTagErr: true},
+		{name: "longest project", input: strings.Repeat("p", 100), want: strings.Repeat("p", 100), wantTagErr: true},
+		{name: "project too long", input: strings.Repeat("p", 101), wantTagErr: true, wantProjectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, err := importTag(db, tt.input, user.ID)
+			if tt.wantTagErr != errors.Is(err, dto.ErrImportTagName) {
+				t.Fatalf("importTag(%q) error = %v", tt.input, err)
+			}
+			if err == nil && tag.Name != tt.want {
+				t.Errorf("importTag(%q) = %q, want %q", tt.input, tag.Name, tt.want)
+			}
+
+			project, err := importProject(db, tt.input, user.ID)
+			if tt.wantProjectErr != errors.Is(err, dto.ErrImportProjectName) {
+				t.Fatalf("importProject(%q) error = %v", tt.input, err)
+			}
+			if err == nil && project.Name != tt.want {
+				t.Errorf("importProject(%q) = %q, want %q", tt.input, project.Name, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+
+	"gorm.io/gorm"
+)
+
+// todoPriorities lists the priorities from the lowest to the highest.
+var todoPriorities = []enums.TodoPriority{
+	enums.TodoPriorityNone,
+	enums.TodoPriorityLow,
+	enums.TodoPriorityMedium,
+	enums.TodoPriorityHigh,
+	enums.TodoPriorityUrgent,
+}
+
+// todoPriorityRank is the SQL expression ranking the priority of a todo like todoPriorities.
+var todoPriorityRank = func() string {
+	expr := "CASE todos.priority"
+	for rank, priority := range todoPriorities {
+		expr += fmt.Sprintf(" WHEN '%s' THEN %d", priority, rank)
+	}
+	return expr + " ELSE 0 END"
+}()
+
+// todoSortKey is one column of the order of a todo list, with the value
+// it had on the last todo of the previous page. A nil value stands for NULL.
+type todoSortKey struct {
+	expr  string
+	desc  bool
+	value any
+}
+
+// MoveTodo places a todo between two neighbours of the author's list. Owners of a
+// shared todo move it in the list of its author.
+// Only the moved todo gets a new position, the rest of the list is left untouched.
+func MoveTodo(todoID string, moveDTO *dto.MoveTodoDTO, authorID string) (*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureTodoPositions(tx, todo.AuthorID); err != nil {
+			return err
+		}
+
+		before, err := findNeighbour(tx, moveDTO.BeforeID, todo)
+		if err != nil {
+			return err
+		}
+		after, err := findNeighbour(tx, moveDTO.AfterID, todo)
+		if err != nil {
+			return err
+		}
+
+		// A single neighbour is completed with the todo next to it in the list
+		if before != nil && after == nil {
+			after, err = adjacentTodo(tx, todo, "position > ?", before.Position, "position, id")
+		} else if after != nil && before == nil {
+			before, err = adjacentTodo(tx, todo, "position < ?", after.Position, "position DESC, id DESC")
+		}
+		if err != nil {
+			return err
+		}
+
+		var lower, upper string
+		if before != nil {
+			lower = before.Position
+		}
+		if after != nil {
+			upper = after.Position
+		}
+		position, err := utils.RankBetween(lower, upper)
+		if err != nil {
+			return dto.ErrToDoMoveNeighbours
+		}
+
+		todo.Position = position
+		return tx.Model(&models.Todo{}).Where("id = ?", todo.ID).Update("position", position).Error
+	})
+	if errors.Is(err, dto.ErrToDoMoveNeighbours) || errors.Is(err, dto.ErrToDoNotFound) || errors.Is(err, dto.ErrUnauthToDo) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, dto.ErrToDoUpdate
+	}
+
+	return reloadTodo(db, todo.ID)
+}
+
+// todoSortKeys returns the order of a todo list for a sort, the manual position by default.
+// Todos without a due date come last when sorting by due date, and ties are broken
+// by the manual position and the ID so the order is stable between pages.
+func todoSortKeys(sort string, order string, cursor *todoCursor) []todoSortKey {
+	desc := order == "desc"
+	var dueAt any
+	noDueDate := 0
+	if cursor.DueAt != nil {
+		dueAt = cursor.DueAt.UTC()
+	} else {
+		noDueDate = 1
+	}
+
+	var keys []todoSortKey
+	switch sort {
+	case "priority":
+		keys = append(keys, todoSortKey{todoPriorityRank, desc, cursor.Priority})
+	case "due":
+		keys = append(keys,
+			todoSortKey{"CASE WHEN todos.due_at IS NULL THEN 1 ELSE 0 END", false, noDueDate},
+			todoSortKey{"todos.due_at", desc, dueAt},
+		)
+	case "created_at":
+		keys = append(keys, todoSortKey{"todos.created_at", desc, cursor.CreatedAt.UTC()})
+	}
+	if sort == "" || sort == "position" {
+		keys = append(keys, todoSortKey{"todos.position", desc, cursor.Position})
+	} else {
+		keys = append(keys, todoSortKey{"todos.position", false, cursor.Position})
+	}
+	return append(keys, todoSortKey{"todos.id", desc, cursor.ID})
+}
+
+// sortTodos orders a todo query by its sort keys.
+func sortTodos(keys []todoSortKey) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, key := range keys {
+			if key.desc {
+				db = db.Order(key.expr + " DESC")
+			} else {
+				db = db.Order(key.expr + " ASC")
+			}
+		}
+		return db
+	}
+}
+
+// nextTodoPosition returns the position at the end of the author's list.
+func nextTodoPosition(db *gorm.DB, authorID uint) (string, error) {
+	var last *string
+	if err := db.Unscoped().Model(&models.Todo{}).Where("author_id = ?", authorID).Select("MAX(position)").Scan(&last).Error; err != nil {
+		return "", err
+	}
+	if last == nil {
+		return utils.RankBetween("", "")
+	}
+	return utils.RankBetween(*last, "")
+}
+
+// ensureTodoPositions gives a position to the todos created before manual ordering existed,
+// appending them in their creation order.
+func ensureTodoPositions(tx *gorm.DB, authorID uint) error {
+	var unranked []models.Todo
+	if err := tx.Unscoped().Where("author_id = ? AND (position IS NULL OR position = '')", authorID).Order("created_at, id").Find(&unranked).Error; err != nil {
+		return err
+	}
+	for _, todo := range unranked {
+		position, err := nextTodoPosition(tx, authorID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Todo{}).Where("id = ?", todo.ID).Update("position", position).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findNeighbour loads the neighbour a todo is moved next to, if one was given.
+func findNeighbour(tx *gorm.DB, neighbourID *uint, todo *models.Todo) (*models.Todo, error) {
+	if neighbourID == nil {
+		return nil, nil
+	}
+	if *neighbourID == todo.ID {
+		return nil, dto.ErrToDoMoveNeighbours
+	}
+	var neighbour models.Todo
+	if err := tx.Where("id = ?", *neighbourID).First(&neighbour).Error; err != nil {
+		return nil, dto.ErrToDoNotFound
+	}
+
+	// Check if the author owns the todo
+	if neighbour.AuthorID != todo.AuthorID {
+		return nil, dto.ErrUnauthToDo
+	}
+
+	return &neighbour, nil
+}
+
+// adjacentTodo finds the closest todo of the list on one side of a position, ignoring the moved todo.
+func adjacentTodo(tx *gorm.DB, todo *models.Todo, condition string, position string, order string) (*models.Todo, error) {
+	var adjacent models.Todo
+	err := tx.Unscoped().Where("author_id = ? AND id <> ?", todo.AuthorID, todo.ID).Where(condition, position).Order(order).Limit(1).Find(&adjacent).Error
+	if err != nil {
+		return nil, err
+	}
+	if adjacent.ID == 0 {
+		return nil, nil
+	}
+	return &adjacent, nil
+}
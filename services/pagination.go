@@ -0,0 +1,244 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultPageSize is the number of todos of a page when no limit is given.
+const defaultPageSize = 50
+
+// idCursor is the last row of a page of a list sorted by id, encoded in the next_cursor
+// of the response.
+type idCursor struct {
+	ID uint `json:"i"`
+}
+
+// todoCursor is the last todo of a page, encoded in the next_cursor of the response.
+// It carries the sort it was built for since its values only make sense with it.
+type todoCursor struct {
+	Sort      string     `json:"s,omitempty"`
+	Order     string     `json:"o,omitempty"`
+	Priority  int        `json:"p,omitempty"`
+	DueAt     *time.Time `json:"d,omitempty"`
+	CreatedAt time.Time  `json:"c"`
+	Position  string     `json:"r,omitempty"`
+	ID        uint       `json:"i"`
+}
+
+// Get All ToDos of a user { trash + active }
+func GetUserAllToDo(authorID string, query *dto.TodoQueryDTO) (*dto.TodoPageDTO, error) {
+	db := database.GetDB()
+	return listTodos(db.Unscoped(), authorID, query)
+}
+
+// Get Active ToDos of a user
+func GetUserActiveToDo(authorID string, query *dto.TodoQueryDTO) (*dto.TodoPageDTO, error) {
+	db := database.GetDB()
+	return listTodos(db, authorID, query)
+}
+
+// GetTrashedTodos retrieves the soft-deleted todos of the author.
+func GetTrashedTodos(authorID string, query *dto.TodoQueryDTO) (*dto.TodoPageDTO, error) {
+	db := database.GetDB()
+	return listTodos(db.Unscoped().Where("todos.deleted_at IS NOT NULL"), authorID, query)
+}
+
+// listTodos reads one page of the todos of the author, filtered and sorted by the query.
+func listTodos(db *gorm.DB, authorID string, query *dto.TodoQueryDTO) (*dto.TodoPageDTO, error) {
+	// Convert authorID from string to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	return pageTodos(db.Where("todos.author_id = ?", authorIDUint), query)
+}
+
+// pageTodos reads one page of the todos of a query, filtered and sorted by the list query.
+// Pages are read with keyset pagination: the cursor is the last todo of the previous
+// page and the next page starts right after it in the sort order.
+func pageTodos(tx *gorm.DB, query *dto.TodoQueryDTO) (*dto.TodoPageDTO, error) {
+	cursor := &todoCursor{}
+	if query.Cursor != "" {
+		var err error
+		cursor, err = decodeTodoCursor(query.Cursor, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+	keys := todoSortKeys(query.Sort, query.Order, cursor)
+
+	tx, err := filterTodos(tx.Scopes(withTodoRelations), query)
+	if err != nil {
+		return nil, err
+	}
+	if query.Cursor != "" {
+		tx = tx.Scopes(afterCursor(keys))
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	// One more todo tells whether there is a next page
+	var todos []models.Todo
+	if err := tx.Scopes(sortTodos(keys)).Limit(limit + 1).Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	page := &dto.TodoPageDTO{Todos: make([]*dto.TodoResponseDTO, 0, len(todos))}
+	if len(todos) > limit {
+		todos = todos[:limit]
+		next := encodeTodoCursor(&todos[limit-1], query)
+		page.NextCursor = &next
+	}
+
+	// Convert todos to DTOs
+	for _, todo := range todos {
+		page.Todos = append(page.Todos, utils.ToTodoResponseDTO(&todo))
+	}
+
+	return page, nil
+}
+
+// filterTodos applies the filters of a list query.
+func filterTodos(tx *gorm.DB, query *dto.TodoQueryDTO) (*gorm.DB, error) {
+	if names := splitTagNames(query.Tags); len(names) > 0 {
+		tx = tx.Scopes(filterByTags(names, query.TagsMatch == "all"))
+	}
+	if query.ProjectID != nil {
+		tx = tx.Where("todos.project_id = ?", *query.ProjectID)
+	}
+	if query.Due != "" {
+		loc, err := loadLocation(query.TZ)
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Scopes(filterByDue(query.Due, loc, time.Now()))
+	}
+	if query.Status != "" {
+		tx = tx.Where("todos.status = ?", query.Status)
+	}
+	if query.Category != "" {
+		tx = tx.Where("todos.status_category = ?", query.Category)
+	}
+	if query.Q != "" {
+		tx = tx.Where("LOWER(todos.title) LIKE ? ESCAPE '\\'", "%"+escapeLike(strings.ToLower(query.Q))+"%")
+	}
+	if query.CreatedFrom != nil {
+		tx = tx.Where("todos.created_at >= ?", query.CreatedFrom.UTC())
+	}
+	if query.CreatedTo != nil {
+		tx = tx.Where("todos.created_at < ?", query.CreatedTo.UTC())
+	}
+	if query.UpdatedFrom != nil {
+		tx = tx.Where("todos.updated_at >= ?", query.UpdatedFrom.UTC())
+	}
+	if query.UpdatedTo != nil {
+		tx = tx.Where("todos.updated_at < ?", query.UpdatedTo.UTC())
+	}
+	return tx, nil
+}
+
+// afterCursor keeps the todos that come after the cursor in the order of the sort keys:
+// the first key is past the cursor, or it is equal and the next key is past it, and so on.
+func afterCursor(keys []todoSortKey) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		var conditions []string
+		var args []any
+		for i, key := range keys {
+			// Nothing sorts after NULL within the NULL group
+			if key.value == nil {
+				continue
+			}
+
+			var parts []string
+			for _, previous := range keys[:i] {
+				if previous.value == nil {
+					parts = append(parts, previous.expr+" IS NULL")
+				} else {
+					parts = append(parts, previous.expr+" = ?")
+					args = append(args, previous.value)
+				}
+			}
+			if key.desc {
+				parts = append(parts, key.expr+" < ?")
+			} else {
+				parts = append(parts, key.expr+" > ?")
+			}
+			args = append(args, key.value)
+			conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+		}
+		return db.Where(strings.Join(conditions, " OR "), args...)
+	}
+}
+
+// encodeTodoCursor builds the opaque cursor pointing after a todo.
+func encodeTodoCursor(todo *models.Todo, query *dto.TodoQueryDTO) string {
+	cursor := todoCursor{
+		Sort:      query.Sort,
+		Order:     query.Order,
+		DueAt:     todo.DueAt,
+		CreatedAt: todo.CreatedAt,
+		Position:  todo.Position,
+		ID:        todo.ID,
+	}
+	for rank, priority := range todoPriorities {
+		if todo.Priority == priority {
+			cursor.Priority = rank
+		}
+	}
+
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeTodoCursor reads a cursor back, it must come from a list with the same sort.
+func decodeTodoCursor(raw string, query *dto.TodoQueryDTO) (*todoCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, dto.ErrInvalidCursor
+	}
+	var cursor todoCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, dto.ErrInvalidCursor
+	}
+	if cursor.Sort != query.Sort || cursor.Order != query.Order || cursor.ID == 0 {
+		return nil, dto.ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// encodeIDCursor builds the opaque cursor pointing after the row with an id.
+func encodeIDCursor(id uint) string {
+	raw, _ := json.Marshal(idCursor{ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeIDCursor reads an id cursor back.
+func decodeIDCursor(raw string) (*idCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, dto.ErrInvalidCursor
+	}
+	var cursor idCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return nil, dto.ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPageTodos(t *testing.T) {
+	db := newTestDB(t)
+	user := models.User{Username: "ada", Email: "ada@example.com"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	day := func(d int) *time.Time {
+		at := time.Date(2025, 3, d, 9, 0, 0, 0, time.UTC)
+		return &at
+	}
+	// Every sort has ties, broken by the position and the ID, and two todos without a due date
+	todos := []struct {
+		title    string
+		dueAt    *time.Time
+		priority enums.TodoPriority
+		position string
+		created  *time.Time
+	}{
+		{title: "a", dueAt: day(3), priority: enums.TodoPriorityHigh, position: "b", created: day(1)},
+		{title: "b", priority: enums.TodoPriorityNone, position: "c", created: day(1)},
+		{title: "c", dueAt: day(4), priority: enums.TodoPriorityHigh, position: "d", created: day(2)},
+		{title: "d", dueAt: day(3), priority: enums.TodoPriorityLow, position: "e", created: day(2)},
+		{title: "e", priority: enums.TodoPriorityUrgent, position: "f", created: day(3)},
+		{title: "f", dueAt: day(4), priority: enums.TodoPriorityNone, position: "g", created: day(3)},
+	}
+	for _, todo := range todos {
+		row := models.Todo{Title: todo.title, DueAt: todo.dueAt, Priority: todo.priority, Position: todo.position, AuthorID: user.ID}
+		if err := db.Create(&row).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Model(&row).UpdateColumn("created_at", *todo.created).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		sort  string
+		order string
+		want  []string
+	}{
+		{name: "position", want: []string{"a", "b", "c", "d", "e", "f"}},
+		{name: "position desc", sort: "position", order: "desc", want: []string{"f", "e", "d", "c", "b", "a"}},
+		{name: "due", sort: "due", want: []string{"a", "d", "c", "f", "b", "e"}},
+		{name: "due desc keeps no due date last", sort: "due", order: "desc", want: []string{"c", "f", "a", "d", "b", "e"}},
+		{name: "created_at desc", sort: "created_at", order: "desc", want: []string{"e", "f", "c", "d", "a", "b"}},
+		{name: "priority", sort: "priority", want: []string{"b", "f", "d", "a", "c", "e"}},
+		{name: "priority desc", sort: "priority", order: "desc", want: []string{"e", "a", "c", "d", "b", "f"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Pages of every size cut the list at every tie
+			for _, limit := range []int{1, 2, 4, 6} {
+				query := &dto.TodoQueryDTO{Sort: tt.sort, Order: tt.order, Limit: limit}
+				var got []string
+				for pages := 0; ; pages++ {
+					if pages > len(todos) {
+						t.Fatalf("limit %d: the pages never end", limit)
+					}
+					page, err := pageTodos(db.Where("todos.author_id = ?", user.ID), query)
+					if err != nil {
+						t.Fatalf("limit %d: pageTodos() error = %v", limit, err)
+					}
+					for _, todo := range page.Todos {
+						got = append(got, todo.Title)
+					}
+					if page.NextCursor == nil {
+						break
+					}
+					query.Cursor = *page.NextCursor
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("limit %d: pages = %v, want %v", limit, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+
+	"gorm.io/gorm"
+)
+
+// todoRole returns the role a user has on a todo: the author owns it, anybody else
+// gets the highest role of their accepted shares of the todo and of its project.
+// A user the todo isn't shared with has no role.
+func todoRole(db *gorm.DB, todo *models.Todo, userID uint) (enums.ShareRole, error) {
+	if todo.AuthorID == userID {
+		return enums.ShareRoleOwner, nil
+	}
+
+	query := db.Model(&models.TodoShare{}).Where("user_id = ? AND accepted_at IS NOT NULL", userID)
+	if todo.ProjectID != nil {
+		query = query.Where("todo_id = ? OR project_id = ?", todo.ID, *todo.ProjectID)
+	} else {
+		query = query.Where("todo_id = ?", todo.ID)
+	}
+	var roles []enums.ShareRole
+	if err := query.Pluck("role", &roles).Error; err != nil {
+		return "", err
+	}
+
+	var best enums.ShareRole
+	for _, role := range roles {
+		if role.Allows(best) {
+			best = role
+		}
+	}
+	return best, nil
+}
+
+// accessibleTodos restricts a todo query to the todos a user has a role on, as todoRole
+// tells: their own todos and the ones shared with them, directly or through their project.
+func accessibleTodos(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		shared := func(column string) *gorm.DB {
+			return db.Session(&gorm.Session{NewDB: true}).Model(&models.TodoShare{}).
+				Select(column).Where("user_id = ? AND accepted_at IS NOT NULL", userID)
+		}
+		return db.Where("(todos.author_id = ? OR todos.id IN (?) OR todos.project_id IN (?))",
+			userID, shared("todo_id"), shared("project_id"))
+	}
+}
+
+// authorizeTodo checks that a user has at least a role on a todo.
+func authorizeTodo(db *gorm.DB, todo *models.Todo, userID uint, needed enums.ShareRole) error {
+	role, err := todoRole(db, todo, userID)
+	if err != nil {
+		return err
+	}
+	if !role.Allows(needed) {
+		return dto.ErrUnauthToDo
+	}
+	return nil
+}
+
+// projectRole returns the role a user has on a project: the author owns it, anybody
+// else gets the role of their accepted share of the project.
+func projectRole(db *gorm.DB, project *models.Project, userID uint) (enums.ShareRole, error) {
+	if project.AuthorID == userID {
+		return enums.ShareRoleOwner, nil
+	}
+
+	var roles []enums.ShareRole
+	err := db.Model(&models.TodoShare{}).Where("user_id = ? AND project_id = ? AND accepted_at IS NOT NULL", userID, project.ID).
+		Pluck("role", &roles).Error
+	if err != nil {
+		return "", err
+	}
+
+	var best enums.ShareRole
+	for _, role := range roles {
+		if role.Allows(best) {
+			best = role
+		}
+	}
+	return best, nil
+}
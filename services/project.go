@@ -0,0 +1,470 @@
+package services
+
+import (
+	"errors"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// inboxProjectName is the name given to the project that collects orphaned todos.
+const inboxProjectName = "Inbox"
+
+// GetUserProjects retrieves the projects of a user in their manual order.
+func GetUserProjects(authorID string, query *dto.ProjectQueryDTO) ([]*dto.ProjectResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	tx := db.Where("author_id = ?", authorIDUint)
+	if !query.Archived {
+		tx = tx.Where("archived_at IS NULL")
+	}
+
+	var projects []models.Project
+	if err := tx.Order("position, id").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	// Map to DTOs
+	var projectDTOs []*dto.ProjectResponseDTO
+	for _, project := range projects {
+		projectDTOs = append(projectDTOs, utils.ToProjectResponseDTO(&project))
+	}
+
+	return projectDTOs, nil
+}
+
+// GetProjectById retrieves a project by its ID, ensuring it belongs to the author.
+func GetProjectById(projectID string, authorID string) (*dto.ProjectResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	projectIDUint, err := utils.ConvId(projectID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	project, err := findProject(db, projectIDUint, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.ToProjectResponseDTO(project), nil
+}
+
+// CreateProject adds a new project with a unique name for each user at the end of the list.
+func CreateProject(projectDTO *dto.CreateProjectDTO, authorID string) (*dto.ProjectResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	name := strings.TrimSpace(projectDTO.Name)
+	if err := checkProjectName(db, name, authorIDUint, 0); err != nil {
+		return nil, err
+	}
+
+	project := &models.Project{
+		Name:     name,
+		AuthorID: authorIDUint,
+	}
+	if err := createProject(db, project); err != nil {
+		return nil, dto.ErrProjectCreate
+	}
+
+	return utils.ToProjectResponseDTO(project), nil
+}
+
+// RenameProject updates the name of a project, ensuring it belongs to the author.
+func RenameProject(projectID string, updateDTO *dto.UpdateProjectDTO, authorID string) (*dto.ProjectResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	projectIDUint, err := utils.ConvId(projectID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	project, err := findProject(db, projectIDUint, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+
+	if updateDTO.Name != nil {
+		name := strings.TrimSpace(*updateDTO.Name)
+		if err := checkProjectName(db, name, authorIDUint, project.ID); err != nil {
+			return nil, err
+		}
+		project.Name = name
+	}
+
+	if err := db.Save(project).Error; err != nil {
+		return nil, dto.ErrProjectUpdate
+	}
+
+	return utils.ToProjectResponseDTO(project), nil
+}
+
+// SetProjectArchived archives or unarchives a project, ensuring it belongs to the author.
+func SetProjectArchived(projectID string, archived bool, authorID string) (*dto.ProjectResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	projectIDUint, err := utils.ConvId(projectID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	project, err := findProject(db, projectIDUint, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+	if project.IsInbox {
+		return nil, dto.ErrProjectInbox
+	}
+
+	if archived && project.ArchivedAt == nil {
+		now := time.Now()
+		project.ArchivedAt = &now
+	} else if !archived {
+		project.ArchivedAt = nil
+	}
+
+	if err := db.Save(project).Error; err != nil {
+		return nil, dto.ErrProjectUpdate
+	}
+
+	return utils.ToProjectResponseDTO(project), nil
+}
+
+// ReorderProjects moves the given projects to the top of the list in the given order,
+// the remaining projects keep their relative order after them.
+func ReorderProjects(reorderDTO *dto.ReorderProjectsDTO, authorID string) ([]*dto.ProjectResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	var projects []models.Project
+	if err := db.Where("author_id = ?", authorIDUint).Order("position, id").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*models.Project, len(projects))
+	for i := range projects {
+		byID[projects[i].ID] = &projects[i]
+	}
+
+	// Listed projects come first, in the requested order
+	var ordered []*models.Project
+	listed := map[uint]bool{}
+	for _, id := range uniqueIDs(reorderDTO.ProjectIDs) {
+		project, ok := byID[id]
+		if !ok {
+			return nil, dto.ErrProjectNotFound
+		}
+		listed[id] = true
+		ordered = append(ordered, project)
+	}
+	for i := range projects {
+		if !listed[projects[i].ID] {
+			ordered = append(ordered, &projects[i])
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for position, project := range ordered {
+			if project.Position == position {
+				continue
+			}
+			project.Position = position
+			if err := tx.Model(project).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, dto.ErrProjectUpdate
+	}
+
+	// Map to DTOs
+	var projectDTOs []*dto.ProjectResponseDTO
+	for _, project := range ordered {
+		projectDTOs = append(projectDTOs, utils.ToProjectResponseDTO(project))
+	}
+
+	return projectDTOs, nil
+}
+
+// MoveTodosToProject moves the given todos into a project. The user must be able to edit
+// every todo, and the project must belong to the author of the todos, like on an update.
+func MoveTodosToProject(projectID string, moveDTO *dto.MoveTodosDTO, authorID string) ([]*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	projectIDUint, err := utils.ConvId(projectID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todoIDs := uniqueIDs(moveDTO.TodoIDs)
+	todos := make([]models.Todo, 0, len(todoIDs))
+	var project *models.Project
+	for _, todoID := range todoIDs {
+		todo, err := findTodo(db, todoID, authorIDUint, enums.ShareRoleEditor)
+		if err != nil {
+			return nil, err
+		}
+		// A project has a single author, todos of another author can't go in it
+		if project == nil || project.AuthorID != todo.AuthorID {
+			project, err = findActiveProject(db, projectIDUint, todo.AuthorID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		todos = append(todos, *todo)
+	}
+
+	// The todos keep their status if the workflow of the project has it
+	workflow, err := workflowOf(db, project.AuthorID, &project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Todo{}).Where("id IN ?", todoIDs).Update("project_id", project.ID).Error; err != nil {
+			return err
+		}
+		statusChanges, err := fitTodoStatuses(tx, todos, workflow)
+		if err != nil {
+			return err
+		}
+		for _, todo := range todos {
+			changes := map[string]dto.FieldChangeDTO{}
+			if !equalIDs(todo.ProjectID, &project.ID) {
+				changes["project_id"] = dto.FieldChangeDTO{Old: snapshotID(todo.ProjectID), New: project.ID}
+			}
+			if change, ok := statusChanges[todo.ID]; ok {
+				changes["status"] = change
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			if err := recordActivity(tx, todo.ID, &authorIDUint, enums.TodoActivityUpdated, changes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, dto.ErrToDoUpdate
+	}
+
+	// Map to DTOs
+	var todoDTOs []*dto.TodoResponseDTO
+	for _, todo := range todos {
+		todo.ProjectID = &project.ID
+		todoDTOs = append(todoDTOs, utils.ToTodoResponseDTO(&todo))
+	}
+
+	return todoDTOs, nil
+}
+
+// DeleteProject removes a project. Its todos are either moved to the trash (default)
+// or into the user's Inbox project, which is created on demand.
+func DeleteProject(projectID string, query *dto.DeleteProjectQueryDTO, authorID string) error {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	projectIDUint, err := utils.ConvId(projectID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	project, err := findProject(db, projectIDUint, authorIDUint)
+	if err != nil {
+		return err
+	}
+	if project.IsInbox {
+		return dto.ErrProjectInbox
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var todos []models.Todo
+		if err := tx.Unscoped().Select("id", "status", "status_category", "deleted_at").Where("project_id = ?", project.ID).Find(&todos).Error; err != nil {
+			return err
+		}
+
+		var newProjectID *uint
+		if query.Todos == "inbox" {
+			inbox, err := getOrCreateInbox(tx, authorIDUint)
+			if err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Model(&models.Todo{}).Where("project_id = ?", project.ID).Update("project_id", inbox.ID).Error; err != nil {
+				return err
+			}
+			newProjectID = &inbox.ID
+		} else {
+			if err := tx.Where("project_id = ?", project.ID).Delete(&models.Todo{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Model(&models.Todo{}).Where("project_id = ?", project.ID).Update("project_id", nil).Error; err != nil {
+				return err
+			}
+		}
+
+		// The todos keep their status if the workflow they now follow has it
+		workflow, err := workflowOf(tx, authorIDUint, newProjectID)
+		if err != nil {
+			return err
+		}
+		statusChanges, err := fitTodoStatuses(tx, todos, workflow)
+		if err != nil {
+			return err
+		}
+
+		// Todos trashed with the project say so, the others only changed project
+		for _, todo := range todos {
+			action := enums.TodoActivityUpdated
+			if query.Todos != "inbox" && !todo.DeletedAt.Valid {
+				action = enums.TodoActivityTrashed
+			}
+			changes := map[string]dto.FieldChangeDTO{
+				"project_id": {Old: project.ID, New: snapshotID(newProjectID)},
+			}
+			if change, ok := statusChanges[todo.ID]; ok {
+				changes["status"] = change
+			}
+			if err := recordActivity(tx, todo.ID, &authorIDUint, action, changes); err != nil {
+				return err
+			}
+		}
+		// Templates of the project create their todos outside a project from now on
+		if err := tx.Model(&models.Template{}).Where("project_id = ?", project.ID).Update("project_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(project).Error
+	})
+	if err != nil {
+		return dto.ErrProjectDelete
+	}
+
+	return nil
+}
+
+// findProject loads a project by ID and checks that the author owns it.
+func findProject(db *gorm.DB, projectID uint, authorID uint) (*models.Project, error) {
+	var project models.Project
+	err := db.Where("id = ?", projectID).First(&project).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if the author owns the project
+	if project.AuthorID != authorID {
+		return nil, dto.ErrUnauthProject
+	}
+
+	return &project, nil
+}
+
+// findActiveProject loads a project of the author that can still receive todos.
+func findActiveProject(db *gorm.DB, projectID uint, authorID uint) (*models.Project, error) {
+	project, err := findProject(db, projectID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if project.ArchivedAt != nil {
+		return nil, dto.ErrProjectArchived
+	}
+	return project, nil
+}
+
+// checkProjectName ensures no other project of the author already uses the name.
+func checkProjectName(db *gorm.DB, name string, authorID uint, excludeID uint) error {
+	var existingProject models.Project
+	err := db.Where("name = ? AND author_id = ? AND id <> ?", name, authorID, excludeID).First(&existingProject).Error
+	if err == nil {
+		return dto.ErrProjectNameAlreadyExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// createProject inserts a project after the last project of its author.
+func createProject(db *gorm.DB, project *models.Project) error {
+	var lastPosition *int
+	if err := db.Model(&models.Project{}).Where("author_id = ?", project.AuthorID).Select("MAX(position)").Scan(&lastPosition).Error; err != nil {
+		return err
+	}
+	if lastPosition != nil {
+		project.Position = *lastPosition + 1
+	}
+	return db.Create(project).Error
+}
+
+// getOrCreateInbox returns the Inbox project of the author, creating it if needed.
+func getOrCreateInbox(db *gorm.DB, authorID uint) (*models.Project, error) {
+	var inbox models.Project
+	err := db.Where("author_id = ? AND is_inbox = ?", authorID, true).First(&inbox).Error
+	if err == nil {
+		return &inbox, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	inbox = models.Project{
+		Name:     inboxProjectName,
+		IsInbox:  true,
+		AuthorID: authorID,
+	}
+	if err := createProject(db, &inbox); err != nil {
+		return nil, err
+	}
+	return &inbox, nil
+}
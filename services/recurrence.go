@@ -0,0 +1,427 @@
+package services
+
+import (
+	"errors"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// defaultOccurrenceWindow is expanded when no end date is given
+	defaultOccurrenceWindow = 30 * 24 * time.Hour
+	// maxOccurrenceWindow bounds the date window of an expansion
+	maxOccurrenceWindow = 366 * 24 * time.Hour
+	// maxOccurrences bounds the expansion of a single window
+	maxOccurrences = 500
+	// maxOccurrenceScan bounds the dates of a rule walked through to expand a window,
+	// those before the window included
+	maxOccurrenceScan = 100000
+)
+
+// ExpandOccurrences lists the occurrences of the series of a todo within a date window,
+// with the skipped and edited occurrences applied.
+func ExpandOccurrences(todoID string, query *dto.OccurrenceQueryDTO, authorID string) ([]*dto.OccurrenceResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	series, err := findSeries(db, todo)
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Now()
+	if query.From != nil {
+		from = *query.From
+	}
+	to := from.Add(defaultOccurrenceWindow)
+	if query.To != nil {
+		to = *query.To
+	}
+	if to.Before(from) || to.Sub(from) > maxOccurrenceWindow {
+		return nil, dto.ErrRecurrenceWindow
+	}
+
+	rule, err := seriesRule(series)
+	if err != nil {
+		return nil, err
+	}
+	dates := occurrencesBetween(rule, from, to)
+
+	// Occurrences that already exist as todos
+	var live []models.Todo
+	if err := db.Where("recurrence_id = ? AND occurrence_at >= ? AND occurrence_at <= ?", series.ID, from.UTC(), to.UTC()).Find(&live).Error; err != nil {
+		return nil, err
+	}
+
+	var occurrences []*dto.OccurrenceResponseDTO
+	for _, date := range dates {
+		occurrence := &dto.OccurrenceResponseDTO{
+			OccurrenceAt: date,
+			DueAt:        date,
+			Title:        seriesTitle(series, todo),
+			Description:  todo.Description,
+		}
+		if exception := findException(series, date); exception != nil {
+			applyException(occurrence, exception)
+		}
+		for i := range live {
+			if live[i].OccurrenceAt != nil && live[i].OccurrenceAt.Equal(date) {
+				occurrence.TodoID = &live[i].ID
+				occurrence.Title = live[i].Title
+				occurrence.Description = live[i].Description
+				if live[i].DueAt != nil {
+					occurrence.DueAt = live[i].DueAt.In(date.Location())
+				}
+			}
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	return occurrences, nil
+}
+
+// UpdateOccurrence skips or edits a single upcoming occurrence without changing the series.
+func UpdateOccurrence(todoID string, updateDTO *dto.UpdateOccurrenceDTO, authorID string) (*dto.OccurrenceResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	series, err := findSeries(db, todo)
+	if err != nil {
+		return nil, err
+	}
+
+	// The date must be one the rule actually produces
+	rule, err := seriesRule(series)
+	if err != nil {
+		return nil, err
+	}
+	occurrenceAt := updateDTO.OccurrenceAt
+	if dates := rule.Between(occurrenceAt, occurrenceAt, true); len(dates) == 0 {
+		return nil, dto.ErrRecurrenceNoOccurrence
+	}
+
+	// Materialized occurrences are plain todos and are edited as such
+	var live int64
+	if err := db.Model(&models.Todo{}).Where("recurrence_id = ? AND occurrence_at = ?", series.ID, occurrenceAt.UTC()).Count(&live).Error; err != nil {
+		return nil, err
+	}
+	if live > 0 {
+		return nil, dto.ErrRecurrenceOccurrenceLive
+	}
+
+	exception := findException(series, occurrenceAt)
+	if exception == nil {
+		exception = &models.RecurrenceException{
+			RecurrenceID: series.ID,
+			OccurrenceAt: occurrenceAt.UTC(),
+		}
+	}
+
+	// Update fields if provided
+	if updateDTO.Skip != nil {
+		exception.Skipped = *updateDTO.Skip
+	}
+	if updateDTO.Title != nil {
+		exception.Title = updateDTO.Title
+	}
+	if updateDTO.Description != nil {
+		exception.Description = updateDTO.Description
+	}
+	if updateDTO.DueAt != nil {
+		exception.DueAt = updateDTO.DueAt
+	}
+
+	if err := db.Save(exception).Error; err != nil {
+		return nil, dto.ErrRecurrenceUpdate
+	}
+
+	occurrence := &dto.OccurrenceResponseDTO{
+		OccurrenceAt: occurrenceAt,
+		DueAt:        occurrenceAt,
+		Title:        seriesTitle(series, todo),
+		Description:  todo.Description,
+	}
+	applyException(occurrence, exception)
+
+	return occurrence, nil
+}
+
+// newRecurrence validates a recurrence payload and builds the series of a todo titled
+// title starting at dtstart. Series repeat at most daily.
+func newRecurrence(recurrenceDTO *dto.RecurrenceDTO, title string, dtstart *time.Time, authorID uint) (*models.Recurrence, error) {
+	if dtstart == nil {
+		return nil, dto.ErrRecurrenceNeedsDueDate
+	}
+
+	timezone := recurrenceDTO.Timezone
+	if timezone == "" {
+		timezone = time.UTC.String()
+	}
+	series := &models.Recurrence{
+		Title:    title,
+		Rule:     strings.TrimPrefix(strings.TrimSpace(recurrenceDTO.Rule), "RRULE:"),
+		Timezone: timezone,
+		Dtstart:  *dtstart,
+		AuthorID: authorID,
+	}
+
+	// The series carries its own DTSTART, the rule must be a single RRULE line
+	if strings.ContainsAny(series.Rule, "\n\r") || strings.Contains(series.Rule, "DTSTART") {
+		return nil, dto.ErrRecurrenceInvalidRule
+	}
+	rule, err := seriesRule(series)
+	if err != nil {
+		return nil, err
+	}
+	// Sub-daily rules would outrun maxOccurrenceScan and lose occurrences when expanded
+	if rule.OrigOptions.Freq > rrule.DAILY {
+		return nil, dto.ErrRecurrenceFrequency
+	}
+	series.Rule = rule.OrigOptions.RRuleString()
+
+	return series, nil
+}
+
+// seriesRule builds the RRULE of a series, evaluated in the series timezone.
+func seriesRule(series *models.Recurrence) (*rrule.RRule, error) {
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		return nil, dto.ErrRecurrenceInvalidRule
+	}
+	option, err := rrule.StrToROptionInLocation(series.Rule, loc)
+	if err != nil {
+		return nil, dto.ErrRecurrenceInvalidRule
+	}
+	option.Dtstart = series.Dtstart.In(loc)
+	rule, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, dto.ErrRecurrenceInvalidRule
+	}
+	return rule, nil
+}
+
+// occurrencesBetween returns the first maxOccurrences dates of a rule within a window,
+// its bounds included. The rule is walked from its start and the walk stops as soon as
+// the window is full, so frequent rules never expand a whole window.
+func occurrencesBetween(rule *rrule.RRule, from time.Time, to time.Time) []time.Time {
+	var dates []time.Time
+	next := rule.Iterator()
+	for scanned := 0; len(dates) < maxOccurrences && scanned < maxOccurrenceScan; scanned++ {
+		date, ok := next()
+		if !ok || date.After(to) {
+			break
+		}
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+// findSeries loads the series of a recurring todo with its exceptions.
+func findSeries(db *gorm.DB, todo *models.Todo) (*models.Recurrence, error) {
+	if todo.RecurrenceID == nil {
+		return nil, dto.ErrToDoNotRecurring
+	}
+	var series models.Recurrence
+	err := db.Preload("Exceptions").First(&series, *todo.RecurrenceID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrToDoNotRecurring
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+// findException returns the exception of a series for an occurrence date, if any.
+func findException(series *models.Recurrence, occurrenceAt time.Time) *models.RecurrenceException {
+	for i := range series.Exceptions {
+		if series.Exceptions[i].OccurrenceAt.Equal(occurrenceAt) {
+			return &series.Exceptions[i]
+		}
+	}
+	return nil
+}
+
+// applyException overrides an occurrence with what its exception changed.
+func applyException(occurrence *dto.OccurrenceResponseDTO, exception *models.RecurrenceException) {
+	occurrence.Skipped = exception.Skipped
+	if exception.Title != nil {
+		occurrence.Title = *exception.Title
+	}
+	if exception.Description != nil {
+		occurrence.Description = *exception.Description
+	}
+	if exception.DueAt != nil {
+		occurrence.DueAt = *exception.DueAt
+	}
+}
+
+// spawnNextOccurrence creates the todo of the occurrence following a completed one.
+// Skipped occurrences are jumped over, and nothing is created when the series is over
+// or when the next occurrence already exists.
+func spawnNextOccurrence(tx *gorm.DB, todo *models.Todo) error {
+	series, err := findSeries(tx, todo)
+	if err != nil {
+		return err
+	}
+	if err := lockSeries(tx, series.ID); err != nil {
+		return err
+	}
+	rule, err := seriesRule(series)
+	if err != nil {
+		return err
+	}
+
+	current := todo.OccurrenceAt
+	if current == nil {
+		current = todo.DueAt
+	}
+	if current == nil {
+		return nil
+	}
+
+	// Find the next occurrence that is not skipped
+	next := rule.After(*current, false)
+	var exception *models.RecurrenceException
+	for i := 0; !next.IsZero() && i < maxOccurrences; i++ {
+		exception = findException(series, next)
+		if exception == nil || !exception.Skipped {
+			break
+		}
+		next = rule.After(next, false)
+	}
+	if next.IsZero() || (exception != nil && exception.Skipped) {
+		return nil
+	}
+
+	var existing int64
+	if err := tx.Unscoped().Model(&models.Todo{}).Where("recurrence_id = ? AND occurrence_at = ?", series.ID, next.UTC()).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
+
+	occurrence := &dto.OccurrenceResponseDTO{
+		OccurrenceAt: next,
+		DueAt:        next,
+		Title:        seriesTitle(series, todo),
+		Description:  todo.Description,
+	}
+	if exception != nil {
+		applyException(occurrence, exception)
+	}
+
+	// Titles stay unique: the completed occurrence keeps its title, so the next one is
+	// numbered after the series, or after the title an edit of the occurrence gave it
+	title, err := availableTodoTitle(tx, occurrence.Title, todo.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	position, err := nextTodoPosition(tx, todo.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	// The next occurrence starts over in the first status of the workflow
+	workflow, err := todoWorkflow(tx, todo)
+	if err != nil {
+		return err
+	}
+	initial := workflow.Initial()
+
+	occurrenceAt := next.UTC()
+	dueAt := occurrence.DueAt.UTC()
+	nextTodo := &models.Todo{
+		Title:          title,
+		Description:    occurrence.Description,
+		Status:         initial.Name,
+		StatusCategory: initial.Category,
+		Priority:       todo.Priority,
+		Position:       position,
+		AuthorID:       todo.AuthorID,
+		ProjectID:      todo.ProjectID,
+		DueAt:          &dueAt,
+		RecurrenceID:   &series.ID,
+		OccurrenceAt:   &occurrenceAt,
+		Tags:           todo.Tags,
+	}
+	// Keep the same lead time between start and due dates
+	if todo.StartAt != nil && todo.DueAt != nil {
+		startAt := dueAt.Add(-todo.DueAt.Sub(*todo.StartAt))
+		nextTodo.StartAt = &startAt
+	}
+	for _, subtask := range todo.Subtasks {
+		nextTodo.Subtasks = append(nextTodo.Subtasks, models.Subtask{
+			Title:    subtask.Title,
+			Required: subtask.Required,
+			Position: subtask.Position,
+		})
+	}
+
+	if err := tx.Omit("Tags.*", "Recurrence").Create(nextTodo).Error; err != nil {
+		return err
+	}
+	return recordActivity(tx, nextTodo.ID, nil, enums.TodoActivityCreated, diffSnapshots(nil, todoSnapshot(nextTodo)))
+}
+
+// seriesTitle returns the title the occurrences of a series take before being numbered.
+// Series stored without a title take the one of their current todo.
+func seriesTitle(series *models.Recurrence, todo *models.Todo) string {
+	if series.Title == "" {
+		return todo.Title
+	}
+	return series.Title
+}
+
+// renameSeries makes the title of a recurring todo the title of its next occurrences.
+func renameSeries(tx *gorm.DB, todo *models.Todo) error {
+	if todo.RecurrenceID == nil {
+		return nil
+	}
+	return tx.Model(&models.Recurrence{}).Where("id = ?", *todo.RecurrenceID).Update("title", todo.Title).Error
+}
+
+// lockSeries serializes the completion of occurrences of a series.
+func lockSeries(tx *gorm.DB, seriesID uint) error {
+	var series models.Recurrence
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&series, seriesID).Error
+}
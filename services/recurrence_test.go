@@ -0,0 +1,42 @@
+package services
+
+import (
+	"errors"
+	dto "go-feToDo/dtos"
+	"testing"
+	"time"
+)
+
+func TestNewRecurrence(t *testing.T) {
+	dtstart := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		rule    string
+		wantErr error
+	}{
+		{name: "daily", rule: "FREQ=DAILY"},
+		{name: "weekly with a prefix", rule: "RRULE:FREQ=WEEKLY;BYDAY=MO"},
+		{name: "yearly", rule: "FREQ=YEARLY;COUNT=3"},
+		{name: "hourly", rule: "FREQ=HOURLY", wantErr: dto.ErrRecurrenceFrequency},
+		{name: "minutely", rule: "FREQ=MINUTELY;INTERVAL=30", wantErr: dto.ErrRecurrenceFrequency},
+		{name: "secondly", rule: "FREQ=SECONDLY", wantErr: dto.ErrRecurrenceFrequency},
+		{name: "own dtstart", rule: "DTSTART:20250303T090000Z\nRRULE:FREQ=DAILY", wantErr: dto.ErrRecurrenceInvalidRule},
+		{name: "no frequency", rule: "BYDAY=MO", wantErr: dto.ErrRecurrenceInvalidRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := newRecurrence(&dto.RecurrenceDTO{Rule: tt.rule}, "Water the plants (2)", &dtstart, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("newRecurrence(%q) error = %v, want %v", tt.rule, err, tt.wantErr)
+			}
+			if err == nil && series.Title != "Water the plants (2)" {
+				t.Errorf("newRecurrence(%q) title = %q, want the title of the todo as is", tt.rule, series.Title)
+			}
+		})
+	}
+
+	if _, err := newRecurrence(&dto.RecurrenceDTO{Rule: "FREQ=DAILY"}, "Water the plants", nil, 1); !errors.Is(err, dto.ErrRecurrenceNeedsDueDate) {
+		t.Errorf("newRecurrence() without a due date error = %v, want %v", err, dto.ErrRecurrenceNeedsDueDate)
+	}
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultSearchLimit is the number of hits returned when no limit is given
+	defaultSearchLimit = 20
+	// snippetRadius is the number of characters kept around a match in a fallback snippet
+	snippetRadius = 40
+	// highlightStart and highlightStop wrap the matches until the snippet is escaped
+	highlightStart = "\x01"
+	highlightStop  = "\x02"
+)
+
+// searchTerm is a word or a quoted phrase of a search query. The last word
+// of the term matches as a prefix when the term ends with "*".
+type searchTerm struct {
+	words  []string
+	prefix bool
+}
+
+// searchWord is a lowercased word of a text and its byte offsets in the text.
+type searchWord struct {
+	text       string
+	start, end int
+}
+
+// searchHit is a todo matching a search, with its rank and raw snippets.
+type searchHit struct {
+	ID                 uint
+	Rank               float64
+	TitleSnippet       string
+	DescriptionSnippet string
+}
+
+// SearchTodos runs a ranked full-text search over the titles and descriptions of the
+// active todos of the author. Postgres uses the todos search vector, other stores fall
+// back on a substring search ranked in memory.
+func SearchTodos(query *dto.SearchQueryDTO, authorID string) ([]*dto.SearchResultDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	terms := parseSearchQuery(query.Q)
+	if len(terms) == 0 {
+		return []*dto.SearchResultDTO{}, nil
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+
+	var hits []searchHit
+	if db.Dialector.Name() == "postgres" {
+		hits, err = searchWithVector(db, terms, authorIDUint, limit, query.Offset)
+	} else {
+		hits, err = searchWithLike(db, terms, authorIDUint, limit, query.Offset)
+	}
+	if err != nil {
+		return nil, dto.ErrToDoSearch
+	}
+
+	// Load the todos of the hits, keeping the rank order
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	var todos []models.Todo
+	if err := db.Scopes(withTodoRelations).Where("id IN ?", ids).Find(&todos).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*models.Todo, len(todos))
+	for i := range todos {
+		byID[todos[i].ID] = &todos[i]
+	}
+
+	results := make([]*dto.SearchResultDTO, 0, len(hits))
+	for _, hit := range hits {
+		todo, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, &dto.SearchResultDTO{
+			Todo:               utils.ToTodoResponseDTO(todo),
+			Rank:               hit.Rank,
+			TitleSnippet:       escapeSnippet(hit.TitleSnippet),
+			DescriptionSnippet: escapeSnippet(hit.DescriptionSnippet),
+		})
+	}
+
+	return results, nil
+}
+
+// searchWithVector ranks the todos matching the terms with the Postgres search vector.
+func searchWithVector(db *gorm.DB, terms []searchTerm, authorID uint, limit int, offset int) ([]searchHit, error) {
+	titleOptions := "StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", HighlightAll=true"
+	descriptionOptions := "StartSel=" + highlightStart + ", StopSel=" + highlightStop + `, MaxFragments=2, MinWords=5, MaxWords=20, FragmentDelimiter=" … "`
+
+	var hits []searchHit
+	err := db.Model(&models.Todo{}).
+		Select("todos.id, ts_rank_cd(todos.search_vector, search_query) AS rank, "+
+			"ts_headline('"+database.SearchConfig+"', coalesce(todos.title, ''), search_query, ?) AS title_snippet, "+
+			"ts_headline('"+database.SearchConfig+"', coalesce(todos.description, ''), search_query, ?) AS description_snippet",
+			titleOptions, descriptionOptions).
+		Joins("CROSS JOIN to_tsquery('"+database.SearchConfig+"', ?) AS search_query", toTSQuery(terms)).
+		Where("todos.author_id = ? AND todos.deleted_at IS NULL AND todos.search_vector @@ search_query", authorID).
+		Order("rank DESC, todos.id").
+		Limit(limit).
+		Offset(offset).
+		Scan(&hits).Error
+	return hits, err
+}
+
+// searchWithLike finds the todos matching every term and ranks them in memory, title
+// matches count double. It is meant for local stores without full-text search, and
+// matches like the search vector: words are split the same way, the words of a phrase
+// follow each other, from the title on to the description, and only prefixes match
+// the start of a word.
+func searchWithLike(db *gorm.DB, terms []searchTerm, authorID uint, limit int, offset int) ([]searchHit, error) {
+	// The words of every term in order narrow the todos down, they are matched in memory.
+	// SQLite only lowercases ASCII, so capitals beyond it don't match a lowercase query
+	tx := db.Model(&models.Todo{}).Where("todos.author_id = ? AND todos.deleted_at IS NULL", authorID)
+	for _, term := range terms {
+		words := make([]string, 0, len(term.words))
+		for _, word := range term.words {
+			words = append(words, escapeLike(word))
+		}
+		pattern := "%" + strings.Join(words, "%") + "%"
+		tx = tx.Where("LOWER(todos.title || ' ' || COALESCE(todos.description, '')) LIKE ? ESCAPE '\\'", pattern)
+	}
+
+	var todos []models.Todo
+	if err := tx.Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	hits := make([]searchHit, 0, len(todos))
+	for _, todo := range todos {
+		words := append(splitSearchWords(todo.Title), splitSearchWords(todo.Description)...)
+		if !matchesEveryTerm(words, terms) {
+			continue
+		}
+		title, titleMatches := highlightTerms(todo.Title, terms)
+		description, descriptionMatches := highlightTerms(todo.Description, terms)
+		hits = append(hits, searchHit{
+			ID:                 todo.ID,
+			Rank:               float64(2*titleMatches + descriptionMatches),
+			TitleSnippet:       title,
+			DescriptionSnippet: trimSnippet(description),
+		})
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	if offset >= len(hits) {
+		return nil, nil
+	}
+	hits = hits[offset:]
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// isSearchRune reports whether a rune belongs to a word, anything else separates words.
+func isSearchRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// splitSearchWords splits a text into its lowercased words, the way queries are split.
+func splitSearchWords(text string) []searchWord {
+	var words []searchWord
+	start := -1
+	for i, r := range text {
+		if isSearchRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			words = append(words, searchWord{text: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, searchWord{text: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return words
+}
+
+// termMatchesAt reports whether the words of a term follow each other in words from i.
+func termMatchesAt(words []searchWord, i int, term searchTerm) bool {
+	if i+len(term.words) > len(words) {
+		return false
+	}
+	for j, word := range term.words {
+		if term.prefix && j == len(term.words)-1 {
+			if !strings.HasPrefix(words[i+j].text, word) {
+				return false
+			}
+		} else if words[i+j].text != word {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesEveryTerm reports whether every term matches somewhere in words.
+func matchesEveryTerm(words []searchWord, terms []searchTerm) bool {
+	for _, term := range terms {
+		found := false
+		for i := range words {
+			if termMatchesAt(words, i, term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSearchQuery splits a search query into words and quoted phrases.
+// Words are lowercased and anything but letters and digits separates them.
+func parseSearchQuery(q string) []searchTerm {
+	var terms []searchTerm
+	for i, part := range strings.Split(q, `"`) {
+		// Odd parts are between quotes
+		var raws []string
+		if i%2 == 1 {
+			raws = []string{part}
+		} else {
+			raws = strings.Fields(part)
+		}
+		for _, raw := range raws {
+			raw = strings.TrimSpace(raw)
+			words := strings.FieldsFunc(strings.ToLower(raw), func(r rune) bool {
+				return !isSearchRune(r)
+			})
+			if len(words) == 0 {
+				continue
+			}
+			terms = append(terms, searchTerm{words: words, prefix: strings.HasSuffix(raw, "*")})
+		}
+	}
+	return terms
+}
+
+// toTSQuery writes the terms as a tsquery: every term is required,
+// phrases follow each other and prefixes end with ":*".
+func toTSQuery(terms []searchTerm) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		words := make([]string, 0, len(term.words))
+		for _, word := range term.words {
+			words = append(words, "'"+word+"'")
+		}
+		if term.prefix {
+			words[len(words)-1] += ":*"
+		}
+		parts = append(parts, strings.Join(words, " <-> "))
+	}
+	return strings.Join(parts, " & ")
+}
+
+// highlightTerms wraps every match of the terms in a text and counts them. A match runs
+// from the first word of its term to the last, a prefix covers its whole word.
+func highlightTerms(text string, terms []searchTerm) (string, int) {
+	words := splitSearchWords(text)
+	marked := make([]bool, len(text)+1)
+	matches := 0
+	for _, term := range terms {
+		for i := 0; i < len(words); i++ {
+			if !termMatchesAt(words, i, term) {
+				continue
+			}
+			last := i + len(term.words) - 1
+			for j := words[i].start; j < words[last].end; j++ {
+				marked[j] = true
+			}
+			matches++
+			i = last
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString(highlightStart)
+		}
+		b.WriteByte(text[i])
+		if marked[i] && !marked[i+1] {
+			b.WriteString(highlightStop)
+		}
+	}
+	return b.String(), matches
+}
+
+// trimSnippet keeps the part of a highlighted text around its first match.
+func trimSnippet(text string) string {
+	first := strings.Index(text, highlightStart)
+	if first < 0 {
+		first = 0
+	}
+	runes := []rune(text)
+	start := len([]rune(text[:first])) - snippetRadius
+	end := start + 3*snippetRadius
+	prefix, suffix := "… ", " …"
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(runes) {
+		end, suffix = len(runes), ""
+	}
+	snippet := string(runes[start:end])
+	// Don't cut a highlighted match in two
+	if strings.Count(snippet, highlightStart) > strings.Count(snippet, highlightStop) {
+		snippet += highlightStop
+	}
+	return prefix + snippet + suffix
+}
+
+// escapeSnippet escapes a snippet for HTML and turns its highlights into <mark> tags.
+func escapeSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, highlightStart, "<mark>")
+	return strings.ReplaceAll(snippet, highlightStop, "</mark>")
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"go-feToDo/database"
+	"go-feToDo/models"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// markHighlights writes the highlights of a snippet as brackets, to read the expected ones.
+var markHighlights = strings.NewReplacer(highlightStart, "[", highlightStop, "]")
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		q    string
+		want []searchTerm
+	}{
+		{name: "words", q: "Buy  MILK", want: []searchTerm{{words: []string{"buy"}}, {words: []string{"milk"}}}},
+		{name: "phrase and prefix", q: `"grocery list" mil*`, want: []searchTerm{
+			{words: []string{"grocery", "list"}},
+			{words: []string{"mil"}, prefix: true},
+		}},
+		{name: "phrase prefix", q: `"new yea*"`, want: []searchTerm{{words: []string{"new", "yea"}, prefix: true}}},
+		{name: "unclosed phrase", q: `call "the plumber`, want: []searchTerm{
+			{words: []string{"call"}},
+			{words: []string{"the", "plumber"}},
+		}},
+		{name: "punctuation splits words", q: "e-mail", want: []searchTerm{{words: []string{"e", "mail"}}}},
+		{name: "letters beyond ascii", q: "Café", want: []searchTerm{{words: []string{"café"}}}},
+		{name: "blank", q: "   ", want: nil},
+		{name: "no letters", q: `*** !! ""`, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSearchQuery(tt.q); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSearchQuery(%q) = %+v, want %+v", tt.q, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToTSQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		q    string
+		want string
+	}{
+		{name: "word", q: "milk", want: "'milk'"},
+		{name: "every term is required", q: "buy milk", want: "'buy' & 'milk'"},
+		{name: "phrase", q: `"grocery list"`, want: "'grocery' <-> 'list'"},
+		{name: "prefix", q: "mil*", want: "'mil':*"},
+		{name: "phrase prefix", q: `"new yea*" party`, want: "'new' <-> 'yea':* & 'party'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toTSQuery(parseSearchQuery(tt.q)); got != tt.want {
+				t.Errorf("toTSQuery(%q) = %q, want %q", tt.q, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightTerms(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		q           string
+		want        string
+		wantMatches int
+	}{
+		{name: "every occurrence", text: "Buy milk and more Milk", q: "milk", want: "Buy [milk] and more [Milk]", wantMatches: 2},
+		{name: "phrase", text: "Grocery list for the week", q: `"grocery list"`, want: "[Grocery list] for the week", wantMatches: 1},
+		{name: "phrase across punctuation", text: "Send the e-mail", q: "e-mail", want: "Send the [e-mail]", wantMatches: 1},
+		{name: "words stay apart", text: "Milk shake", q: "milk shake", want: "[Milk] [shake]", wantMatches: 2},
+		{name: "overlapping matches merge", text: "Grocery list", q: `"grocery list" list`, want: "[Grocery list]", wantMatches: 2},
+		{name: "only whole words", text: "Buttermilk", q: "milk", want: "Buttermilk", wantMatches: 0},
+		{name: "prefix covers its word", text: "Milkshake", q: "milk*", want: "[Milkshake]", wantMatches: 1},
+		{name: "no match", text: "Water the plants", q: "milk", want: "Water the plants", wantMatches: 0},
+		{name: "empty text", text: "", q: "milk", want: "", wantMatches: 0},
+		{name: "letters beyond ascii", text: "İstanbul trip", q: "trip", want: "İstanbul [trip]", wantMatches: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, matches := highlightTerms(tt.text, parseSearchQuery(tt.q))
+			if markHighlights.Replace(got) != tt.want || matches != tt.wantMatches {
+				t.Errorf("highlightTerms(%q, %q) = %q, %d, want %q, %d", tt.text, tt.q, markHighlights.Replace(got), matches, tt.want, tt.wantMatches)
+			}
+		})
+	}
+}
+
+func TestTrimSnippet(t *testing.T) {
+	match := highlightStart + "milk" + highlightStop
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "short text", text: "Whole " + match + " from the farm", want: "Whole [milk] from the farm"},
+		{name: "no match keeps the start", text: strings.Repeat("a", 130), want: strings.Repeat("a", 80) + " …"},
+		{
+			name: "around the first match",
+			text: strings.Repeat("a", 100) + match + strings.Repeat("b", 100),
+			want: "… " + strings.Repeat("a", 40) + "[milk]" + strings.Repeat("b", 74) + " …",
+		},
+		{
+			name: "a cut match is closed",
+			text: match + strings.Repeat("c", 70) + match + strings.Repeat("d", 50),
+			want: "[milk]" + strings.Repeat("c", 70) + "[mil]" + " …",
+		},
+		{
+			name: "runes are counted, not bytes",
+			text: strings.Repeat("é", 50) + match,
+			want: "… " + strings.Repeat("é", 40) + "[milk]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markHighlights.Replace(trimSnippet(tt.text)); got != tt.want {
+				t.Errorf("trimSnippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestSearchDB opens an in-memory SQLite store, the kind of local store searchWithLike
+// serves, with the todos of two users. It returns the first user and the IDs of their todos
+// by title.
+func newTestSearchDB(t *testing.T) (*gorm.DB, uint, map[string]uint) {
+	t.Helper()
+	db := newTestDB(t)
+	users := []models.User{{Username: "ada", Email: "ada@example.com"}, {Username: "bob", Email: "bob@example.com"}}
+	if err := db.Create(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	todos := []models.Todo{
+		{Title: "Milk the cows", Description: "Before the vet comes", AuthorID: users[0].ID},
+		{Title: "Buy milk", Description: "Whole milk from the farm shop", AuthorID: users[0].ID},
+		{Title: "Groceries", Description: "For the weekend: bread, butter, apples, pears, coffee beans, milk and eggs. " +
+			"Oat milk if the farm shop has no whole milk left, they usually sell out by noon on Saturdays.", AuthorID: users[0].ID},
+		{Title: "Call mum", Description: "About the dinner on Sunday", AuthorID: users[0].ID},
+		{Title: "Old milk", AuthorID: users[0].ID},
+		{Title: "Buy milk too", AuthorID: users[1].ID},
+	}
+	if err := db.Create(&todos).Error; err != nil {
+		t.Fatal(err)
+	}
+	// Trashed todos aren't searched
+	if err := db.Delete(&todos[4]).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ids := map[string]uint{}
+	for _, todo := range todos {
+		if todo.AuthorID == users[0].ID {
+			ids[todo.Title] = todo.ID
+		}
+	}
+	return db, users[0].ID, ids
+}
+
+func TestSearchWithLike(t *testing.T) {
+	db, authorID, ids := newTestSearchDB(t)
+	titles := map[uint]string{}
+	for title, id := range ids {
+		titles[id] = title
+	}
+
+	tests := []struct {
+		name   string
+		q      string
+		limit  int
+		offset int
+		want   []string
+	}{
+		// Title matches count double: "Buy milk" and "Groceries" rank 3, "Milk the cows" 2
+		{name: "ranked", q: "milk", limit: 10, want: []string{"Buy milk", "Groceries", "Milk the cows"}},
+		{name: "prefix", q: "mil*", limit: 10, want: []string{"Buy milk", "Groceries", "Milk the cows"}},
+		{name: "every term is required", q: "milk eggs", limit: 10, want: []string{"Groceries"}},
+		{name: "phrase", q: `"farm shop"`, limit: 10, want: []string{"Buy milk", "Groceries"}},
+		{name: "limit and offset", q: "milk", limit: 1, offset: 1, want: []string{"Groceries"}},
+		{name: "offset past the hits", q: "milk", limit: 10, offset: 3, want: nil},
+		{name: "no match", q: "plumber", limit: 10, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := searchWithLike(db, parseSearchQuery(tt.q), authorID, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("searchWithLike() error = %v", err)
+			}
+			var got []string
+			for _, hit := range hits {
+				got = append(got, titles[hit.ID])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("searchWithLike(%q) = %q, want %q", tt.q, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchWithLikeSnippets(t *testing.T) {
+	db, authorID, ids := newTestSearchDB(t)
+
+	hits, err := searchWithLike(db, parseSearchQuery("milk"), authorID, 10, 0)
+	if err != nil {
+		t.Fatalf("searchWithLike() error = %v", err)
+	}
+
+	type snippet struct {
+		ID          uint
+		Rank        float64
+		Title       string
+		Description string
+	}
+	want := []snippet{
+		{ID: ids["Buy milk"], Rank: 3, Title: "Buy [milk]", Description: "Whole [milk] from the farm shop"},
+		{ID: ids["Groceries"], Rank: 3, Title: "Groceries", Description: "… d, butter, apples, pears, coffee beans, [milk] and eggs. " +
+			"Oat [milk] if the farm shop has no whole [milk] left, they usua …"},
+		{ID: ids["Milk the cows"], Rank: 2, Title: "[Milk] the cows", Description: "Before the vet comes"},
+	}
+	var got []snippet
+	for _, hit := range hits {
+		got = append(got, snippet{
+			ID:          hit.ID,
+			Rank:        hit.Rank,
+			Title:       markHighlights.Replace(hit.TitleSnippet),
+			Description: markHighlights.Replace(hit.DescriptionSnippet),
+		})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("searchWithLike() = %+v, want %+v", got, want)
+	}
+}
+
+// searchPathTodos are the todos both search paths must find the same way, by title.
+var searchPathTodos = []models.Todo{
+	{Title: "Send the e-mail", Description: "To the landlord"},
+	{Title: "Grocery, list", Description: "Bread and milk"},
+	{Title: "Buttermilk pancakes"},
+	{Title: "Milkshake", Description: "Vanilla"},
+	{Title: "Call the", Description: "plumber about room 42"},
+	{Title: "Café crème", Description: "With a list of the cafés around"},
+}
+
+// searchPathQueries are the queries run on both paths with the titles they find.
+var searchPathQueries = []struct {
+	q    string
+	want []string
+}{
+	{q: "e-mail", want: []string{"Send the e-mail"}},
+	{q: `"grocery list"`, want: []string{"Grocery, list"}},
+	{q: "milk", want: []string{"Grocery, list"}},
+	{q: "mil*", want: []string{"Grocery, list", "Milkshake"}},
+	{q: `"the plumber"`, want: []string{"Call the"}},
+	{q: "42 room", want: []string{"Call the"}},
+	{q: "café", want: []string{"Café crème"}},
+	{q: "caf*", want: []string{"Café crème"}},
+	{q: "list", want: []string{"Café crème", "Grocery, list"}},
+	{q: `"list bread"`, want: []string{"Grocery, list"}},
+	{q: `"bread milk"`, want: nil},
+	{q: "butter", want: nil},
+}
+
+// createSearchPathTodos creates the todos of searchPathTodos for a new user and returns the user.
+func createSearchPathTodos(t *testing.T, db *gorm.DB) uint {
+	t.Helper()
+	user := models.User{Username: "ada", Email: "ada@example.com"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	todos := make([]models.Todo, len(searchPathTodos))
+	copy(todos, searchPathTodos)
+	for i := range todos {
+		todos[i].AuthorID = user.ID
+	}
+	if err := db.Create(&todos).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user.ID
+}
+
+// runSearchPath runs every query of searchPathQueries with a search path.
+func runSearchPath(t *testing.T, db *gorm.DB, authorID uint, search func(*gorm.DB, []searchTerm, uint, int, int) ([]searchHit, error)) {
+	t.Helper()
+	for _, tt := range searchPathQueries {
+		hits, err := search(db, parseSearchQuery(tt.q), authorID, 10, 0)
+		if err != nil {
+			t.Fatalf("search(%q) error = %v", tt.q, err)
+		}
+		var got []string
+		for _, hit := range hits {
+			var todo models.Todo
+			if err := db.First(&todo, hit.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, todo.Title)
+		}
+		// The paths rank differently, only what they find must agree
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("search(%q) = %q, want %q", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestSearchPaths(t *testing.T) {
+	t.Run("like", func(t *testing.T) {
+		db := newTestDB(t)
+		runSearchPath(t, db, createSearchPathTodos(t, db), searchWithLike)
+	})
+
+	// The search vector needs Postgres, the same queries run there when TEST_DATABASE_DSN is set
+	t.Run("vector", func(t *testing.T) {
+		dsn := os.Getenv("TEST_DATABASE_DSN")
+		if dsn == "" {
+			t.Skip("TEST_DATABASE_DSN is not set")
+		}
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := db.Begin()
+		if tx.Error != nil {
+			t.Fatal(tx.Error)
+		}
+		defer tx.Rollback()
+		if err := tx.Exec("CREATE SCHEMA search_test").Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Exec("SET LOCAL search_path TO search_test").Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.AutoMigrate(&models.User{}, &models.Todo{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := database.MigrateSearch(tx); err != nil {
+			t.Fatal(err)
+		}
+		runSearchPath(t, tx, createSearchPathTodos(t, tx), searchWithVector)
+	})
+}
@@ -0,0 +1,423 @@
+package services
+
+import (
+	"errors"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// withShareRelations preloads everything a ShareResponseDTO is built from.
+func withShareRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("User").Preload("InvitedBy").Preload("Todo").Preload("Project")
+}
+
+// ShareTodo invites a user to a todo with a role. Inviting a user the todo is already
+// shared with changes their role. Only owners can share a todo.
+func ShareTodo(todoID string, shareDTO *dto.CreateShareDTO, userID string) (*dto.ShareResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert userID to uint
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, userIDUint, enums.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeTodo(db, todo, userIDUint, enums.ShareRoleOwner); err != nil {
+		return nil, dto.ErrUnauthShare
+	}
+
+	share := &models.TodoShare{TodoID: &todo.ID}
+	if err := inviteUser(db, share, shareDTO, todo.AuthorID, userIDUint); err != nil {
+		return nil, err
+	}
+
+	return reloadShare(db, share.ID)
+}
+
+// ShareProject invites a user to every todo of a project with a role. Only owners can share a project.
+func ShareProject(projectID string, shareDTO *dto.CreateShareDTO, userID string) (*dto.ShareResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert userID to uint
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	projectIDUint, err := utils.ConvId(projectID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	project, err := findSharedProject(db, projectIDUint, userIDUint, enums.ShareRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	share := &models.TodoShare{ProjectID: &project.ID}
+	if err := inviteUser(db, share, shareDTO, project.AuthorID, userIDUint); err != nil {
+		return nil, err
+	}
+
+	return reloadShare(db, share.ID)
+}
+
+// GetTodoShares lists the shares of a todo, for anybody with a role on it.
+func GetTodoShares(todoID string, userID string) ([]*dto.ShareResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert userID to uint
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, userIDUint, enums.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return listShares(db.Where("todo_id = ?", todo.ID))
+}
+
+// GetProjectShares lists the shares of a project, for anybody with a role on it.
+func GetProjectShares(projectID string, userID string) ([]*dto.ShareResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert userID to uint
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	projectIDUint, err := utils.ConvId(projectID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	project, err := findSharedProject(db, projectIDUint, userIDUint, enums.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return listShares(db.Where("project_id = ?", project.ID))
+}
+
+// RevokeTodoShare removes a share of a todo. Owners can revoke any share, the invited
+// user can leave on their own.
+func RevokeTodoShare(todoID string, shareID string, userID string) error {
+	db := database.GetDB()
+
+	// Convert userID to uint
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	shareIDUint, err := utils.ConvId(shareID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	var share models.TodoShare
+	err = db.Where("id = ? AND todo_id = ?", shareIDUint, todoIDUint).First(&share).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.ErrShareNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if share.UserID != userIDUint {
+		todo, err := findTodo(db, todoIDUint, userIDUint, enums.ShareRoleViewer)
+		if err != nil {
+			return err
+		}
+		if err := authorizeTodo(db, todo, userIDUint, enums.ShareRoleOwner); err != nil {
+			return dto.ErrUnauthShare
+		}
+	}
+
+	if err := db.Delete(&share).Error; err != nil {
+		return dto.ErrShareDelete
+	}
+	return nil
+}
+
+// RevokeProjectShare removes a share of a project. Owners can revoke any share, the
+// invited user can leave on their own.
+func RevokeProjectShare(projectID string, shareID string, userID string) error {
+	db := database.GetDB()
+
+	// Convert userID to uint
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	projectIDUint, err := utils.ConvId(projectID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	shareIDUint, err := utils.ConvId(shareID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	var share models.TodoShare
+	err = db.Where("id = ? AND project_id = ?", shareIDUint, projectIDUint).First(&share).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.ErrShareNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if share.UserID != userIDUint {
+		if _, err := findSharedProject(db, projectIDUint, userIDUint, enums.ShareRoleOwner); err != nil {
+			return err
+		}
+	}
+
+	if err := db.Delete(&share).Error; err != nil {
+		return dto.ErrShareDelete
+	}
+	return nil
+}
+
+// GetUserShares lists the shares the user was invited to, pending or accepted.
+func GetUserShares(userID string) ([]*dto.ShareResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert userID to uint
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	return listShares(db.Where("user_id = ?", userIDUint))
+}
+
+// AcceptShare accepts an invitation of the user, giving them its role from now on.
+func AcceptShare(shareID string, userID string) (*dto.ShareResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert userID to uint
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	shareIDUint, err := utils.ConvId(shareID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	share, err := findUserShare(db, shareIDUint, userIDUint)
+	if err != nil {
+		return nil, err
+	}
+
+	if share.AcceptedAt == nil {
+		now := time.Now()
+		share.AcceptedAt = &now
+		if err := db.Model(share).Update("accepted_at", now).Error; err != nil {
+			return nil, dto.ErrShareAccept
+		}
+	}
+
+	return reloadShare(db, share.ID)
+}
+
+// DeclineShare declines an invitation of the user, or leaves a share they accepted.
+func DeclineShare(shareID string, userID string) error {
+	db := database.GetDB()
+
+	// Convert userID to uint
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	shareIDUint, err := utils.ConvId(shareID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	share, err := findUserShare(db, shareIDUint, userIDUint)
+	if err != nil {
+		return err
+	}
+
+	if err := db.Delete(share).Error; err != nil {
+		return dto.ErrShareDelete
+	}
+	return nil
+}
+
+// GetSharedTodos lists the active todos of other users shared with the user, on their
+// own or through their project, with the role the user has on each of them.
+func GetSharedTodos(userID string) ([]*dto.SharedTodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert userID to uint
+	userIDUint, err := utils.ConvId(userID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	var shares []models.TodoShare
+	if err := db.Where("user_id = ? AND accepted_at IS NOT NULL", userIDUint).Find(&shares).Error; err != nil {
+		return nil, err
+	}
+	todoRoles := map[uint]enums.ShareRole{}
+	projectRoles := map[uint]enums.ShareRole{}
+	for _, share := range shares {
+		if share.TodoID != nil && share.Role.Allows(todoRoles[*share.TodoID]) {
+			todoRoles[*share.TodoID] = share.Role
+		}
+		if share.ProjectID != nil && share.Role.Allows(projectRoles[*share.ProjectID]) {
+			projectRoles[*share.ProjectID] = share.Role
+		}
+	}
+
+	todoDTOs := []*dto.SharedTodoResponseDTO{}
+	if len(shares) == 0 {
+		return todoDTOs, nil
+	}
+
+	todoIDs := make([]uint, 0, len(todoRoles))
+	for id := range todoRoles {
+		todoIDs = append(todoIDs, id)
+	}
+	projectIDs := make([]uint, 0, len(projectRoles))
+	for id := range projectRoles {
+		projectIDs = append(projectIDs, id)
+	}
+
+	var todos []models.Todo
+	err = db.Scopes(withTodoRelations).
+		Where("author_id <> ? AND (id IN ? OR project_id IN ?)", userIDUint, append(todoIDs, 0), append(projectIDs, 0)).
+		Order("author_id, position, id").Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range todos {
+		todo := &todos[i]
+		role := todoRoles[todo.ID]
+		if todo.ProjectID != nil && projectRoles[*todo.ProjectID].Allows(role) {
+			role = projectRoles[*todo.ProjectID]
+		}
+		todoDTOs = append(todoDTOs, &dto.SharedTodoResponseDTO{TodoResponseDTO: utils.ToTodoResponseDTO(todo), Role: role})
+	}
+
+	return todoDTOs, nil
+}
+
+// inviteUser saves the share of a todo or a project with the user named in the payload,
+// changing the role of the share that user already has, if any.
+func inviteUser(db *gorm.DB, share *models.TodoShare, shareDTO *dto.CreateShareDTO, ownerID uint, inviterID uint) error {
+	invitee, err := findUserByLogin(db, shareDTO.User)
+	if err != nil {
+		return err
+	}
+	if invitee.ID == ownerID {
+		return dto.ErrShareSelf
+	}
+
+	query := db.Where("user_id = ?", invitee.ID)
+	if share.TodoID != nil {
+		query = query.Where("todo_id = ?", *share.TodoID)
+	} else {
+		query = query.Where("project_id = ?", *share.ProjectID)
+	}
+	err = query.First(share).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	share.UserID = invitee.ID
+	share.InvitedByID = inviterID
+	share.Role = shareDTO.Role
+	if err := db.Save(share).Error; err != nil {
+		return dto.ErrShareCreate
+	}
+	return nil
+}
+
+// findSharedProject loads a project and checks that the user has at least a role on it.
+func findSharedProject(db *gorm.DB, projectID uint, userID uint, needed enums.ShareRole) (*models.Project, error) {
+	var project models.Project
+	err := db.Where("id = ?", projectID).First(&project).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := projectRole(db, &project, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, dto.ErrUnauthProject
+	}
+	if !role.Allows(needed) {
+		return nil, dto.ErrUnauthShare
+	}
+	return &project, nil
+}
+
+// findUserShare loads a share the user was invited to.
+func findUserShare(db *gorm.DB, shareID uint, userID uint) (*models.TodoShare, error) {
+	var share models.TodoShare
+	err := db.Where("id = ? AND user_id = ?", shareID, userID).First(&share).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrShareNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// listShares maps the shares a query selects to DTOs, oldest first.
+func listShares(query *gorm.DB) ([]*dto.ShareResponseDTO, error) {
+	var shares []models.TodoShare
+	if err := query.Scopes(withShareRelations).Order("id").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+
+	shareDTOs := make([]*dto.ShareResponseDTO, 0, len(shares))
+	for i := range shares {
+		shareDTOs = append(shareDTOs, utils.ToShareResponseDTO(&shares[i]))
+	}
+	return shareDTOs, nil
+}
+
+// reloadShare reads a share back with its relations for the response.
+func reloadShare(db *gorm.DB, shareID uint) (*dto.ShareResponseDTO, error) {
+	var share models.TodoShare
+	if err := db.Scopes(withShareRelations).First(&share, shareID).Error; err != nil {
+		return nil, err
+	}
+	return utils.ToShareResponseDTO(&share), nil
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+
+	"gorm.io/gorm"
+)
+
+// AddSubtask appends a subtask to a todo the user can edit.
+func AddSubtask(todoID string, subtaskDTO *dto.CreateSubtaskDTO, authorID string) (*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	subtask := &models.Subtask{
+		TodoID:   todo.ID,
+		Title:    subtaskDTO.Title,
+		Required: subtaskDTO.Required == nil || *subtaskDTO.Required,
+	}
+	if n := len(todo.Subtasks); n > 0 {
+		subtask.Position = todo.Subtasks[n-1].Position + 1
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(subtask).Error; err != nil {
+			return err
+		}
+		return reopenIfIncomplete(tx, todo, authorIDUint)
+	})
+	if err != nil {
+		return nil, dto.ErrSubtaskCreate
+	}
+
+	return reloadTodo(db, todo.ID)
+}
+
+// UpdateSubtask edits, checks or unchecks a subtask of a todo the user can edit.
+func UpdateSubtask(todoID string, subtaskID string, updateDTO *dto.UpdateSubtaskDTO, authorID string) (*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	subtaskIDUint, err := utils.ConvId(subtaskID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	subtask, err := findSubtask(todo, subtaskIDUint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update fields if provided
+	if updateDTO.Title != nil {
+		subtask.Title = *updateDTO.Title
+	}
+	if updateDTO.Required != nil {
+		subtask.Required = *updateDTO.Required
+	}
+	if updateDTO.Done != nil {
+		subtask.Done = *updateDTO.Done
+	}
+
+	if err := saveSubtask(db, todo, subtask, authorIDUint); err != nil {
+		return nil, err
+	}
+
+	return reloadTodo(db, todo.ID)
+}
+
+// ToggleSubtask flips the done flag of a subtask of a todo the user can edit.
+func ToggleSubtask(todoID string, subtaskID string, authorID string) (*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	subtaskIDUint, err := utils.ConvId(subtaskID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	subtask, err := findSubtask(todo, subtaskIDUint)
+	if err != nil {
+		return nil, err
+	}
+
+	subtask.Done = !subtask.Done
+	if err := saveSubtask(db, todo, subtask, authorIDUint); err != nil {
+		return nil, err
+	}
+
+	return reloadTodo(db, todo.ID)
+}
+
+// ReorderSubtasks moves the given subtasks to the top of the checklist in the given order,
+// the remaining subtasks keep their relative order after them.
+func ReorderSubtasks(todoID string, reorderDTO *dto.ReorderSubtasksDTO, authorID string) (*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Listed subtasks come first, in the requested order
+	var ordered []*models.Subtask
+	listed := map[uint]bool{}
+	for _, id := range uniqueIDs(reorderDTO.SubtaskIDs) {
+		subtask, err := findSubtask(todo, id)
+		if err != nil {
+			return nil, err
+		}
+		listed[id] = true
+		ordered = append(ordered, subtask)
+	}
+	for i := range todo.Subtasks {
+		if !listed[todo.Subtasks[i].ID] {
+			ordered = append(ordered, &todo.Subtasks[i])
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for position, subtask := range ordered {
+			if subtask.Position == position {
+				continue
+			}
+			if err := tx.Model(subtask).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, dto.ErrSubtaskUpdate
+	}
+
+	return reloadTodo(db, todo.ID)
+}
+
+// DeleteSubtask removes a subtask from a todo the user can edit.
+func DeleteSubtask(todoID string, subtaskID string, authorID string) error {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	subtaskIDUint, err := utils.ConvId(subtaskID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return err
+	}
+	subtask, err := findSubtask(todo, subtaskIDUint)
+	if err != nil {
+		return err
+	}
+
+	if err := db.Delete(subtask).Error; err != nil {
+		return dto.ErrSubtaskDelete
+	}
+
+	return nil
+}
+
+// findSubtask picks a subtask out of the preloaded checklist of a todo.
+func findSubtask(todo *models.Todo, subtaskID uint) (*models.Subtask, error) {
+	for i := range todo.Subtasks {
+		if todo.Subtasks[i].ID == subtaskID {
+			return &todo.Subtasks[i], nil
+		}
+	}
+	return nil, dto.ErrSubtaskNotFound
+}
+
+// saveSubtask persists a subtask and keeps the completion of its todo consistent.
+func saveSubtask(db *gorm.DB, todo *models.Todo, subtask *models.Subtask, actorID uint) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(subtask).Error; err != nil {
+			return err
+		}
+		return reopenIfIncomplete(tx, todo, actorID)
+	})
+	if err != nil {
+		return dto.ErrSubtaskUpdate
+	}
+	return nil
+}
+
+// reopenIfIncomplete moves a done todo back to the first doing status of its workflow
+// when one of its required subtasks is open, a todo is never done with open required
+// subtasks. The change is recorded in the history of the todo as made by the actor.
+func reopenIfIncomplete(tx *gorm.DB, todo *models.Todo, actorID uint) error {
+	if !todo.Done() {
+		return nil
+	}
+
+	var open int64
+	if err := tx.Model(&models.Subtask{}).Where("todo_id = ? AND required = ? AND done = ?", todo.ID, true, false).Count(&open).Error; err != nil {
+		return err
+	}
+	if open == 0 {
+		return nil
+	}
+
+	workflow, err := todoWorkflow(tx, todo)
+	if err != nil {
+		return err
+	}
+	previous := todo.Status
+	if err := setTodoStatus(tx, todo, workflow.FirstOf(enums.StatusCategoryDoing)); err != nil {
+		return err
+	}
+	return recordActivity(tx, todo.ID, &actorID, enums.TodoActivityUpdated, map[string]dto.FieldChangeDTO{
+		"status": {Old: previous, New: todo.Status},
+	})
+}
+
+// hasOpenRequiredSubtasks reports whether a required subtask is not done yet.
+func hasOpenRequiredSubtasks(subtasks []models.Subtask) bool {
+	for _, subtask := range subtasks {
+		if subtask.Required && !subtask.Done {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"errors"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// GetUserTags retrieves all tags of a user.
+func GetUserTags(authorID string) ([]*dto.TagResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	var tags []models.Tag
+	if err := db.Where("author_id = ?", authorIDUint).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	// Map to DTOs
+	var tagDTOs []*dto.TagResponseDTO
+	for _, tag := range tags {
+		tagDTOs = append(tagDTOs, utils.ToTagResponseDTO(&tag))
+	}
+
+	return tagDTOs, nil
+}
+
+// GetTagById retrieves a tag by its ID, ensuring it belongs to the author.
+func GetTagById(tagID string, authorID string) (*dto.TagResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	tagIDUint, err := utils.ConvId(tagID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	tag, err := findTag(db, tagIDUint, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.ToTagResponseDTO(tag), nil
+}
+
+// CreateTag adds a new tag with a unique name for each user.
+func CreateTag(tagDTO *dto.CreateTagDTO, authorID string) (*dto.TagResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	name := strings.TrimSpace(tagDTO.Name)
+	if name == "" {
+		return nil, dto.ErrTagNameEmpty
+	}
+	if err := checkTagName(db, name, authorIDUint, 0); err != nil {
+		return nil, err
+	}
+
+	tag := &models.Tag{
+		Name:     name,
+		Color:    tagDTO.Color,
+		AuthorID: authorIDUint,
+	}
+
+	if err := db.Create(tag).Error; err != nil {
+		return nil, dto.ErrTagCreate
+	}
+
+	return utils.ToTagResponseDTO(tag), nil
+}
+
+// UpdateTag renames or recolors a tag, ensuring it belongs to the author.
+func UpdateTag(tagID string, updateDTO *dto.UpdateTagDTO, authorID string) (*dto.TagResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	tagIDUint, err := utils.ConvId(tagID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	tag, err := findTag(db, tagIDUint, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update fields if provided
+	if updateDTO.Name != nil {
+		name := strings.TrimSpace(*updateDTO.Name)
+		if name == "" {
+			return nil, dto.ErrTagNameEmpty
+		}
+		if err := checkTagName(db, name, authorIDUint, tag.ID); err != nil {
+			return nil, err
+		}
+		tag.Name = name
+	}
+	if updateDTO.Color != nil {
+		tag.Color = *updateDTO.Color
+	}
+
+	if err := db.Save(tag).Error; err != nil {
+		return nil, dto.ErrTagUpdate
+	}
+
+	return utils.ToTagResponseDTO(tag), nil
+}
+
+// DeleteTag removes a tag and detaches it from every todo.
+func DeleteTag(tagID string, authorID string) error {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	tagIDUint, err := utils.ConvId(tagID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	tag, err := findTag(db, tagIDUint, authorIDUint)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM todo_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM template_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(tag).Error
+	})
+	if err != nil {
+		return dto.ErrTagDelete
+	}
+
+	return nil
+}
+
+// findTag loads a tag by ID and checks that the author owns it.
+func findTag(db *gorm.DB, tagID uint, authorID uint) (*models.Tag, error) {
+	var tag models.Tag
+	err := db.Where("id = ?", tagID).First(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrTagNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if the author owns the tag
+	if tag.AuthorID != authorID {
+		return nil, dto.ErrUnauthTag
+	}
+
+	return &tag, nil
+}
+
+// checkTagName ensures no other tag of the author already uses the name.
+func checkTagName(db *gorm.DB, name string, authorID uint, excludeID uint) error {
+	var existingTag models.Tag
+	err := db.Where("name = ? AND author_id = ? AND id <> ?", name, authorID, excludeID).First(&existingTag).Error
+	if err == nil {
+		return dto.ErrTagNameAlreadyExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// findUserTags loads the tags with the given IDs, ensuring they all belong to the author.
+func findUserTags(db *gorm.DB, tagIDs []uint, authorID uint) ([]models.Tag, error) {
+	tagIDs = uniqueIDs(tagIDs)
+	if len(tagIDs) == 0 {
+		return nil, nil
+	}
+
+	var tags []models.Tag
+	if err := db.Where("id IN ? AND author_id = ?", tagIDs, authorID).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	if len(tags) != len(tagIDs) {
+		return nil, dto.ErrTagNotFound
+	}
+
+	return tags, nil
+}
+
+// filterByTags restricts a todo query to todos carrying the given tag names. The names are
+// matched against the tags of each todo's author, the only ones a todo can carry, so a
+// shared or assigned todo is filtered by its author's tags, not the reader's.
+// With matchAll every tag must be present, otherwise any of them is enough.
+func filterByTags(names []string, matchAll bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		sub := db.Session(&gorm.Session{NewDB: true}).
+			Table("todo_tags").
+			Select("todo_tags.todo_id").
+			Joins("JOIN tags ON tags.id = todo_tags.tag_id").
+			Where("tags.author_id = todos.author_id AND tags.name IN ?", names)
+		if matchAll {
+			sub = sub.Group("todo_tags.todo_id").Having("COUNT(DISTINCT tags.id) = ?", len(names))
+		}
+		return db.Where("todos.id IN (?)", sub)
+	}
+}
+
+// splitTagNames parses a comma separated list of tag names, dropping blanks and duplicates.
+func splitTagNames(raw string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// uniqueIDs drops duplicated IDs while keeping their order.
+func uniqueIDs(ids []uint) []uint {
+	var unique []uint
+	seen := map[uint]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"errors"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// withTemplateRelations preloads the tags and the subtasks, in order, of a template.
+func withTemplateRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Tags").Preload("Subtasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position, id")
+	})
+}
+
+// GetTemplates lists the templates of the user by name.
+func GetTemplates(authorID string) ([]*dto.TemplateResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	var templates []models.Template
+	if err := db.Scopes(withTemplateRelations).Where("author_id = ?", authorIDUint).Order("name, id").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+
+	templateDTOs := make([]*dto.TemplateResponseDTO, 0, len(templates))
+	for i := range templates {
+		templateDTOs = append(templateDTOs, utils.ToTemplateResponseDTO(&templates[i]))
+	}
+	return templateDTOs, nil
+}
+
+// GetTemplateById retrieves a template of the user by its ID.
+func GetTemplateById(templateID string, authorID string) (*dto.TemplateResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	templateIDUint, err := utils.ConvId(templateID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	template, err := findTemplate(db, templateIDUint, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+	return utils.ToTemplateResponseDTO(template), nil
+}
+
+// CreateTemplate adds a template for the user.
+func CreateTemplate(templateDTO *dto.TemplateDTO, authorID string) (*dto.TemplateResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	template := &models.Template{AuthorID: authorIDUint}
+	if err := fillTemplate(db, template, templateDTO); err != nil {
+		return nil, err
+	}
+	if err := db.Omit("Tags.*").Create(template).Error; err != nil {
+		return nil, dto.ErrTemplateCreate
+	}
+
+	return reloadTemplate(db, template.ID)
+}
+
+// SaveTodoAsTemplate saves a todo the user can see as a template, with its subtasks and tags.
+// Its dates become offsets from the moment it was created. The project and tags of a todo
+// shared with the user belong to its author, so the template of such a todo goes without them.
+func SaveTodoAsTemplate(todoID string, saveDTO *dto.SaveAsTemplateDTO, authorID string) (*dto.TemplateResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	// Reading a todo is enough to copy it
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	if todo.AuthorID != authorIDUint {
+		todo.ProjectID, todo.Tags = nil, nil
+	}
+
+	template := &models.Template{
+		Name:        saveDTO.Name,
+		Title:       todo.Title,
+		Description: todo.Description,
+		Priority:    todo.Priority,
+		ProjectID:   todo.ProjectID,
+		StartOffset: templateOffset(todo.CreatedAt, todo.StartAt),
+		DueOffset:   templateOffset(todo.CreatedAt, todo.DueAt),
+		AuthorID:    authorIDUint,
+		Tags:        todo.Tags,
+	}
+	for _, subtask := range todo.Subtasks {
+		template.Subtasks = append(template.Subtasks, models.TemplateSubtask{
+			Title:    subtask.Title,
+			Required: subtask.Required,
+			Position: subtask.Position,
+		})
+	}
+	if err := db.Omit("Tags.*").Create(template).Error; err != nil {
+		return nil, dto.ErrTemplateCreate
+	}
+
+	return reloadTemplate(db, template.ID)
+}
+
+// UpdateTemplate replaces the content of a template of the user, subtasks and tags included.
+func UpdateTemplate(templateID string, templateDTO *dto.TemplateDTO, authorID string) (*dto.TemplateResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	templateIDUint, err := utils.ConvId(templateID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	template, err := findTemplate(db, templateIDUint, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+	if err := fillTemplate(db, template, templateDTO); err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tags", "Subtasks").Save(template).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(template).Association("Tags").Replace(template.Tags); err != nil {
+			return err
+		}
+		if err := tx.Where("template_id = ?", template.ID).Delete(&models.TemplateSubtask{}).Error; err != nil {
+			return err
+		}
+		if len(template.Subtasks) == 0 {
+			return nil
+		}
+		for i := range template.Subtasks {
+			template.Subtasks[i].TemplateID = template.ID
+		}
+		return tx.Create(&template.Subtasks).Error
+	})
+	if err != nil {
+		return nil, dto.ErrTemplateUpdate
+	}
+
+	return reloadTemplate(db, template.ID)
+}
+
+// DeleteTemplate removes a template of the user. The todos created from it stay.
+func DeleteTemplate(templateID string, authorID string) error {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	templateIDUint, err := utils.ConvId(templateID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	template, err := findTemplate(db, templateIDUint, authorIDUint)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(template).Association("Tags").Clear(); err != nil {
+			return err
+		}
+		return tx.Select("Subtasks").Delete(template).Error
+	})
+	if err != nil {
+		return dto.ErrTemplateDelete
+	}
+	return nil
+}
+
+// InstantiateTemplate creates a todo of the user from a template. Its variables take the
+// given values, its dates are its offsets from the given moment, and the todo follows the
+// same rules as one created by hand: a title already in use fails unless it is renamed.
+func InstantiateTemplate(templateID string, instantiateDTO *dto.InstantiateTemplateDTO, authorID string) (*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	templateIDUint, err := utils.ConvId(templateID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	template, err := findTemplate(db, templateIDUint, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every variable needs a value, a todo is never created with {{placeholders}} left
+	for _, name := range utils.TemplateVariables(template.Title, template.Description) {
+		if _, ok := instantiateDTO.Variables[name]; !ok {
+			return nil, dto.ErrTemplateVariables
+		}
+	}
+	title := strings.TrimSpace(utils.FillTemplate(template.Title, instantiateDTO.Variables))
+	if title == "" {
+		return nil, dto.ErrTemplateTitle
+	}
+
+	from := time.Now().UTC()
+	if instantiateDTO.From != nil {
+		from = instantiateDTO.From.UTC()
+	}
+	todoDTO := dto.CreateTodoDTO{
+		Title:       title,
+		Description: utils.FillTemplate(template.Description, instantiateDTO.Variables),
+		Priority:    template.Priority,
+		ProjectID:   template.ProjectID,
+		StartAt:     offsetDate(from, template.StartOffset),
+		DueAt:       offsetDate(from, template.DueOffset),
+	}
+	if instantiateDTO.ProjectID != nil {
+		todoDTO.ProjectID = nil
+		if *instantiateDTO.ProjectID != 0 {
+			todoDTO.ProjectID = instantiateDTO.ProjectID
+		}
+	}
+	for _, tag := range template.Tags {
+		todoDTO.TagIDs = append(todoDTO.TagIDs, tag.ID)
+	}
+
+	var todo *models.Todo
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if instantiateDTO.OnConflict == "rename" {
+			title, err := availableTodoTitle(tx, todoDTO.Title, authorIDUint)
+			if err != nil {
+				return err
+			}
+			todoDTO.Title = title
+		}
+
+		todo, err = createTodo(tx, &todoDTO, authorIDUint)
+		if err != nil {
+			return err
+		}
+		if len(template.Subtasks) == 0 {
+			return nil
+		}
+		subtasks := make([]models.Subtask, 0, len(template.Subtasks))
+		for i, subtask := range template.Subtasks {
+			subtasks = append(subtasks, models.Subtask{
+				TodoID:   todo.ID,
+				Title:    subtask.Title,
+				Required: subtask.Required,
+				Position: i,
+			})
+		}
+		return tx.Create(&subtasks).Error
+	})
+	if errors.Is(err, dto.ErrToDoTitleAlreadyExists) || errors.Is(err, dto.ErrToDoDueBeforeStart) || errors.Is(err, dto.ErrTagNotFound) ||
+		errors.Is(err, dto.ErrProjectNotFound) || errors.Is(err, dto.ErrUnauthProject) || errors.Is(err, dto.ErrProjectArchived) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, dto.ErrToDoCreate
+	}
+
+	return reloadTodo(db, todo.ID)
+}
+
+// fillTemplate checks a template payload and sets the content of a template from it.
+func fillTemplate(db *gorm.DB, template *models.Template, templateDTO *dto.TemplateDTO) error {
+	if err := checkTemplateOffsets(templateDTO.StartOffset, templateDTO.DueOffset); err != nil {
+		return err
+	}
+
+	// Make sure every requested tag belongs to the user
+	tags, err := findUserTags(db, templateDTO.TagIDs, template.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the project belongs to the user, archived ones are only checked on instantiation
+	if templateDTO.ProjectID != nil {
+		if _, err := findProject(db, *templateDTO.ProjectID, template.AuthorID); err != nil {
+			return err
+		}
+	}
+
+	template.Name = templateDTO.Name
+	template.Title = templateDTO.Title
+	template.Description = templateDTO.Description
+	template.Priority = templateDTO.Priority
+	if template.Priority == "" {
+		template.Priority = enums.TodoPriorityNone
+	}
+	template.ProjectID = templateDTO.ProjectID
+	template.StartOffset = templateDTO.StartOffset
+	template.DueOffset = templateDTO.DueOffset
+	template.Tags = tags
+	template.Subtasks = nil
+	for i, subtask := range templateDTO.Subtasks {
+		template.Subtasks = append(template.Subtasks, models.TemplateSubtask{
+			Title:    subtask.Title,
+			Required: subtask.Required == nil || *subtask.Required,
+			Position: i,
+		})
+	}
+	return nil
+}
+
+// checkTemplateOffsets makes sure the todos of a template are due after they start.
+func checkTemplateOffsets(startOffset *int64, dueOffset *int64) error {
+	if startOffset != nil && dueOffset != nil && *dueOffset < *startOffset {
+		return dto.ErrToDoDueBeforeStart
+	}
+	return nil
+}
+
+// templateOffset returns the seconds between the creation of a todo and one of its dates.
+func templateOffset(createdAt time.Time, date *time.Time) *int64 {
+	if date == nil {
+		return nil
+	}
+	offset := int64(date.Sub(createdAt).Round(time.Second) / time.Second)
+	return &offset
+}
+
+// offsetDate returns the date an offset of a template points to, from the moment it is instantiated.
+func offsetDate(from time.Time, offset *int64) *time.Time {
+	if offset == nil {
+		return nil
+	}
+	date := from.Add(time.Duration(*offset) * time.Second)
+	return &date
+}
+
+// findTemplate loads a template by ID, with its tags and subtasks, and checks that the author owns it.
+func findTemplate(db *gorm.DB, templateID uint, authorID uint) (*models.Template, error) {
+	var template models.Template
+	err := db.Scopes(withTemplateRelations).Where("id = ?", templateID).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if template.AuthorID != authorID {
+		return nil, dto.ErrUnauthTemplate
+	}
+	return &template, nil
+}
+
+// reloadTemplate reads a template back with its tags and subtasks for the response.
+func reloadTemplate(db *gorm.DB, templateID uint) (*dto.TemplateResponseDTO, error) {
+	var template models.Template
+	if err := db.Scopes(withTemplateRelations).First(&template, templateID).Error; err != nil {
+		return nil, err
+	}
+	return utils.ToTemplateResponseDTO(&template), nil
+}
@@ -0,0 +1,435 @@
+package services
+
+import (
+	"errors"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StartTimer starts a timer on a todo the user can edit. A user has a single running timer.
+func StartTimer(todoID string, authorID string) (*dto.TimeEntryResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := runningTimer(db, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+	if running != nil {
+		return nil, dto.ErrTimerRunning
+	}
+
+	entry := &models.TimeEntry{TodoID: todo.ID, UserID: authorIDUint, StartedAt: time.Now().UTC()}
+	if err := db.Create(entry).Error; err != nil {
+		// A timer started at the same time hit the unique index
+		if running, _ := runningTimer(db, authorIDUint); running != nil {
+			return nil, dto.ErrTimerRunning
+		}
+		return nil, dto.ErrTimeEntryCreate
+	}
+
+	return reloadTimeEntry(db, entry.ID)
+}
+
+// StopTimer stops the timer the user runs on a todo and adds its time to the todo.
+func StopTimer(todoID string, authorID string) (*dto.TimeEntryResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	running, err := runningTimer(db, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+	if running == nil || running.TodoID != todoIDUint {
+		return nil, dto.ErrTimerNotRunning
+	}
+
+	endedAt := time.Now().UTC()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Only the request that ends the timer counts its time
+		result := tx.Model(&models.TimeEntry{}).Where("id = ? AND ended_at IS NULL", running.ID).Update("ended_at", endedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return dto.ErrTimerNotRunning
+		}
+		running.EndedAt = &endedAt
+		return addTrackedTime(tx, running.TodoID, running.Seconds())
+	})
+	if errors.Is(err, dto.ErrTimerNotRunning) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, dto.ErrTimeEntryUpdate
+	}
+
+	return reloadTimeEntry(db, running.ID)
+}
+
+// GetTimeEntries lists the time entries of every user on a todo the user can see, latest first.
+func GetTimeEntries(todoID string, authorID string) ([]*dto.TimeEntryResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []models.TimeEntry
+	if err := db.Preload("User").Where("todo_id = ?", todo.ID).Order("started_at DESC, id DESC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	entryDTOs := make([]*dto.TimeEntryResponseDTO, 0, len(entries))
+	for i := range entries {
+		entryDTOs = append(entryDTOs, utils.ToTimeEntryResponseDTO(&entries[i]))
+	}
+
+	return entryDTOs, nil
+}
+
+// CreateTimeEntry records time the user spent on a todo they can edit.
+func CreateTimeEntry(todoID string, entryDTO *dto.CreateTimeEntryDTO, authorID string) (*dto.TimeEntryResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todo, err := findTodo(db, todoIDUint, authorIDUint, enums.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	endedAt := entryDTO.EndedAt.UTC()
+	entry := &models.TimeEntry{
+		TodoID:    todo.ID,
+		UserID:    authorIDUint,
+		StartedAt: entryDTO.StartedAt.UTC(),
+		EndedAt:   &endedAt,
+		Note:      entryDTO.Note,
+	}
+	if err := checkTimeEntryDates(entry); err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		return addTrackedTime(tx, todo.ID, entry.Seconds())
+	})
+	if err != nil {
+		return nil, dto.ErrTimeEntryCreate
+	}
+
+	return reloadTimeEntry(db, entry.ID)
+}
+
+// UpdateTimeEntry corrects a time entry of the user on a todo they can see.
+func UpdateTimeEntry(todoID string, entryID string, updateDTO *dto.UpdateTimeEntryDTO, authorID string) (*dto.TimeEntryResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	entryIDUint, err := utils.ConvId(entryID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	entry, err := findTimeEntry(db, todoIDUint, entryIDUint, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+
+	before := entry.Seconds()
+	if updateDTO.StartedAt != nil {
+		entry.StartedAt = updateDTO.StartedAt.UTC()
+	}
+	if updateDTO.EndedAt != nil {
+		endedAt := updateDTO.EndedAt.UTC()
+		entry.EndedAt = &endedAt
+	}
+	if updateDTO.Note != nil {
+		entry.Note = *updateDTO.Note
+	}
+	if err := checkTimeEntryDates(entry); err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Todo", "User").Save(entry).Error; err != nil {
+			return err
+		}
+		return addTrackedTime(tx, entry.TodoID, entry.Seconds()-before)
+	})
+	if err != nil {
+		return nil, dto.ErrTimeEntryUpdate
+	}
+
+	return reloadTimeEntry(db, entry.ID)
+}
+
+// DeleteTimeEntry removes a time entry of the user and its time from the todo.
+func DeleteTimeEntry(todoID string, entryID string, authorID string) error {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	entryIDUint, err := utils.ConvId(entryID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	entry, err := findTimeEntry(db, todoIDUint, entryIDUint, authorIDUint)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(entry).Error; err != nil {
+			return err
+		}
+		return addTrackedTime(tx, entry.TodoID, -entry.Seconds())
+	})
+	if err != nil {
+		return dto.ErrTimeEntryDelete
+	}
+
+	return nil
+}
+
+// GetTimeReport sums up the time the user spent on todos over a range of dates, by day,
+// project or tag. A todo with several tags counts in each of them.
+func GetTimeReport(query *dto.TimeReportQueryDTO, authorID string) (*dto.TimeReportDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	loc, err := loadLocation(query.TZ)
+	if err != nil {
+		return nil, err
+	}
+	groupBy := query.GroupBy
+	if groupBy == "" {
+		groupBy = "day"
+	}
+
+	// Time spent on todos trashed since then still counts
+	var entries []models.TimeEntry
+	err = db.Preload("Todo", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Todo.Tags").
+		Where("user_id = ? AND ended_at IS NOT NULL AND started_at >= ? AND started_at < ?", authorIDUint, query.From.UTC(), query.To.UTC()).
+		Order("started_at, id").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	projectNames, err := reportProjectNames(db, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.TimeReportDTO{
+		From:    query.From,
+		To:      query.To,
+		GroupBy: groupBy,
+		Rows:    []*dto.TimeReportRowDTO{},
+	}
+	rows := map[string]*dto.TimeReportRowDTO{}
+	add := func(key string, name string, seconds int64) {
+		row, ok := rows[key]
+		if !ok {
+			row = &dto.TimeReportRowDTO{Key: key, Name: name}
+			rows[key] = row
+			report.Rows = append(report.Rows, row)
+		}
+		row.Entries++
+		row.Seconds += seconds
+	}
+	for _, entry := range entries {
+		seconds := entry.Seconds()
+		report.TotalSeconds += seconds
+		switch groupBy {
+		case "project":
+			if entry.Todo.ProjectID == nil {
+				add("", "", seconds)
+			} else {
+				add(strconv.FormatUint(uint64(*entry.Todo.ProjectID), 10), projectNames[*entry.Todo.ProjectID], seconds)
+			}
+		case "tag":
+			if len(entry.Todo.Tags) == 0 {
+				add("", "", seconds)
+			}
+			for _, tag := range entry.Todo.Tags {
+				add(strconv.FormatUint(uint64(tag.ID), 10), tag.Name, seconds)
+			}
+		default:
+			day := entry.StartedAt.In(loc).Format(time.DateOnly)
+			add(day, day, seconds)
+		}
+	}
+
+	// Days are already in order, projects and tags go by name with the rest last
+	if groupBy != "day" {
+		sort.SliceStable(report.Rows, func(i, j int) bool {
+			a, b := report.Rows[i], report.Rows[j]
+			if (a.Key == "") != (b.Key == "") {
+				return b.Key == ""
+			}
+			return a.Name < b.Name
+		})
+	}
+
+	return report, nil
+}
+
+// runningTimer loads the timer a user runs, nil when there is none.
+func runningTimer(db *gorm.DB, userID uint) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	if err := db.Where("user_id = ? AND ended_at IS NULL", userID).Limit(1).Find(&entry).Error; err != nil {
+		return nil, err
+	}
+	if entry.ID == 0 {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// findTimeEntry loads a time entry of a todo and checks that it is the user's.
+func findTimeEntry(db *gorm.DB, todoID uint, entryID uint, userID uint) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	err := db.Where("id = ? AND todo_id = ?", entryID, todoID).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrTimeEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Users only change the time they tracked themselves
+	if entry.UserID != userID {
+		return nil, dto.ErrUnauthTimeEntry
+	}
+
+	return &entry, nil
+}
+
+// reloadTimeEntry reads a time entry back with its user, as the API shows it.
+func reloadTimeEntry(db *gorm.DB, entryID uint) (*dto.TimeEntryResponseDTO, error) {
+	var entry models.TimeEntry
+	if err := db.Preload("User").First(&entry, entryID).Error; err != nil {
+		return nil, err
+	}
+	return utils.ToTimeEntryResponseDTO(&entry), nil
+}
+
+// checkTimeEntryDates ensures a time entry doesn't start or end in the future, nor end
+// before it starts.
+func checkTimeEntryDates(entry *models.TimeEntry) error {
+	now := time.Now()
+	if entry.StartedAt.After(now) {
+		return dto.ErrTimeEntryDates
+	}
+	if entry.EndedAt != nil && (!entry.EndedAt.After(entry.StartedAt) || entry.EndedAt.After(now)) {
+		return dto.ErrTimeEntryDates
+	}
+	return nil
+}
+
+// addTrackedTime adds the time of finished entries to the total of a todo, or removes it.
+func addTrackedTime(tx *gorm.DB, todoID uint, seconds int64) error {
+	if seconds == 0 {
+		return nil
+	}
+	return tx.Unscoped().Model(&models.Todo{}).Where("id = ?", todoID).UpdateColumn("tracked_seconds", gorm.Expr("tracked_seconds + ?", seconds)).Error
+}
+
+// reportProjectNames maps the projects of the todos of time entries to their names.
+func reportProjectNames(db *gorm.DB, entries []models.TimeEntry) (map[uint]string, error) {
+	var ids []uint
+	for _, entry := range entries {
+		if entry.Todo.ProjectID != nil {
+			ids = append(ids, *entry.Todo.ProjectID)
+		}
+	}
+	names := map[uint]string{}
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	var projects []models.Project
+	if err := db.Select("id", "name").Where("id IN ?", uniqueIDs(ids)).Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	for _, project := range projects {
+		names[project.ID] = project.Name
+	}
+	return names, nil
+}
@@ -4,73 +4,22 @@ import (
 	"errors"
 	"go-feToDo/database"
 	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
 	"go-feToDo/models"
 	"go-feToDo/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// Get All ToDos of a user
-func GetUserAllToDo(authorID string) ([]*dto.TodoResponseDTO, error) {
-	db := database.GetDB()
-
-	// Convert authorID from string to uint
-	authorIDUint, err := utils.ConvId(authorID)
-	if err != nil {
-		return nil, dto.ErrAuthIdConv
-	}
-
-	var todos []models.Todo
-
-	// Query to fetch all to-dos belonging to the specified author
-	err = db.Unscoped().Where("author_id = ?", authorIDUint).Find(&todos).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, dto.ErrToDoNotFound
-		}
-		return nil, err
-	}
-
-	// Convert todos to DTOs
-	var todoDTOs []*dto.TodoResponseDTO
-	for _, todo := range todos {
-		todoDTOs = append(todoDTOs, utils.ToTodoResponseDTO(&todo))
-	}
-
-	return todoDTOs, nil
-}
-
-// Get Active ToDos of a user
-func GetUserActiveToDo(authorID string) ([]*dto.TodoResponseDTO, error) {
-	db := database.GetDB()
-
-	// Convert authorID from string to uint
-	authorIDUint, err := utils.ConvId(authorID)
-	if err != nil {
-		return nil, dto.ErrAuthIdConv
-	}
-
-	var todos []models.Todo
-
-	// Query to fetch all to-dos belonging to the specified author
-	err = db.Where("author_id = ? AND deleted_at IS NULL", authorIDUint).Find(&todos).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, dto.ErrToDoNotFound
-		}
-		return nil, err
-	}
-
-	// Convert todos to DTOs
-	var todoDTOs []*dto.TodoResponseDTO
-	for _, todo := range todos {
-		todoDTOs = append(todoDTOs, utils.ToTodoResponseDTO(&todo))
-	}
-
-	return todoDTOs, nil
+// withTodoRelations preloads everything a TodoResponseDTO is built from.
+func withTodoRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Tags").Preload("Recurrence").Preload("Blockers").Preload("Subtasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position, id")
+	})
 }
 
-// GetTodoById retrieves a todo by its ID, ensuring it belongs to the author.
+// GetTodoById retrieves a todo by its ID, ensuring it is the user's or shared with them.
 func GetTodoById(todoID string, authorID string) (*dto.TodoResponseDTO, error) {
 	db := database.GetDB()
 
@@ -85,7 +34,7 @@ func GetTodoById(todoID string, authorID string) (*dto.TodoResponseDTO, error) {
 	}
 
 	var todo models.Todo
-	err = db.Where("id = ?", todoIDUint).First(&todo).Error
+	err = db.Scopes(withTodoRelations).Where("id = ?", todoIDUint).First(&todo).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, dto.ErrToDoNotFound
 	}
@@ -93,16 +42,16 @@ func GetTodoById(todoID string, authorID string) (*dto.TodoResponseDTO, error) {
 		return nil, err
 	}
 
-	// Check if the author owns the todo
-	if todo.AuthorID != authorIDUint {
-		return nil, dto.ErrUnauthToDo
+	// Check if the todo is the user's or shared with them
+	if err := authorizeTodo(db, &todo, authorIDUint, enums.ShareRoleViewer); err != nil {
+		return nil, err
 	}
 
 	return utils.ToTodoResponseDTO(&todo), nil
 }
 
-// GetTrashedTodos retrieves all soft-deleted todos for the author.
-func GetTrashedTodos(authorID string) ([]*dto.TodoResponseDTO, error) {
+// CreateTodo adds a new todo with a unique title for each user.
+func CreateTodo(todoDTO *dto.CreateTodoDTO, authorID string) (*dto.TodoResponseDTO, error) {
 	db := database.GetDB()
 
 	// Convert authorID to uint
@@ -111,33 +60,19 @@ func GetTrashedTodos(authorID string) ([]*dto.TodoResponseDTO, error) {
 		return nil, dto.ErrAuthIdConv
 	}
 
-	var todos []models.Todo
-	if err := db.Unscoped().Where("author_id = ? AND deleted_at IS NOT NULL", authorIDUint).Find(&todos).Error; err != nil {
-		return nil, dto.ErrToDoTrash
-	}
-
-	// Map to DTOs
-	var todoDTOs []*dto.TodoResponseDTO
-	for _, todo := range todos {
-		todoDTOs = append(todoDTOs, utils.ToTodoResponseDTO(&todo))
+	todo, err := createTodo(db, todoDTO, authorIDUint)
+	if err != nil {
+		return nil, err
 	}
 
-	return todoDTOs, nil
+	return utils.ToTodoResponseDTO(todo), nil
 }
 
-// CreateTodo adds a new todo with a unique title for each user.
-func CreateTodo(todoDTO *dto.CreateTodoDTO, authorID string) (*dto.TodoResponseDTO, error) {
-	db := database.GetDB()
-
-	// Convert authorID to uint
-	authorIDUint, err := utils.ConvId(authorID)
-	if err != nil {
-		return nil, dto.ErrAuthIdConv
-	}
-
+// createTodo checks a todo payload and inserts the todo at the end of the author's list.
+func createTodo(db *gorm.DB, todoDTO *dto.CreateTodoDTO, authorID uint) (*models.Todo, error) {
 	// Check if a todo with the same title already exists for the user
 	var existingTodo models.Todo
-	err = db.Where("title = ? AND author_id = ?", todoDTO.Title, authorIDUint).First(&existingTodo).Error
+	err := db.Where("title = ? AND author_id = ?", todoDTO.Title, authorID).First(&existingTodo).Error
 	if err == nil {
 		// If an existing to-do is found, return an error indicating the title is not unique
 		return nil, dto.ErrToDoTitleAlreadyExists
@@ -147,21 +82,75 @@ func CreateTodo(todoDTO *dto.CreateTodoDTO, authorID string) (*dto.TodoResponseD
 		return nil, err
 	}
 
+	if err := checkTodoDates(todoDTO.StartAt, todoDTO.DueAt); err != nil {
+		return nil, err
+	}
+
+	// Make sure every requested tag belongs to the user
+	tags, err := findUserTags(db, todoDTO.TagIDs, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the project belongs to the user and is not archived
+	if todoDTO.ProjectID != nil {
+		if _, err := findActiveProject(db, *todoDTO.ProjectID, authorID); err != nil {
+			return nil, err
+		}
+	}
+
+	// New todos go at the end of the list
+	position, err := nextTodoPosition(db, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// New todos start in the first status of their workflow
+	workflow, err := workflowOf(db, authorID, todoDTO.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	initial := workflow.Initial()
+
 	// Proceed with creating the new to-do
 	todo := &models.Todo{
-		Title:       todoDTO.Title,
-		Description: todoDTO.Description,
-		AuthorID:    authorIDUint,
+		Title:          todoDTO.Title,
+		Description:    todoDTO.Description,
+		Status:         initial.Name,
+		StatusCategory: initial.Category,
+		Priority:       todoDTO.Priority,
+		Position:       position,
+		AuthorID:       authorID,
+		ProjectID:      todoDTO.ProjectID,
+		StartAt:        todoDTO.StartAt,
+		DueAt:          todoDTO.DueAt,
+		Tags:           tags,
+	}
+
+	// A recurring todo is the first occurrence of its series
+	if todoDTO.Recurrence != nil {
+		series, err := newRecurrence(todoDTO.Recurrence, todoDTO.Title, todoDTO.DueAt, authorID)
+		if err != nil {
+			return nil, err
+		}
+		todo.Recurrence = series
+		todo.OccurrenceAt = todoDTO.DueAt
 	}
 
-	if err := db.Create(todo).Error; err != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tags.*").Create(todo).Error; err != nil {
+			return err
+		}
+		return recordActivity(tx, todo.ID, &authorID, enums.TodoActivityCreated, diffSnapshots(nil, todoSnapshot(todo)))
+	})
+	if err != nil {
 		return nil, dto.ErrToDoCreate
 	}
 
-	return utils.ToTodoResponseDTO(todo), nil
+	return todo, nil
 }
 
-// UpdateTodo updates an existing todo, ensuring it belongs to the author.
+// UpdateTodo updates an existing todo, ensuring the user owns it or can edit it.
 func UpdateTodo(todoID string, updateDTO *dto.UpdateTodoDTO, authorID string) (*dto.TodoResponseDTO, error) {
 	db := database.GetDB()
 
@@ -176,7 +165,7 @@ func UpdateTodo(todoID string, updateDTO *dto.UpdateTodoDTO, authorID string) (*
 	}
 
 	var todo models.Todo
-	err = db.Where("id = ?", todoIDUint).First(&todo).Error
+	err = db.Scopes(withTodoRelations).Where("id = ?", todoIDUint).First(&todo).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, dto.ErrToDoNotFound
 	}
@@ -184,9 +173,25 @@ func UpdateTodo(todoID string, updateDTO *dto.UpdateTodoDTO, authorID string) (*
 		return nil, err
 	}
 
-	// Check if the author owns the todo
-	if todo.AuthorID != authorIDUint {
-		return nil, dto.ErrUnauthToDo
+	// Editors of a shared todo can update it too
+	if err := authorizeTodo(db, &todo, authorIDUint, enums.ShareRoleEditor); err != nil {
+		return nil, err
+	}
+
+	wasDone := todo.Done()
+	before := todoSnapshot(&todo)
+	previousProjectID := todo.ProjectID
+	previousStatus := todo.Status
+	previousTitle := todo.Title
+
+	// Make sure every tag to attach or detach belongs to the author of the todo
+	addTags, err := findUserTags(db, updateDTO.AddTagIDs, todo.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	removeTags, err := findUserTags(db, updateDTO.RemoveTagIDs, todo.AuthorID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Update fields if provided
@@ -196,11 +201,123 @@ func UpdateTodo(todoID string, updateDTO *dto.UpdateTodoDTO, authorID string) (*
 	if updateDTO.Description != nil {
 		todo.Description = *updateDTO.Description
 	}
+	if updateDTO.Priority != nil {
+		todo.Priority = *updateDTO.Priority
+	}
+	if updateDTO.ProjectID != nil {
+		if *updateDTO.ProjectID == 0 {
+			todo.ProjectID = nil
+		} else {
+			project, err := findActiveProject(db, *updateDTO.ProjectID, todo.AuthorID)
+			if err != nil {
+				return nil, err
+			}
+			todo.ProjectID = &project.ID
+		}
+	}
+
+	// A todo moving to a project with another workflow keeps its status if the workflow has
+	// it, then the status changes follow the transitions of the workflow
+	workflow, err := todoWorkflow(db, &todo)
+	if err != nil {
+		return nil, err
+	}
+	if !equalIDs(previousProjectID, todo.ProjectID) {
+		status := fitStatus(workflow, todo.Status, todo.StatusCategory)
+		todo.Status, todo.StatusCategory = status.Name, status.Category
+	}
 	if updateDTO.Status != nil {
-		todo.Status = *updateDTO.Status
+		status, err := checkStatusChange(workflow, todo.Status, *updateDTO.Status)
+		if err != nil {
+			return nil, err
+		}
+		// A todo can't be done while required subtasks are still open
+		if status.Category == enums.StatusCategoryDone && !updateDTO.CompleteSubtasks && hasOpenRequiredSubtasks(todo.Subtasks) {
+			return nil, dto.ErrToDoSubtasksOpen
+		}
+		if !updateDTO.Force {
+			if err := checkBlockers(&todo, status); err != nil {
+				return nil, err
+			}
+		}
+		todo.Status, todo.StatusCategory = status.Name, status.Category
+	}
+	// A todo changing status outside the board goes to the end of its new column
+	if todo.Status != previousStatus {
+		todo.BoardPosition = ""
+	}
+	if updateDTO.ClearStartAt {
+		todo.StartAt = nil
+	} else if updateDTO.StartAt != nil {
+		todo.StartAt = updateDTO.StartAt
+	}
+	if updateDTO.ClearDueAt {
+		todo.DueAt = nil
+	} else if updateDTO.DueAt != nil {
+		todo.DueAt = updateDTO.DueAt
+	}
+	if err := checkTodoDates(todo.StartAt, todo.DueAt); err != nil {
+		return nil, err
 	}
 
-	if err := db.Save(&todo).Error; err != nil {
+	// A new rule restarts the series from this todo, the previous occurrences keep the old one
+	var series *models.Recurrence
+	if updateDTO.ClearRecurrence {
+		todo.RecurrenceID = nil
+		todo.Recurrence = nil
+		todo.OccurrenceAt = nil
+	} else if updateDTO.Recurrence != nil {
+		series, err = newRecurrence(updateDTO.Recurrence, todo.Title, todo.DueAt, todo.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		todo.OccurrenceAt = todo.DueAt
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if series != nil {
+			if err := tx.Create(series).Error; err != nil {
+				return err
+			}
+			todo.RecurrenceID = &series.ID
+			todo.Recurrence = series
+		}
+		if err := tx.Omit(clause.Associations).Save(&todo).Error; err != nil {
+			return err
+		}
+		// The next occurrences of a renamed todo follow its new title
+		if series == nil && todo.Title != previousTitle {
+			if err := renameSeries(tx, &todo); err != nil {
+				return err
+			}
+		}
+		if todo.Done() && updateDTO.CompleteSubtasks {
+			if err := tx.Model(&models.Subtask{}).Where("todo_id = ? AND done = ?", todo.ID, false).Update("done", true).Error; err != nil {
+				return err
+			}
+		}
+		if len(addTags) > 0 {
+			if err := tx.Model(&todo).Association("Tags").Append(addTags); err != nil {
+				return err
+			}
+		}
+		if len(removeTags) > 0 {
+			if err := tx.Model(&todo).Association("Tags").Delete(removeTags); err != nil {
+				return err
+			}
+		}
+		// Completing an occurrence of a series brings up the next one
+		if !wasDone && todo.Done() && todo.RecurrenceID != nil {
+			if err := spawnNextOccurrence(tx, &todo); err != nil {
+				return err
+			}
+		}
+		if err := recordTodoUpdate(tx, todo.ID, before, &authorIDUint); err != nil {
+			return err
+		}
+		return tx.Scopes(withTodoRelations).First(&todo, todo.ID).Error
+	})
+	if err != nil {
 		return nil, dto.ErrToDoUpdate
 	}
 
@@ -230,15 +347,20 @@ func SoftDeleteTodo(todoID string, authorID string) error {
 		return err
 	}
 
-	// Check if the author owns the todo
-	if todo.AuthorID != authorIDUint {
-		return dto.ErrUnauthToDo
+	// Only owners can trash or delete a todo
+	if err := authorizeTodo(db, &todo, authorIDUint, enums.ShareRoleOwner); err != nil {
+		return err
 	}
 
-	return db.Delete(&todo).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&todo).Error; err != nil {
+			return err
+		}
+		return recordActivity(tx, todo.ID, &authorIDUint, enums.TodoActivityTrashed, nil)
+	})
 }
 
-// DeleteTodo permanently deletes a todo from the database.
+// DeleteTodo permanently deletes a todo from the database, and its attached files from the storage.
 func DeleteTodo(todoID string, authorID string) error {
 	db := database.GetDB()
 
@@ -261,10 +383,48 @@ func DeleteTodo(todoID string, authorID string) error {
 		return err
 	}
 
-	// Check if the author owns the todo
-	if todo.AuthorID != authorIDUint {
-		return dto.ErrUnauthToDo
+	// Only owners can trash or delete a todo
+	if err := authorizeTodo(db, &todo, authorIDUint, enums.ShareRoleOwner); err != nil {
+		return err
+	}
+
+	var blobs []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		blobs, err = hardDeleteTodos(tx, []models.Todo{todo}, &authorIDUint)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	removeBlobs(blobs)
+	return nil
+}
+
+// findTodo loads an active todo with its relations and checks that the user has a role on it.
+func findTodo(db *gorm.DB, todoID uint, userID uint, needed enums.ShareRole) (*models.Todo, error) {
+	var todo models.Todo
+	err := db.Scopes(withTodoRelations).Where("id = ?", todoID).First(&todo).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrToDoNotFound
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return db.Unscoped().Delete(&todo).Error
+	// Check if the todo is the user's or shared with them
+	if err := authorizeTodo(db, &todo, userID, needed); err != nil {
+		return nil, err
+	}
+
+	return &todo, nil
+}
+
+// reloadTodo reads a todo back with its relations for the response.
+func reloadTodo(db *gorm.DB, todoID uint) (*dto.TodoResponseDTO, error) {
+	var todo models.Todo
+	if err := db.Scopes(withTodoRelations).First(&todo, todoID).Error; err != nil {
+		return nil, err
+	}
+	return utils.ToTodoResponseDTO(&todo), nil
 }
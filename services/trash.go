@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// purgeBatchSize bounds the number of todos deleted by a single statement
+	purgeBatchSize = 500
+	// trashPurgeLockKey identifies the Postgres advisory lock held while purging a batch
+	trashPurgeLockKey = 7_482_315_601
+)
+
+// RestoreTodo brings a trashed todo back, ensuring the user owns it.
+func RestoreTodo(todoID string, authorID string) (*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	todoIDUint, err := utils.ConvId(todoID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	var todo models.Todo
+	err = db.Unscoped().Where("id = ?", todoIDUint).First(&todo).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrToDoNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Only owners can restore a todo
+	if err := authorizeTodo(db, &todo, authorIDUint, enums.ShareRoleOwner); err != nil {
+		return nil, err
+	}
+
+	if err := restoreTodos(db, []models.Todo{todo}, &authorIDUint); err != nil {
+		return nil, err
+	}
+
+	return reloadTodo(db, todo.ID)
+}
+
+// RestoreTodos brings several trashed todos back at once. Nothing is restored
+// unless every todo can be.
+func RestoreTodos(restoreDTO *dto.RestoreTodosDTO, authorID string) ([]*dto.TodoResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	todoIDs := uniqueIDs(restoreDTO.TodoIDs)
+	var todos []models.Todo
+	if err := db.Unscoped().Where("id IN ?", todoIDs).Find(&todos).Error; err != nil {
+		return nil, err
+	}
+	if len(todos) != len(todoIDs) {
+		return nil, dto.ErrToDoNotFound
+	}
+
+	// Only owners can restore a todo
+	for i := range todos {
+		if err := authorizeTodo(db, &todos[i], authorIDUint, enums.ShareRoleOwner); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := restoreTodos(db, todos, &authorIDUint); err != nil {
+		return nil, err
+	}
+
+	var restored []models.Todo
+	if err := db.Scopes(withTodoRelations).Where("id IN ?", todoIDs).Find(&restored).Error; err != nil {
+		return nil, err
+	}
+
+	// Map to DTOs
+	var todoDTOs []*dto.TodoResponseDTO
+	for _, todo := range restored {
+		todoDTOs = append(todoDTOs, utils.ToTodoResponseDTO(&todo))
+	}
+
+	return todoDTOs, nil
+}
+
+// restoreTodos clears the deletion date of trashed todos. A todo whose title has been
+// taken by an active todo in the meantime can't come back, titles are unique per user.
+func restoreTodos(db *gorm.DB, todos []models.Todo, actorID *uint) error {
+	titles := map[string]bool{}
+	for _, todo := range todos {
+		if !todo.DeletedAt.Valid {
+			return dto.ErrToDoNotTrashed
+		}
+		if titles[todo.Title] {
+			return dto.ErrToDoRestoreTitleConflict
+		}
+		titles[todo.Title] = true
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, todo := range todos {
+			var taken int64
+			if err := tx.Model(&models.Todo{}).Where("title = ? AND author_id = ?", todo.Title, todo.AuthorID).Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken > 0 {
+				return dto.ErrToDoRestoreTitleConflict
+			}
+			if err := tx.Unscoped().Model(&models.Todo{}).Where("id = ?", todo.ID).Update("deleted_at", nil).Error; err != nil {
+				return dto.ErrToDoRestore
+			}
+			if err := recordActivity(tx, todo.ID, actorID, enums.TodoActivityRestored, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// EmptyTrash permanently deletes every trashed todo of the author.
+func EmptyTrash(authorID string) error {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	var blobs []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		_, blobs, err = deleteTrashedTodos(context.Background(), tx.Where("author_id = ?", authorIDUint), &authorIDUint)
+		return err
+	})
+	if err != nil {
+		return dto.ErrToDoDelete
+	}
+	removeBlobs(blobs)
+
+	return nil
+}
+
+// PurgeTrash permanently deletes the todos trashed before the given date and returns their IDs.
+// Each batch is deleted in its own transaction, so the size of a transaction and the time
+// its locks are held don't grow with the trash. On Postgres every batch takes an advisory
+// lock for its transaction: when several replicas run the purge at once, a replica that
+// finds the lock taken stops and leaves the rest to the one holding it.
+// A cancelled context stops the purge between two batches. On error, the IDs of the
+// batches already committed are returned with it.
+func PurgeTrash(ctx context.Context, trashedBefore time.Time) ([]uint, error) {
+	db := database.GetDB()
+
+	var purged []uint
+	for ctx.Err() == nil {
+		var ids []uint
+		var blobs []string
+		locked := true
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if tx.Dialector.Name() == "postgres" {
+				if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", trashPurgeLockKey).Scan(&locked).Error; err != nil {
+					return err
+				}
+				if !locked {
+					return nil
+				}
+			}
+
+			var err error
+			ids, blobs, err = deleteTrashedBatch(tx.Where("deleted_at < ?", trashedBefore.UTC()), nil)
+			return err
+		})
+		if err != nil {
+			return purged, err
+		}
+		removeBlobs(blobs)
+		purged = append(purged, ids...)
+
+		if !locked || len(ids) < purgeBatchSize {
+			break
+		}
+	}
+
+	return purged, nil
+}
+
+// deleteTrashedTodos permanently deletes, batch after batch, the trashed todos matched by
+// the query. It returns their IDs and the storage keys of their attached files.
+func deleteTrashedTodos(ctx context.Context, query *gorm.DB, actorID *uint) ([]uint, []string, error) {
+	var deleted []uint
+	var blobs []string
+	for ctx.Err() == nil {
+		ids, keys, err := deleteTrashedBatch(query, actorID)
+		if err != nil {
+			return deleted, blobs, err
+		}
+		deleted = append(deleted, ids...)
+		blobs = append(blobs, keys...)
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+	return deleted, blobs, nil
+}
+
+// deleteTrashedBatch permanently deletes up to purgeBatchSize trashed todos matched by the
+// query, oldest IDs first. It returns their IDs and the storage keys of their attached files.
+func deleteTrashedBatch(query *gorm.DB, actorID *uint) ([]uint, []string, error) {
+	var todos []models.Todo
+	if err := query.Session(&gorm.Session{}).Unscoped().Where("deleted_at IS NOT NULL").Order("id").Limit(purgeBatchSize).Find(&todos).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(todos) == 0 {
+		return nil, nil, nil
+	}
+	keys, err := hardDeleteTodos(query.Session(&gorm.Session{NewDB: true}), todos, actorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]uint, 0, len(todos))
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+	return ids, keys, nil
+}
+
+// hardDeleteTodos permanently deletes todos with their subtasks, tag links and attachments.
+// Their history keeps what they were last. It returns the storage keys of the attached
+// files, to remove with removeBlobs once the deletion is committed.
+func hardDeleteTodos(tx *gorm.DB, todos []models.Todo, actorID *uint) ([]string, error) {
+	if len(todos) == 0 {
+		return nil, nil
+	}
+	keys, err := attachmentKeys(tx, todos)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(todos))
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+	var deleted []models.Todo
+	if err := tx.Unscoped().Preload("Tags").Where("id IN ?", ids).Find(&deleted).Error; err != nil {
+		return nil, err
+	}
+	for i := range deleted {
+		changes := diffSnapshots(todoSnapshot(&deleted[i]), nil)
+		if err := recordActivity(tx, deleted[i].ID, actorID, enums.TodoActivityDeleted, changes); err != nil {
+			return nil, err
+		}
+	}
+
+	// Todos waiting for a deleted todo don't anymore
+	if err := tx.Table("todo_dependencies").Where("todo_id IN ? OR blocker_id IN ?", ids, ids).Delete(&todoDependency{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Select("Tags", "Subtasks", "Attachments", "TimeEntries").Delete(&todos).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
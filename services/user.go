@@ -151,3 +151,16 @@ func UpdateUser(id string, userUpdate *dto.UpdateUserDTO) (*dto.UserResponseDTO,
 
 	return userDTO, nil
 }
+
+// findUserByLogin finds a user by their username or their email.
+func findUserByLogin(db *gorm.DB, login string) (*models.User, error) {
+	var user models.User
+	err := db.Where("username = ? OR email = ?", login, login).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
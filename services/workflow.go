@@ -0,0 +1,495 @@
+package services
+
+import (
+	"errors"
+	"go-feToDo/database"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"go-feToDo/utils"
+
+	"gorm.io/gorm"
+)
+
+// statusAliases maps the statuses of the default workflow and the category names to a
+// category, for imports and clients that don't know the statuses of a workflow.
+var statusAliases = map[enums.TodoStatus]enums.StatusCategory{
+	enums.TodoStatusPending:    enums.StatusCategoryTodo,
+	enums.TodoStatusInProgress: enums.StatusCategoryDoing,
+	enums.TodoStatusCompleted:  enums.StatusCategoryDone,
+	"todo":                     enums.StatusCategoryTodo,
+	"doing":                    enums.StatusCategoryDoing,
+	"done":                     enums.StatusCategoryDone,
+}
+
+// withWorkflowRelations preloads the statuses, in order, and the transitions of a workflow.
+func withWorkflowRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Statuses", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position, id")
+	}).Preload("Transitions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	})
+}
+
+// GetWorkflows lists the workflows the user can give their todos, the built-in one first.
+func GetWorkflows(authorID string) ([]*dto.WorkflowResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	var workflows []models.Workflow
+	if err := db.Scopes(withWorkflowRelations).Where("author_id = ?", authorIDUint).Order("id").Find(&workflows).Error; err != nil {
+		return nil, err
+	}
+
+	builtin := models.DefaultWorkflow()
+	builtin.IsDefault = true
+	for _, workflow := range workflows {
+		if workflow.IsDefault {
+			builtin.IsDefault = false
+		}
+	}
+
+	workflowDTOs := []*dto.WorkflowResponseDTO{utils.ToWorkflowResponseDTO(builtin)}
+	for i := range workflows {
+		workflowDTOs = append(workflowDTOs, utils.ToWorkflowResponseDTO(&workflows[i]))
+	}
+	return workflowDTOs, nil
+}
+
+// CreateWorkflow adds a workflow for the user. It is used once chosen as their default
+// or for a project.
+func CreateWorkflow(workflowDTO *dto.WorkflowDTO, authorID string) (*dto.WorkflowResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	statuses, transitions, err := newWorkflowDefinition(workflowDTO)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow := models.Workflow{
+		Name:        workflowDTO.Name,
+		AuthorID:    authorIDUint,
+		Statuses:    statuses,
+		Transitions: transitions,
+	}
+	if err := db.Create(&workflow).Error; err != nil {
+		return nil, dto.ErrWorkflowCreate
+	}
+
+	return reloadWorkflow(db, workflow.ID)
+}
+
+// UpdateWorkflow replaces the name, statuses and transitions of a workflow of the user.
+// Todos in a status that no longer exists move to the first status of the same category.
+func UpdateWorkflow(workflowID string, workflowDTO *dto.WorkflowDTO, authorID string) (*dto.WorkflowResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	workflowIDUint, err := utils.ConvId(workflowID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	workflow, err := findWorkflow(db, workflowIDUint, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+	statuses, transitions, err := newWorkflowDefinition(workflowDTO)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(workflow).Update("name", workflowDTO.Name).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("workflow_id = ?", workflow.ID).Delete(&models.WorkflowStatus{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("workflow_id = ?", workflow.ID).Delete(&models.WorkflowTransition{}).Error; err != nil {
+			return err
+		}
+		for i := range statuses {
+			statuses[i].WorkflowID = workflow.ID
+		}
+		if err := tx.Create(&statuses).Error; err != nil {
+			return err
+		}
+		if len(transitions) > 0 {
+			for i := range transitions {
+				transitions[i].WorkflowID = workflow.ID
+			}
+			if err := tx.Create(&transitions).Error; err != nil {
+				return err
+			}
+		}
+		return reconcileTodoStatuses(tx, tx.Where("author_id = ?", authorIDUint), &authorIDUint)
+	})
+	if err != nil {
+		return nil, dto.ErrWorkflowUpdate
+	}
+
+	return reloadWorkflow(db, workflow.ID)
+}
+
+// DeleteWorkflow removes a workflow of the user. Its projects and, if it was the default
+// one, the other todos of the user go back to the default workflow.
+func DeleteWorkflow(workflowID string, authorID string) error {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+	workflowIDUint, err := utils.ConvId(workflowID)
+	if err != nil {
+		return dto.ErrAuthIdConv
+	}
+
+	workflow, err := findWorkflow(db, workflowIDUint, authorIDUint)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Project{}).Where("workflow_id = ?", workflow.ID).Update("workflow_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Select("Statuses", "Transitions").Delete(workflow).Error; err != nil {
+			return err
+		}
+		return reconcileTodoStatuses(tx, tx.Where("author_id = ?", authorIDUint), &authorIDUint)
+	})
+	if err != nil {
+		return dto.ErrWorkflowDelete
+	}
+	return nil
+}
+
+// SetDefaultWorkflow makes a workflow of the user, or the built-in one with the ID 0, the
+// workflow of their todos outside a project with a workflow.
+func SetDefaultWorkflow(workflowID string, authorID string) (*dto.WorkflowResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	workflowIDUint, err := utils.ConvId(workflowID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	workflow := models.DefaultWorkflow()
+	if workflowIDUint != 0 {
+		workflow, err = findWorkflow(db, workflowIDUint, authorIDUint)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Workflow{}).Where("author_id = ? AND is_default = ?", authorIDUint, true).Update("is_default", false).Error; err != nil {
+			return err
+		}
+		if workflow.ID != 0 {
+			if err := tx.Model(workflow).Update("is_default", true).Error; err != nil {
+				return err
+			}
+		}
+		return reconcileTodoStatuses(tx, tx.Where("author_id = ?", authorIDUint), &authorIDUint)
+	})
+	if err != nil {
+		return nil, dto.ErrWorkflowUpdate
+	}
+
+	workflow.IsDefault = true
+	return utils.ToWorkflowResponseDTO(workflow), nil
+}
+
+// SetProjectWorkflow chooses the workflow the todos of a project of the user follow. Todos
+// in a status the workflow doesn't have move to its first status of the same category.
+func SetProjectWorkflow(projectID string, workflowDTO *dto.SetProjectWorkflowDTO, authorID string) (*dto.ProjectResponseDTO, error) {
+	db := database.GetDB()
+
+	// Convert authorID to uint
+	authorIDUint, err := utils.ConvId(authorID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+	projectIDUint, err := utils.ConvId(projectID)
+	if err != nil {
+		return nil, dto.ErrAuthIdConv
+	}
+
+	project, err := findProject(db, projectIDUint, authorIDUint)
+	if err != nil {
+		return nil, err
+	}
+	var workflowID *uint
+	if workflowDTO.WorkflowID != 0 {
+		workflow, err := findWorkflow(db, workflowDTO.WorkflowID, authorIDUint)
+		if err != nil {
+			return nil, err
+		}
+		workflowID = &workflow.ID
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(project).Update("workflow_id", workflowID).Error; err != nil {
+			return err
+		}
+		return reconcileTodoStatuses(tx, tx.Where("project_id = ?", project.ID), &authorIDUint)
+	})
+	if err != nil {
+		return nil, dto.ErrProjectUpdate
+	}
+
+	project.WorkflowID = workflowID
+	return utils.ToProjectResponseDTO(project), nil
+}
+
+// findWorkflow loads a workflow with its statuses and transitions and checks that the author owns it.
+func findWorkflow(db *gorm.DB, workflowID uint, authorID uint) (*models.Workflow, error) {
+	var workflow models.Workflow
+	err := db.Scopes(withWorkflowRelations).Where("id = ?", workflowID).First(&workflow).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dto.ErrWorkflowNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if workflow.AuthorID != authorID {
+		return nil, dto.ErrUnauthWorkflow
+	}
+	return &workflow, nil
+}
+
+// reloadWorkflow reads a workflow back with its statuses and transitions for the response.
+func reloadWorkflow(db *gorm.DB, workflowID uint) (*dto.WorkflowResponseDTO, error) {
+	var workflow models.Workflow
+	if err := db.Scopes(withWorkflowRelations).First(&workflow, workflowID).Error; err != nil {
+		return nil, err
+	}
+	return utils.ToWorkflowResponseDTO(&workflow), nil
+}
+
+// newWorkflowDefinition checks the statuses and transitions of a workflow payload: status
+// names are unique and every category has a status, so todos always have one to go to.
+func newWorkflowDefinition(workflowDTO *dto.WorkflowDTO) ([]models.WorkflowStatus, []models.WorkflowTransition, error) {
+	statuses := make([]models.WorkflowStatus, 0, len(workflowDTO.Statuses))
+	names := map[enums.TodoStatus]bool{}
+	categories := map[enums.StatusCategory]bool{}
+	for i, status := range workflowDTO.Statuses {
+		if names[status.Name] {
+			return nil, nil, dto.ErrWorkflowStatuses
+		}
+		names[status.Name] = true
+		categories[status.Category] = true
+		statuses = append(statuses, models.WorkflowStatus{Name: status.Name, Category: status.Category, Position: i, WIPLimit: status.WIPLimit})
+	}
+	if !categories[enums.StatusCategoryTodo] || !categories[enums.StatusCategoryDoing] || !categories[enums.StatusCategoryDone] {
+		return nil, nil, dto.ErrWorkflowStatuses
+	}
+
+	var transitions []models.WorkflowTransition
+	seen := map[dto.WorkflowTransitionDTO]bool{}
+	for _, transition := range workflowDTO.Transitions {
+		if !names[transition.From] || !names[transition.To] {
+			return nil, nil, dto.ErrWorkflowTransition
+		}
+		// Staying in a status is always allowed
+		if transition.From == transition.To || seen[transition] {
+			continue
+		}
+		seen[transition] = true
+		transitions = append(transitions, models.WorkflowTransition{From: transition.From, To: transition.To})
+	}
+	return statuses, transitions, nil
+}
+
+// workflowOf returns the workflow the todos of an author in a project follow: the one of
+// the project, else the default one of the author, else the built-in one.
+func workflowOf(db *gorm.DB, authorID uint, projectID *uint) (*models.Workflow, error) {
+	if projectID != nil {
+		var project models.Project
+		if err := db.Select("id", "workflow_id").First(&project, *projectID).Error; err != nil {
+			return nil, err
+		}
+		if project.WorkflowID != nil {
+			var workflow models.Workflow
+			if err := db.Scopes(withWorkflowRelations).First(&workflow, *project.WorkflowID).Error; err != nil {
+				return nil, err
+			}
+			return &workflow, nil
+		}
+	}
+
+	var workflow models.Workflow
+	err := db.Scopes(withWorkflowRelations).Where("author_id = ? AND is_default = ?", authorID, true).First(&workflow).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultWorkflow(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+// todoWorkflow returns the workflow a todo follows.
+func todoWorkflow(db *gorm.DB, todo *models.Todo) (*models.Workflow, error) {
+	return workflowOf(db, todo.AuthorID, todo.ProjectID)
+}
+
+// checkStatusChange returns the status of a workflow a todo asks to go to from its
+// current one, if the workflow has it and allows the change.
+func checkStatusChange(workflow *models.Workflow, from enums.TodoStatus, to enums.TodoStatus) (*models.WorkflowStatus, error) {
+	status := workflow.Status(to)
+	if status == nil {
+		return nil, dto.ErrToDoStatusUnknown
+	}
+	if !workflow.Allows(from, to) {
+		return nil, dto.ErrToDoStatusTransition
+	}
+	return status, nil
+}
+
+// aliasedStatus returns the status of a workflow named by a client: one of its statuses,
+// else the first status of the category a default status or a category name stands for.
+func aliasedStatus(workflow *models.Workflow, name enums.TodoStatus) (*models.WorkflowStatus, error) {
+	if status := workflow.Status(name); status != nil {
+		return status, nil
+	}
+	if category, ok := statusAliases[name]; ok {
+		return workflow.FirstOf(category), nil
+	}
+	return nil, dto.ErrToDoStatusUnknown
+}
+
+// fitStatus returns the status a todo takes in a workflow it moves to: the one with the
+// same name, else the first one of the same category.
+func fitStatus(workflow *models.Workflow, name enums.TodoStatus, category enums.StatusCategory) *models.WorkflowStatus {
+	if status := workflow.Status(name); status != nil {
+		return status
+	}
+	if status := workflow.FirstOf(category); status != nil {
+		return status
+	}
+	return workflow.Initial()
+}
+
+// setTodoStatus moves a todo to a status of its workflow, without checking the transition.
+// A todo can't be done while required subtasks are still open, and completing an
+// occurrence of a series brings up the next one. A todo changing status goes to the end
+// of its new column on the board.
+func setTodoStatus(tx *gorm.DB, todo *models.Todo, status *models.WorkflowStatus) error {
+	if status.Category == enums.StatusCategoryDone && hasOpenRequiredSubtasks(todo.Subtasks) {
+		return dto.ErrToDoSubtasksOpen
+	}
+
+	wasDone := todo.Done()
+	if status.Name != todo.Status {
+		todo.BoardPosition = ""
+	}
+	todo.Status, todo.StatusCategory = status.Name, status.Category
+	err := tx.Model(todo).Updates(map[string]any{"status": todo.Status, "status_category": todo.StatusCategory, "board_position": todo.BoardPosition}).Error
+	if err != nil {
+		return err
+	}
+
+	if !wasDone && todo.Done() && todo.RecurrenceID != nil {
+		return spawnNextOccurrence(tx, todo)
+	}
+	return nil
+}
+
+// setTodoCategory moves a todo to the first status of a category of its workflow, unless
+// it already is in one. It serves clients that only know categories, like CalDAV.
+func setTodoCategory(tx *gorm.DB, todo *models.Todo, category enums.StatusCategory) error {
+	if todo.StatusCategory == category {
+		return nil
+	}
+	workflow, err := todoWorkflow(tx, todo)
+	if err != nil {
+		return err
+	}
+	return setTodoStatus(tx, todo, workflow.FirstOf(category))
+}
+
+// fitTodoStatuses moves todos into a workflow they now follow, keeping the status they
+// are in when it has it. It returns the status changes by todo, for their history.
+func fitTodoStatuses(tx *gorm.DB, todos []models.Todo, workflow *models.Workflow) (map[uint]dto.FieldChangeDTO, error) {
+	changes := map[uint]dto.FieldChangeDTO{}
+	for i := range todos {
+		todo := &todos[i]
+		status := fitStatus(workflow, todo.Status, todo.StatusCategory)
+		if status.Name == todo.Status && status.Category == todo.StatusCategory {
+			continue
+		}
+		err := tx.Unscoped().Model(&models.Todo{}).Where("id = ?", todo.ID).
+			Updates(map[string]any{"status": status.Name, "status_category": status.Category, "board_position": ""}).Error
+		if err != nil {
+			return nil, err
+		}
+		if status.Name != todo.Status {
+			changes[todo.ID] = dto.FieldChangeDTO{Old: todo.Status, New: status.Name}
+		}
+		todo.Status, todo.StatusCategory, todo.BoardPosition = status.Name, status.Category, ""
+	}
+	return changes, nil
+}
+
+// reconcileTodoStatuses fits the todos matched by the query, trashed ones included, into
+// the workflow they follow after a change of workflows, and records the status changes.
+func reconcileTodoStatuses(tx *gorm.DB, query *gorm.DB, actorID *uint) error {
+	var todos []models.Todo
+	if err := query.Unscoped().Select("id", "author_id", "project_id", "status", "status_category").Find(&todos).Error; err != nil {
+		return err
+	}
+
+	// Todos of a same author and project follow the same workflow
+	type workflowKey struct{ authorID, projectID uint }
+	groups := map[workflowKey][]models.Todo{}
+	for _, todo := range todos {
+		key := workflowKey{authorID: todo.AuthorID}
+		if todo.ProjectID != nil {
+			key.projectID = *todo.ProjectID
+		}
+		groups[key] = append(groups[key], todo)
+	}
+
+	session := tx.Session(&gorm.Session{NewDB: true})
+	for _, group := range groups {
+		workflow, err := todoWorkflow(session, &group[0])
+		if err != nil {
+			return err
+		}
+		changes, err := fitTodoStatuses(session, group, workflow)
+		if err != nil {
+			return err
+		}
+		for todoID, change := range changes {
+			if err := recordActivity(session, todoID, actorID, enums.TodoActivityUpdated, map[string]dto.FieldChangeDTO{"status": change}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
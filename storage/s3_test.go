@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"go-feToDo/config"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// newTestS3Storage connects to the MinIO server of docker-compose, in a bucket of its own
+// removed with its objects after the test. The test is skipped when the server isn't up.
+func newTestS3Storage(t *testing.T) *S3Storage {
+	t.Helper()
+	cfg := *config.LoadConfig()
+	if cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+		t.Skip("S3_ACCESS_KEY and S3_SECRET_KEY are not set")
+	}
+	conn, err := net.DialTimeout("tcp", cfg.S3Endpoint, time.Second)
+	if err != nil {
+		t.Skipf("no S3 server at %s: %v", cfg.S3Endpoint, err)
+	}
+	conn.Close()
+
+	cfg.S3Bucket = fmt.Sprintf("%s-test-%d", cfg.S3Bucket, time.Now().UnixNano())
+	s, err := NewS3Storage(&cfg)
+	if err != nil {
+		t.Fatalf("NewS3Storage() error = %v", err)
+	}
+	t.Cleanup(func() {
+		ctx := context.Background()
+		for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Recursive: true}) {
+			if object.Err == nil {
+				s.client.RemoveObject(ctx, s.bucket, object.Key, minio.RemoveObjectOptions{})
+			}
+		}
+		if err := s.client.RemoveBucket(ctx, s.bucket); err != nil {
+			t.Errorf("RemoveBucket() error = %v", err)
+		}
+	})
+	return s
+}
+
+func TestS3Storage(t *testing.T) {
+	s := newTestS3Storage(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		key         string
+		content     []byte
+		contentType string
+	}{
+		{name: "text", key: "attachments/1/notes.txt", content: []byte("first line\nsecond line\n"), contentType: "text/plain"},
+		{name: "binary", key: "attachments/2/image.png", content: []byte{0x89, 'P', 'N', 'G', 0, 0xff, 0x10}, contentType: "image/png"},
+		{name: "empty", key: "attachments/3/empty", content: []byte{}, contentType: "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Put(ctx, tt.key, bytes.NewReader(tt.content), int64(len(tt.content)), tt.contentType); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+
+			object, err := s.Get(ctx, tt.key)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			got, err := io.ReadAll(object)
+			object.Close()
+			if err != nil {
+				t.Fatalf("reading the object: %v", err)
+			}
+			if !bytes.Equal(got, tt.content) {
+				t.Errorf("Get() = %q, want %q", got, tt.content)
+			}
+			info, err := s.client.StatObject(ctx, s.bucket, tt.key, minio.StatObjectOptions{})
+			if err != nil {
+				t.Fatalf("StatObject() error = %v", err)
+			}
+			if info.ContentType != tt.contentType {
+				t.Errorf("content type = %q, want %q", info.ContentType, tt.contentType)
+			}
+
+			if err := s.Delete(ctx, tt.key); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if _, err := s.Get(ctx, tt.key); !errors.Is(err, ErrObjectNotFound) {
+				t.Errorf("Get() after Delete() error = %v, want %v", err, ErrObjectNotFound)
+			}
+		})
+	}
+}
+
+func TestS3StorageReplace(t *testing.T) {
+	s := newTestS3Storage(t)
+	ctx := context.Background()
+	key := "attachments/1/report.csv"
+
+	for _, content := range []string{"a,b\n1,2\n", "a,b\n3,4\n"} {
+		if err := s.Put(ctx, key, bytes.NewReader([]byte(content)), int64(len(content)), "text/csv"); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	object, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer object.Close()
+	got, err := io.ReadAll(object)
+	if err != nil {
+		t.Fatalf("reading the object: %v", err)
+	}
+	if string(got) != "a,b\n3,4\n" {
+		t.Errorf("Get() = %q, want the last content put", got)
+	}
+}
+
+func TestS3StorageMissing(t *testing.T) {
+	s := newTestS3Storage(t)
+	ctx := context.Background()
+
+	if _, err := s.Get(ctx, "attachments/404/missing.txt"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Get() of a missing object error = %v, want %v", err, ErrObjectNotFound)
+	}
+	if err := s.Delete(ctx, "attachments/404/missing.txt"); err != nil {
+		t.Errorf("Delete() of a missing object error = %v, want nil", err)
+	}
+
+	// Only a missing object is ErrObjectNotFound, a missing bucket is a server problem
+	noBucket := &S3Storage{client: s.client, bucket: s.bucket + "-missing"}
+	_, err := noBucket.Get(ctx, "attachments/1/notes.txt")
+	if err == nil || errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Get() in a missing bucket error = %v, want another error than %v", err, ErrObjectNotFound)
+	}
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+
+	"go-feToDo/config"
+)
+
+// ErrObjectNotFound is returned when reading an object that isn't stored.
+var ErrObjectNotFound = errors.New("stored object not found")
+
+// Storage keeps the files uploaded to the application, like attachments, by key.
+type Storage interface {
+	// Put stores the content of r under key, replacing any object already there.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object stored under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	store Storage
+	once  sync.Once
+)
+
+// Connect initializes the storage backend chosen in the configuration
+func Connect() Storage {
+	once.Do(func() {
+		cfg := config.LoadConfig()
+
+		var err error
+		switch cfg.StorageDriver {
+		case "local":
+			store, err = NewLocalStorage(cfg.StorageLocalPath)
+		case "s3":
+			store, err = NewS3Storage(cfg)
+		default:
+			log.Fatalf("Unknown storage driver %q, use local or s3", cfg.StorageDriver)
+		}
+		if err != nil {
+			log.Fatalf("Failed to initialize the %s storage: %v", cfg.StorageDriver, err)
+		}
+		log.Printf("Storage %s initialized successfully!", cfg.StorageDriver)
+	})
+
+	return store
+}
+
+// GetStorage provides the singleton instance of the storage backend
+func GetStorage() Storage {
+	if store == nil {
+		log.Fatal("Storage is not initialized. Call Connect() first.")
+	}
+	return store
+}
@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -109,6 +113,22 @@ func RefreshToken(refreshToken string) (string, error) {
 	return CreateToken(idUint, payload.Username)
 }
 
+// NewSecretToken generates a random token for a secret URL, with the hash it is stored as
+func NewSecretToken() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashSecretToken(token), nil
+}
+
+// HashSecretToken returns the hash a secret token is stored and looked up as
+func HashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // getDuration returns a specified duration or the default value if not provided
 func getDuration(durations []time.Duration, defaultDuration time.Duration) time.Duration {
 	if len(durations) > 0 {
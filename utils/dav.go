@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// XML namespaces of the WebDAV and CalDAV properties
+const (
+	DavNamespace            = "DAV:"
+	CalDavNamespace         = "urn:ietf:params:xml:ns:caldav"
+	CalendarServerNamespace = "http://calendarserver.org/ns/"
+)
+
+// davPrefixes are the prefixes the namespaces are declared with in a multistatus.
+var davPrefixes = map[string]string{
+	DavNamespace:            "d",
+	CalDavNamespace:         "c",
+	CalendarServerNamespace: "cs",
+}
+
+// ErrDavBody is returned for a request body that isn't well-formed XML.
+var ErrDavBody = errors.New("invalid WebDAV request body")
+
+// DavNode is an element of the XML body of a WebDAV request.
+type DavNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []DavNode  `xml:",any"`
+	Text     string     `xml:",chardata"`
+}
+
+// Attr returns the value of an attribute without namespace, or "".
+func (n *DavNode) Attr(local string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Space == "" && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// Child returns the first child element with a name, or nil.
+func (n *DavNode) Child(space string, local string) *DavNode {
+	for i := range n.Children {
+		if n.Children[i].XMLName.Space == space && n.Children[i].XMLName.Local == local {
+			return &n.Children[i]
+		}
+	}
+	return nil
+}
+
+// ChildrenNamed returns every child element with a name.
+func (n *DavNode) ChildrenNamed(space string, local string) []*DavNode {
+	var children []*DavNode
+	for i := range n.Children {
+		if n.Children[i].XMLName.Space == space && n.Children[i].XMLName.Local == local {
+			children = append(children, &n.Children[i])
+		}
+	}
+	return children
+}
+
+// ParseDavBody parses the XML body of a WebDAV request. An empty body gives a nil node.
+func ParseDavBody(r io.Reader) (*DavNode, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, ErrDavBody
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+
+	var node DavNode
+	if err := xml.Unmarshal(body, &node); err != nil {
+		return nil, ErrDavBody
+	}
+	return &node, nil
+}
+
+// DavProperty is a property of a resource, its value being the XML inside the element.
+type DavProperty struct {
+	Name  xml.Name
+	Value string
+}
+
+// DavResponse is the response of a multistatus about one resource. A resource with no
+// properties to show, like a missing one, only has a status.
+type DavResponse struct {
+	Href    string
+	Status  int
+	Found   []DavProperty
+	Missing []xml.Name
+}
+
+// WriteDavMultistatus writes the body of a 207 Multi-Status response.
+func WriteDavMultistatus(responses []*DavResponse) []byte {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">`)
+	for _, response := range responses {
+		b.WriteString("<d:response>")
+		b.WriteString(DavHref(response.Href))
+		if response.Status != 0 {
+			b.WriteString("<d:status>" + davStatus(response.Status) + "</d:status>")
+		}
+		if len(response.Found) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, property := range response.Found {
+				b.WriteString(davElement(property.Name, property.Value))
+			}
+			b.WriteString("</d:prop><d:status>" + davStatus(http.StatusOK) + "</d:status></d:propstat>")
+		}
+		if len(response.Missing) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, name := range response.Missing {
+				b.WriteString(davElement(name, ""))
+			}
+			b.WriteString("</d:prop><d:status>" + davStatus(http.StatusNotFound) + "</d:status></d:propstat>")
+		}
+		b.WriteString("</d:response>")
+	}
+	b.WriteString("</d:multistatus>\n")
+	return []byte(b.String())
+}
+
+// WriteDavError writes the body of an error response naming the precondition that failed.
+func WriteDavError(condition xml.Name) []byte {
+	return []byte(`<?xml version="1.0" encoding="utf-8"?>` + "\n" +
+		`<d:error xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` + davElement(condition, "") + "</d:error>\n")
+}
+
+// DavHref writes a href element.
+func DavHref(href string) string {
+	return "<d:href>" + DavText(href) + "</d:href>"
+}
+
+// DavText escapes text to place inside an element.
+func DavText(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// davElement writes an element holding some XML, declaring its namespace when it has no prefix.
+func davElement(name xml.Name, value string) string {
+	tag := name.Local
+	attrs := ""
+	if prefix, ok := davPrefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag = "x:" + name.Local
+		attrs = ` xmlns:x="` + DavText(name.Space) + `"`
+	}
+	if value == "" {
+		return "<" + tag + attrs + "/>"
+	}
+	return "<" + tag + attrs + ">" + value + "</" + tag + ">"
+}
+
+// davStatus formats the status line of a response or a propstat.
+func davStatus(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDavBody(t *testing.T) {
+	getetag := xml.Name{Space: DavNamespace, Local: "getetag"}
+	calendarData := xml.Name{Space: CalDavNamespace, Local: "calendar-data"}
+
+	tests := []struct {
+		name     string
+		body     string
+		wantNil  bool
+		wantErr  error
+		root     xml.Name
+		props    []xml.Name
+		hrefs    []string
+		compName string // name of the comp-filter inside the VCALENDAR one
+	}{
+		{name: "empty body", body: "", wantNil: true},
+		{name: "blank body", body: "\r\n  \n", wantNil: true},
+		{name: "malformed body", body: `<d:propfind xmlns:d="DAV:"><d:prop>`, wantErr: ErrDavBody},
+		{
+			name: "apple propfind with prefixes",
+			body: `<?xml version="1.0" encoding="UTF-8"?>
+<A:propfind xmlns:A="DAV:">
+  <A:prop>
+    <A:getetag/>
+    <B:calendar-data xmlns:B="urn:ietf:params:xml:ns:caldav"/>
+  </A:prop>
+</A:propfind>`,
+			root:  xml.Name{Space: DavNamespace, Local: "propfind"},
+			props: []xml.Name{getetag, calendarData},
+		},
+		{
+			name:  "davx5 propfind with a default namespace",
+			body:  `<?xml version='1.0' encoding='UTF-8' ?><propfind xmlns="DAV:" xmlns:CAL="urn:ietf:params:xml:ns:caldav"><prop><getetag /><CAL:calendar-data /></prop></propfind>`,
+			root:  xml.Name{Space: DavNamespace, Local: "propfind"},
+			props: []xml.Name{getetag, calendarData},
+		},
+		{
+			name: "thunderbird calendar-query",
+			body: `<?xml version="1.0" encoding="UTF-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/></D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VTODO"/>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`,
+			root:     xml.Name{Space: CalDavNamespace, Local: "calendar-query"},
+			props:    []xml.Name{getetag},
+			compName: "VTODO",
+		},
+		{
+			name:  "davx5 calendar-multiget",
+			body:  `<?xml version='1.0' encoding='UTF-8' ?><CAL:calendar-multiget xmlns="DAV:" xmlns:CAL="urn:ietf:params:xml:ns:caldav"><prop><getetag /><CAL:calendar-data /></prop><href>/dav/calendars/alice/todos/a.ics</href><href> /dav/calendars/alice/todos/b%20c.ics </href></CAL:calendar-multiget>`,
+			root:  xml.Name{Space: CalDavNamespace, Local: "calendar-multiget"},
+			props: []xml.Name{getetag, calendarData},
+			hrefs: []string{"/dav/calendars/alice/todos/a.ics", " /dav/calendars/alice/todos/b%20c.ics "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ParseDavBody(strings.NewReader(tt.body))
+			if tt.wantErr != nil || tt.wantNil {
+				if node != nil || !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseDavBody() = %v, %v, want no node and error %v", node, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDavBody() error = %v", err)
+			}
+
+			if node.XMLName != tt.root {
+				t.Errorf("root = %v, want %v", node.XMLName, tt.root)
+			}
+			var props []xml.Name
+			if prop := node.Child(DavNamespace, "prop"); prop != nil {
+				for _, child := range prop.Children {
+					props = append(props, child.XMLName)
+				}
+			}
+			if !reflect.DeepEqual(props, tt.props) {
+				t.Errorf("props = %v, want %v", props, tt.props)
+			}
+			var hrefs []string
+			for _, href := range node.ChildrenNamed(DavNamespace, "href") {
+				hrefs = append(hrefs, href.Text)
+			}
+			if !reflect.DeepEqual(hrefs, tt.hrefs) {
+				t.Errorf("hrefs = %q, want %q", hrefs, tt.hrefs)
+			}
+			if tt.compName != "" {
+				filter := node.Child(CalDavNamespace, "filter")
+				if filter == nil {
+					t.Fatal("no filter")
+				}
+				calendar := filter.Child(CalDavNamespace, "comp-filter")
+				if calendar == nil || calendar.Attr("name") != "VCALENDAR" {
+					t.Fatalf("calendar comp-filter = %+v", calendar)
+				}
+				if component := calendar.Child(CalDavNamespace, "comp-filter"); component == nil || component.Attr("name") != tt.compName {
+					t.Errorf("comp-filter = %+v, want %s", component, tt.compName)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteDavMultistatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []*DavResponse
+		want      string
+	}{
+		{
+			name:      "no responses",
+			responses: nil,
+			want:      `<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/"></d:multistatus>`,
+		},
+		{
+			name:      "missing resource",
+			responses: []*DavResponse{{Href: "/dav/calendars/alice/todos/gone.ics", Status: http.StatusNotFound}},
+			want: `<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">` +
+				`<d:response><d:href>/dav/calendars/alice/todos/gone.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status></d:response>` +
+				`</d:multistatus>`,
+		},
+		{
+			name: "found and missing properties",
+			responses: []*DavResponse{{
+				Href: "/dav/calendars/alice/todos/a&b.ics",
+				Found: []DavProperty{
+					{Name: xml.Name{Space: DavNamespace, Local: "getetag"}, Value: DavText(`"1f0c"`)},
+					{Name: xml.Name{Space: CalendarServerNamespace, Local: "getctag"}, Value: "abc"},
+					{Name: xml.Name{Space: DavNamespace, Local: "resourcetype"}},
+				},
+				Missing: []xml.Name{
+					{Space: CalDavNamespace, Local: "calendar-user-address-set"},
+					{Space: "http://apple.com/ns/ical/", Local: "calendar-color"},
+				},
+			}},
+			want: `<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">` +
+				`<d:response><d:href>/dav/calendars/alice/todos/a&amp;b.ics</d:href>` +
+				`<d:propstat><d:prop><d:getetag>&#34;1f0c&#34;</d:getetag><cs:getctag>abc</cs:getctag><d:resourcetype/></d:prop>` +
+				`<d:status>HTTP/1.1 200 OK</d:status></d:propstat>` +
+				`<d:propstat><d:prop><c:calendar-user-address-set/><x:calendar-color xmlns:x="http://apple.com/ns/ical/"/></d:prop>` +
+				`<d:status>HTTP/1.1 404 Not Found</d:status></d:propstat></d:response>` +
+				`</d:multistatus>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(WriteDavMultistatus(tt.responses))
+			want := `<?xml version="1.0" encoding="utf-8"?>` + "\n" + tt.want + "\n"
+			if got != want {
+				t.Errorf("WriteDavMultistatus() =\n%s\nwant\n%s", got, want)
+			}
+
+			// What is written reads back as the same responses
+			var multistatus struct {
+				Responses []struct {
+					Href string `xml:"DAV: href"`
+				} `xml:"DAV: response"`
+			}
+			if err := xml.Unmarshal([]byte(got), &multistatus); err != nil {
+				t.Fatalf("multistatus isn't well-formed: %v", err)
+			}
+			if len(multistatus.Responses) != len(tt.responses) {
+				t.Fatalf("%d responses, want %d", len(multistatus.Responses), len(tt.responses))
+			}
+			for i, response := range multistatus.Responses {
+				if response.Href != tt.responses[i].Href {
+					t.Errorf("href = %q, want %q", response.Href, tt.responses[i].Href)
+				}
+			}
+		})
+	}
+}
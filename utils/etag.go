@@ -0,0 +1,17 @@
+package utils
+
+import "strings"
+
+// ETagMatches tells whether an If-None-Match header lists an ETag.
+// Weak and strong validators compare the same way, as If-None-Match requires.
+func ETagMatches(ifNoneMatch string, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
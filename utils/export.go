@@ -0,0 +1,283 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"go-feToDo/models"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrExportFormat = errors.New("unknown export format")
+
+// TodoExporter writes todos one at a time in an export format.
+// Close must be called once every todo is written to end the document.
+type TodoExporter interface {
+	Write(todo *models.Todo, project string) error
+	Close() error
+}
+
+// exportFormat describes how a format is served and how its exporter is built.
+type exportFormat struct {
+	contentType string
+	extension   string
+	new         func(w io.Writer) (TodoExporter, error)
+}
+
+var exportFormats = map[string]exportFormat{
+	"csv":      {"text/csv; charset=utf-8", "csv", newCSVExporter},
+	"json":     {"application/json; charset=utf-8", "json", newJSONExporter},
+	"markdown": {"text/markdown; charset=utf-8", "md", newMarkdownExporter},
+	"todotxt":  {"text/plain; charset=utf-8", "txt", newTodoTxtExporter},
+}
+
+// todoTxtPriorities maps the priorities to the todo.txt priority letters, none has no letter.
+var todoTxtPriorities = map[enums.TodoPriority]string{
+	enums.TodoPriorityUrgent: "A",
+	enums.TodoPriorityHigh:   "B",
+	enums.TodoPriorityMedium: "C",
+	enums.TodoPriorityLow:    "D",
+}
+
+// NewTodoExporter starts an export document in a format and returns its exporter.
+func NewTodoExporter(format string, w io.Writer) (TodoExporter, error) {
+	exportFormat, ok := exportFormats[format]
+	if !ok {
+		return nil, ErrExportFormat
+	}
+	return exportFormat.new(w)
+}
+
+// ExportContentType returns the content type and the file name of an export.
+func ExportContentType(format string) (string, string) {
+	exportFormat := exportFormats[format]
+	return exportFormat.contentType, "todos." + exportFormat.extension
+}
+
+// exportTags lists the names of the tags of a todo.
+func exportTags(todo *models.Todo) []string {
+	names := make([]string, 0, len(todo.Tags))
+	for _, tag := range todo.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// exportTime formats an optional timestamp in UTC, empty when it is not set.
+func exportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// deletedAt returns when a todo was trashed, nil for an active todo.
+func deletedAt(todo *models.Todo) *time.Time {
+	if !todo.DeletedAt.Valid {
+		return nil
+	}
+	return &todo.DeletedAt.Time
+}
+
+// csvExporter writes one row per todo after a header row.
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func newCSVExporter(w io.Writer) (TodoExporter, error) {
+	e := &csvExporter{w: csv.NewWriter(w)}
+	header := []string{"id", "title", "description", "status", "priority", "project", "tags",
+		"start_at", "due_at", "created_at", "updated_at", "deleted_at"}
+	if err := e.w.Write(header); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *csvExporter) Write(todo *models.Todo, project string) error {
+	return e.w.Write([]string{
+		fmt.Sprint(todo.ID),
+		csvCell(todo.Title),
+		csvCell(todo.Description),
+		string(todo.Status),
+		string(todo.Priority),
+		csvCell(project),
+		csvCell(strings.Join(exportTags(todo), ";")),
+		exportTime(todo.StartAt),
+		exportTime(todo.DueAt),
+		exportTime(&todo.CreatedAt),
+		exportTime(&todo.UpdatedAt),
+		exportTime(deletedAt(todo)),
+	})
+}
+
+func (e *csvExporter) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// csvCell keeps spreadsheets from running a user text as a formula.
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// jsonExporter writes a JSON array of todos, one element per line.
+type jsonExporter struct {
+	w     io.Writer
+	first bool
+}
+
+func newJSONExporter(w io.Writer) (TodoExporter, error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return nil, err
+	}
+	return &jsonExporter{w: w, first: true}, nil
+}
+
+func (e *jsonExporter) Write(todo *models.Todo, project string) error {
+	raw, err := json.Marshal(dto.TodoExportDTO{
+		TodoResponseDTO: ToTodoResponseDTO(todo),
+		DeletedAt:       deletedAt(todo),
+	})
+	if err != nil {
+		return err
+	}
+	separator := ",\n"
+	if e.first {
+		separator, e.first = "\n", false
+	}
+	_, err = io.WriteString(e.w, separator+string(raw))
+	return err
+}
+
+func (e *jsonExporter) Close() error {
+	_, err := io.WriteString(e.w, "\n]\n")
+	return err
+}
+
+// markdownExporter writes a task list, the details of a todo are nested under its item
+// and its description is quoted below them.
+type markdownExporter struct {
+	w io.Writer
+}
+
+func newMarkdownExporter(w io.Writer) (TodoExporter, error) {
+	if _, err := io.WriteString(w, "# Todos\n"); err != nil {
+		return nil, err
+	}
+	return &markdownExporter{w: w}, nil
+}
+
+func (e *markdownExporter) Write(todo *models.Todo, project string) error {
+	var b strings.Builder
+	check := " "
+	if todo.Done() {
+		check = "x"
+	}
+	fmt.Fprintf(&b, "\n- [%s] %s\n", check, escapeMarkdown(strings.Join(strings.Fields(todo.Title), " ")))
+
+	details := [][2]string{
+		{"Status", string(todo.Status)},
+		{"Priority", string(todo.Priority)},
+		{"Project", project},
+		{"Tags", strings.Join(exportTags(todo), ", ")},
+		{"Start", exportTime(todo.StartAt)},
+		{"Due", exportTime(todo.DueAt)},
+		{"Created", exportTime(&todo.CreatedAt)},
+		{"Updated", exportTime(&todo.UpdatedAt)},
+		{"Trashed", exportTime(deletedAt(todo))},
+	}
+	for _, detail := range details {
+		if detail[1] != "" {
+			fmt.Fprintf(&b, "  - %s: %s\n", detail[0], escapeMarkdown(detail[1]))
+		}
+	}
+
+	if description := strings.TrimSpace(todo.Description); description != "" {
+		b.WriteString("\n")
+		for _, line := range strings.Split(strings.ReplaceAll(description, "\r\n", "\n"), "\n") {
+			b.WriteString(strings.TrimRight("  > "+escapeMarkdown(line), " ") + "\n")
+		}
+	}
+
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *markdownExporter) Close() error {
+	return nil
+}
+
+// escapeMarkdown escapes the characters Markdown would read as formatting.
+var escapeMarkdown = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`,
+).Replace
+
+// todoTxtExporter writes one todo.txt line per todo. Completed todos start with "x"
+// and their completion date, which is their last update since it isn't tracked.
+// The project becomes a +project, tags become @contexts and the fields todo.txt
+// has no syntax for are key:value pairs, with the description percent-encoded.
+type todoTxtExporter struct {
+	w io.Writer
+}
+
+func newTodoTxtExporter(w io.Writer) (TodoExporter, error) {
+	return &todoTxtExporter{w: w}, nil
+}
+
+func (e *todoTxtExporter) Write(todo *models.Todo, project string) error {
+	var parts []string
+	priority, hasPriority := todoTxtPriorities[todo.Priority]
+	if todo.Done() {
+		parts = append(parts, "x", todo.UpdatedAt.UTC().Format(time.DateOnly))
+	} else if hasPriority {
+		parts = append(parts, "("+priority+")")
+	}
+	parts = append(parts, todo.CreatedAt.UTC().Format(time.DateOnly))
+	parts = append(parts, strings.Fields(todo.Title)...)
+
+	if project != "" {
+		parts = append(parts, "+"+todoTxtWord(project))
+	}
+	for _, tag := range exportTags(todo) {
+		parts = append(parts, "@"+todoTxtWord(tag))
+	}
+	if todo.StartAt != nil {
+		parts = append(parts, "t:"+todo.StartAt.UTC().Format(time.DateOnly))
+	}
+	if todo.DueAt != nil {
+		parts = append(parts, "due:"+todo.DueAt.UTC().Format(time.DateOnly))
+	}
+	// Completed todos lose their priority letter, it is kept as a pair
+	if todo.Done() && hasPriority {
+		parts = append(parts, "pri:"+priority)
+	}
+	if todo.Description != "" {
+		parts = append(parts, "note:"+url.PathEscape(todo.Description))
+	}
+	if trashedAt := deletedAt(todo); trashedAt != nil {
+		parts = append(parts, "trashed:"+trashedAt.UTC().Format(time.DateOnly))
+	}
+
+	_, err := io.WriteString(e.w, strings.Join(parts, " ")+"\n")
+	return err
+}
+
+func (e *todoTxtExporter) Close() error {
+	return nil
+}
+
+// todoTxtWord joins the words of a name so it reads as a single todo.txt tag.
+func todoTxtWord(name string) string {
+	return strings.Join(strings.Fields(name), "_")
+}
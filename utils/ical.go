@@ -0,0 +1,435 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// icalLineLimit is the longest a content line may be, in octets, before it is folded
+	icalLineLimit  = 75
+	icalUTCFormat  = "20060102T150405Z"
+	icalFormat     = "20060102T150405"
+	icalDateFormat = "20060102"
+	// icalTimezoneYears is how many years of transitions a VTIMEZONE lists
+	icalTimezoneYears = 10
+)
+
+// ErrICalSyntax is returned for data that isn't a well-formed iCalendar object.
+var ErrICalSyntax = errors.New("invalid iCalendar data")
+
+// icalDuration matches a DURATION value, like P1W, P2D or PT1H30M.
+var icalDuration = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ICalWriter builds an RFC 5545 iCalendar object line by line.
+type ICalWriter struct {
+	buf bytes.Buffer
+}
+
+// Bytes returns the iCalendar object written so far.
+func (w *ICalWriter) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+// Line writes a content line with a value used as is, folding it past 75 octets.
+func (w *ICalWriter) Line(name string, value string) {
+	line := name + ":" + value
+	for len(line) > icalLineLimit {
+		// Don't cut a UTF-8 sequence in two
+		cut := icalLineLimit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.buf.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	w.buf.WriteString(line + "\r\n")
+}
+
+// Text writes a TEXT property, escaped.
+func (w *ICalWriter) Text(name string, text string) {
+	w.Line(name, EscapeICalText(text))
+}
+
+// Time writes a DATE-TIME property, in UTC or as a local time of the location.
+func (w *ICalWriter) Time(name string, t time.Time, loc *time.Location) {
+	w.Line(icalTimeName(name, loc), FormatICalTime(t, loc))
+}
+
+// Times writes a DATE-TIME property holding a list of times, like EXDATE.
+func (w *ICalWriter) Times(name string, times []time.Time, loc *time.Location) {
+	values := make([]string, 0, len(times))
+	for _, t := range times {
+		values = append(values, FormatICalTime(t, loc))
+	}
+	w.Line(icalTimeName(name, loc), strings.Join(values, ","))
+}
+
+// Timezone writes the VTIMEZONE a location needs from a date, listing every change
+// of offset of the following years. Nothing is written for UTC.
+func (w *ICalWriter) Timezone(loc *time.Location, from time.Time) {
+	if isUTC(loc) {
+		return
+	}
+
+	type observance struct {
+		kind   string
+		from   int
+		to     int
+		name   string
+		onsets []time.Time
+	}
+	var observances []*observance
+	addOnset := func(kind string, from int, to int, name string, onset time.Time) {
+		for _, o := range observances {
+			if o.kind == kind && o.from == from && o.to == to && o.name == name {
+				o.onsets = append(o.onsets, onset)
+				return
+			}
+		}
+		observances = append(observances, &observance{kind, from, to, name, []time.Time{onset}})
+	}
+
+	start := time.Date(from.In(loc).Year(), time.January, 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(icalTimezoneYears, 0, 0)
+	for _, change := range zoneChanges(start, end) {
+		name, offset := change.In(loc).Zone()
+		_, previous := change.Add(-time.Second).In(loc).Zone()
+		kind := "STANDARD"
+		if change.In(loc).IsDST() {
+			kind = "DAYLIGHT"
+		}
+		// Onsets are written in the local time in effect before the change
+		addOnset(kind, previous, offset, name, change.In(time.FixedZone("", previous)))
+	}
+	if len(observances) == 0 {
+		name, offset := start.Zone()
+		addOnset("STANDARD", offset, offset, name, time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC))
+	}
+
+	w.Line("BEGIN", "VTIMEZONE")
+	w.Line("TZID", loc.String())
+	for _, o := range observances {
+		w.Line("BEGIN", o.kind)
+		w.Line("DTSTART", o.onsets[0].Format(icalFormat))
+		if len(o.onsets) > 1 {
+			rdates := make([]string, 0, len(o.onsets)-1)
+			for _, onset := range o.onsets[1:] {
+				rdates = append(rdates, onset.Format(icalFormat))
+			}
+			w.Line("RDATE", strings.Join(rdates, ","))
+		}
+		w.Line("TZOFFSETFROM", formatICalOffset(o.from))
+		w.Line("TZOFFSETTO", formatICalOffset(o.to))
+		if o.name != "" {
+			w.Text("TZNAME", o.name)
+		}
+		w.Line("END", o.kind)
+	}
+	w.Line("END", "VTIMEZONE")
+}
+
+// zoneChanges returns the instants the offset of the zone of start changes before end.
+func zoneChanges(start time.Time, end time.Time) []time.Time {
+	var changes []time.Time
+	_, offset := start.Zone()
+	for day := start; day.Before(end); day = day.Add(24 * time.Hour) {
+		next := day.Add(24 * time.Hour)
+		if _, nextOffset := next.Zone(); nextOffset == offset {
+			continue
+		}
+		// Narrow the change down to the second
+		lo, hi := day, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, midOffset := mid.Zone(); midOffset == offset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		changes = append(changes, hi)
+		_, offset = next.Zone()
+	}
+	return changes
+}
+
+// FormatICalTime formats a DATE-TIME value, in UTC or as a local time of the location.
+func FormatICalTime(t time.Time, loc *time.Location) string {
+	if isUTC(loc) {
+		return t.UTC().Format(icalUTCFormat)
+	}
+	return t.In(loc).Format(icalFormat)
+}
+
+// EscapeICalText escapes a TEXT value.
+func EscapeICalText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(text)
+}
+
+// icalTimeName adds the TZID parameter of a local time to a property name.
+func icalTimeName(name string, loc *time.Location) string {
+	if isUTC(loc) {
+		return name
+	}
+	return name + ";TZID=" + loc.String()
+}
+
+// formatICalOffset formats a UTC offset in seconds as a UTC-OFFSET value.
+func formatICalOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	value := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+	if offset%60 != 0 {
+		value += fmt.Sprintf("%02d", offset%60)
+	}
+	return value
+}
+
+func isUTC(loc *time.Location) bool {
+	return loc == nil || loc == time.UTC || loc.String() == "UTC"
+}
+
+// ICalProperty is a content line of an iCalendar object, its value still escaped.
+type ICalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// ICalComponent is a component of an iCalendar object, like a VCALENDAR or a VTODO.
+type ICalComponent struct {
+	Name       string
+	Properties []*ICalProperty
+	Components []*ICalComponent
+}
+
+// Property returns the first property of the component with a name, or nil.
+func (c *ICalComponent) Property(name string) *ICalProperty {
+	for _, p := range c.Properties {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// PropertiesNamed returns every property of the component with a name.
+func (c *ICalComponent) PropertiesNamed(name string) []*ICalProperty {
+	var properties []*ICalProperty
+	for _, p := range c.Properties {
+		if p.Name == name {
+			properties = append(properties, p)
+		}
+	}
+	return properties
+}
+
+// ComponentsNamed returns every sub-component of the component with a name.
+func (c *ICalComponent) ComponentsNamed(name string) []*ICalComponent {
+	var components []*ICalComponent
+	for _, sub := range c.Components {
+		if sub.Name == name {
+			components = append(components, sub)
+		}
+	}
+	return components
+}
+
+// ParseICal parses an iCalendar object into its top component, usually a VCALENDAR.
+// Names are upper-cased, values are left escaped since their type sets how to read them.
+func ParseICal(data []byte) (*ICalComponent, error) {
+	// Unfold the content lines, clients don't all end their lines with CRLF
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.NewReplacer("\n ", "", "\n\t", "").Replace(text)
+
+	var root *ICalComponent
+	var stack []*ICalComponent
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		property, err := parseICalLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch property.Name {
+		case "BEGIN":
+			if root != nil && len(stack) == 0 {
+				// Only one object per resource
+				return nil, ErrICalSyntax
+			}
+			component := &ICalComponent{Name: strings.ToUpper(property.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, component)
+			} else {
+				root = component
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(property.Value) {
+				return nil, ErrICalSyntax
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, ErrICalSyntax
+			}
+			component := stack[len(stack)-1]
+			component.Properties = append(component.Properties, property)
+		}
+	}
+	if root == nil || len(stack) > 0 {
+		return nil, ErrICalSyntax
+	}
+	return root, nil
+}
+
+// parseICalLine splits an unfolded content line into its name, parameters and value.
+// Parameter values may be quoted, and quoted values may hold ":" and ";".
+func parseICalLine(line string) (*ICalProperty, error) {
+	property := &ICalProperty{Params: map[string]string{}}
+
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return nil, ErrICalSyntax
+	}
+	property.Name = strings.ToUpper(line[:end])
+	line = line[end:]
+
+	for strings.HasPrefix(line, ";") {
+		line = line[1:]
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			return nil, ErrICalSyntax
+		}
+		name := strings.ToUpper(line[:eq])
+		line = line[eq+1:]
+
+		var value strings.Builder
+		quoted := false
+		i := 0
+		for ; i < len(line); i++ {
+			ch := line[i]
+			if ch == '"' {
+				quoted = !quoted
+				continue
+			}
+			if !quoted && (ch == ';' || ch == ':') {
+				break
+			}
+			value.WriteByte(ch)
+		}
+		if quoted || i == len(line) {
+			return nil, ErrICalSyntax
+		}
+		property.Params[name] = value.String()
+		line = line[i:]
+	}
+
+	if !strings.HasPrefix(line, ":") {
+		return nil, ErrICalSyntax
+	}
+	property.Value = line[1:]
+	return property, nil
+}
+
+// Text returns the value of a TEXT property, unescaped.
+func (p *ICalProperty) Text() string {
+	return UnescapeICalText(p.Value)
+}
+
+// TextList returns the values of a property holding a list of TEXT, like CATEGORIES.
+func (p *ICalProperty) TextList() []string {
+	var values []string
+	var value strings.Builder
+	for i := 0; i < len(p.Value); i++ {
+		ch := p.Value[i]
+		if ch == '\\' && i+1 < len(p.Value) {
+			value.WriteByte(ch)
+			value.WriteByte(p.Value[i+1])
+			i++
+			continue
+		}
+		if ch == ',' {
+			values = append(values, UnescapeICalText(value.String()))
+			value.Reset()
+			continue
+		}
+		value.WriteByte(ch)
+	}
+	return append(values, UnescapeICalText(value.String()))
+}
+
+// Time returns the value of a DATE or DATE-TIME property. A date is read as midnight UTC.
+// A local time is read in its TZID when it names a known location, in UTC otherwise,
+// like the floating times that belong to no timezone.
+func (p *ICalProperty) Time() (time.Time, error) {
+	value := p.Value
+	if p.Params["VALUE"] == "DATE" || len(value) == len(icalDateFormat) {
+		t, err := time.Parse(icalDateFormat, value)
+		if err != nil {
+			return time.Time{}, ErrICalSyntax
+		}
+		return t, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icalUTCFormat, value)
+		if err != nil {
+			return time.Time{}, ErrICalSyntax
+		}
+		return t, nil
+	}
+
+	loc := time.UTC
+	if tzid := strings.TrimPrefix(p.Params["TZID"], "/"); tzid != "" {
+		if tz, err := time.LoadLocation(tzid); err == nil {
+			loc = tz
+		}
+	}
+	t, err := time.ParseInLocation(icalFormat, value, loc)
+	if err != nil {
+		return time.Time{}, ErrICalSyntax
+	}
+	return t.UTC(), nil
+}
+
+// Duration returns the value of a DURATION property.
+func (p *ICalProperty) Duration() (time.Duration, error) {
+	match := icalDuration.FindStringSubmatch(p.Value)
+	if match == nil || p.Value == "P" || strings.HasSuffix(p.Value, "T") {
+		return 0, ErrICalSyntax
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var duration time.Duration
+	for i, unit := range units {
+		if match[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(match[i+2])
+		if err != nil {
+			return 0, ErrICalSyntax
+		}
+		duration += time.Duration(n) * unit
+	}
+	if match[1] == "-" {
+		duration = -duration
+	}
+	return duration, nil
+}
+
+// UnescapeICalText reads an escaped TEXT value.
+func UnescapeICalText(text string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(text)
+}
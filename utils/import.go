@@ -0,0 +1,346 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	dto "go-feToDo/dtos"
+	"go-feToDo/enums"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ImportRow is a todo read from an import file, before it is checked and saved.
+// Project and Tags are names, they are resolved against the author's projects and tags.
+type ImportRow struct {
+	Row     int
+	Todo    dto.CreateTodoDTO
+	Status  enums.TodoStatus
+	Project string
+	Tags    []string
+	Err     error // set when the row itself can't be read
+}
+
+// importJSONTodo is an element of a JSON import, the shape of a JSON export is accepted.
+type importJSONTodo struct {
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Status      enums.TodoStatus   `json:"status"`
+	Priority    enums.TodoPriority `json:"priority"`
+	ProjectID   *uint              `json:"project_id"`
+	Project     string             `json:"project"`
+	Tags        []importJSONTag    `json:"tags"`
+	StartAt     *time.Time         `json:"start_at"`
+	DueAt       *time.Time         `json:"due_at"`
+}
+
+// importJSONTag is a tag name, or a tag object as exported.
+type importJSONTag string
+
+func (t *importJSONTag) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = importJSONTag(name)
+		return nil
+	}
+	var tag struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &tag); err != nil {
+		return err
+	}
+	*t = importJSONTag(tag.Name)
+	return nil
+}
+
+// ImportFormat guesses the format of an import file from its extension.
+func ImportFormat(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return "csv"
+	case ".json":
+		return "json"
+	case ".txt":
+		return "todotxt"
+	}
+	return ""
+}
+
+// ParseTodoImport reads the rows of an import file. A file that can't be read at all
+// fails as a whole, while a row that can't be read only carries its error.
+func ParseTodoImport(format string, r io.Reader, maxRows int) ([]ImportRow, error) {
+	var rows []ImportRow
+	var err error
+	switch format {
+	case "csv":
+		rows, err = parseCSVImport(r, maxRows)
+	case "json":
+		rows, err = parseJSONImport(r, maxRows)
+	case "todotxt":
+		rows, err = parseTodoTxtImport(r, maxRows)
+	default:
+		return nil, dto.ErrImportFormat
+	}
+	if errors.Is(err, dto.ErrImportTooManyRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, dto.ErrImportFile
+	}
+	return rows, nil
+}
+
+// parseCSVImport reads a CSV file with a header row, columns are matched by name
+// like in a CSV export and unknown columns are ignored.
+func parseCSVImport(r io.Reader, maxRows int) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, dto.ErrImportFile
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == maxRows {
+			return nil, dto.ErrImportTooManyRows
+		}
+
+		cell := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return importCell(record[i])
+		}
+		line, _ := reader.FieldPos(0)
+		row := ImportRow{
+			Row: line,
+			Todo: dto.CreateTodoDTO{
+				Title:       strings.TrimSpace(cell("title")),
+				Description: cell("description"),
+				Priority:    enums.TodoPriority(strings.TrimSpace(cell("priority"))),
+			},
+			Status:  enums.TodoStatus(strings.TrimSpace(cell("status"))),
+			Project: strings.TrimSpace(cell("project")),
+		}
+		for _, tag := range strings.Split(cell("tags"), ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				row.Tags = append(row.Tags, tag)
+			}
+		}
+		if row.Todo.StartAt, err = parseImportTime(cell("start_at")); err != nil {
+			row.Err = err
+		}
+		if row.Todo.DueAt, err = parseImportTime(cell("due_at")); err != nil {
+			row.Err = err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// importCell undoes the quote a CSV export puts before cells read as formulas.
+func importCell(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+// parseImportTime reads an RFC 3339 timestamp or a date, taken at midnight UTC.
+func parseImportTime(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, s)
+	}
+	if err != nil {
+		return nil, errors.New("invalid date " + s)
+	}
+	return &t, nil
+}
+
+// parseJSONImport reads a JSON array of todos element by element. An element that
+// doesn't fit a todo fails alone, broken JSON fails the whole file.
+func parseJSONImport(r io.Reader, maxRows int) ([]ImportRow, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, dto.ErrImportFile
+	}
+
+	var rows []ImportRow
+	for decoder.More() {
+		if len(rows) == maxRows {
+			return nil, dto.ErrImportTooManyRows
+		}
+
+		var todo importJSONTodo
+		err := decoder.Decode(&todo)
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, err
+		}
+		if errors.As(err, &typeErr) {
+			err = errors.New("invalid " + typeErr.Field)
+		} else if err != nil {
+			err = errors.New("invalid todo")
+		}
+
+		row := ImportRow{
+			Row: len(rows) + 1,
+			Todo: dto.CreateTodoDTO{
+				Title:       strings.TrimSpace(todo.Title),
+				Description: todo.Description,
+				Priority:    todo.Priority,
+				ProjectID:   todo.ProjectID,
+				StartAt:     todo.StartAt,
+				DueAt:       todo.DueAt,
+			},
+			Status:  todo.Status,
+			Project: strings.TrimSpace(todo.Project),
+			Err:     err,
+		}
+		for _, tag := range todo.Tags {
+			if name := strings.TrimSpace(string(tag)); name != "" {
+				row.Tags = append(row.Tags, name)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseTodoTxtImport reads a todo.txt file the way a todo.txt export writes it.
+// Blank lines are ignored.
+func parseTodoTxtImport(r io.Reader, maxRows int) ([]ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var rows []ImportRow
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if len(rows) == maxRows {
+			return nil, dto.ErrImportTooManyRows
+		}
+		row := parseTodoTxtLine(text)
+		row.Row = line
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseTodoTxtLine reads one todo.txt task: completion mark and dates or priority first,
+// then the title where +project, @context and key:value words are picked out.
+func parseTodoTxtLine(text string) ImportRow {
+	// An open task keeps the status new todos start in
+	var row ImportRow
+	words := strings.Fields(text)
+
+	if len(words) > 0 && words[0] == "x" {
+		row.Status = enums.TodoStatusCompleted
+		words = words[1:]
+		// The completion date isn't kept
+		if len(words) > 1 && isTodoTxtDate(words[0]) && isTodoTxtDate(words[1]) {
+			words = words[1:]
+		}
+	} else if len(words) > 0 && len(words[0]) == 3 && words[0][0] == '(' && words[0][2] == ')' {
+		row.Todo.Priority = todoTxtPriority(words[0][1:2])
+		words = words[1:]
+	}
+	// The creation date is set by the import
+	if len(words) > 0 && isTodoTxtDate(words[0]) {
+		words = words[1:]
+	}
+
+	var title []string
+	for _, word := range words {
+		key, value, isPair := strings.Cut(word, ":")
+		switch {
+		case len(word) > 1 && word[0] == '+' && row.Project == "":
+			row.Project = word[1:]
+		case len(word) > 1 && word[0] == '@':
+			row.Tags = append(row.Tags, word[1:])
+		case isPair && value != "" && key == "due":
+			row.Todo.DueAt = parseTodoTxtDate(value, &row)
+		case isPair && value != "" && key == "t":
+			row.Todo.StartAt = parseTodoTxtDate(value, &row)
+		case isPair && value != "" && key == "pri":
+			row.Todo.Priority = todoTxtPriority(value)
+		case isPair && value != "" && key == "note":
+			description, err := url.PathUnescape(value)
+			if err != nil {
+				row.Err = errors.New("invalid note " + value)
+			}
+			row.Todo.Description = description
+		case isPair && value != "" && key == "trashed":
+			// Trashed todos come back as active todos
+		default:
+			title = append(title, word)
+		}
+	}
+	row.Todo.Title = strings.Join(title, " ")
+	return row
+}
+
+// isTodoTxtDate tells whether a word is a todo.txt date.
+func isTodoTxtDate(word string) bool {
+	_, err := time.Parse(time.DateOnly, word)
+	return err == nil
+}
+
+// parseTodoTxtDate reads the date of a key:value pair, a bad date fails the row.
+func parseTodoTxtDate(value string, row *ImportRow) *time.Time {
+	date, err := parseImportTime(value)
+	if err != nil {
+		row.Err = err
+	}
+	return date
+}
+
+// todoTxtPriority maps a todo.txt priority letter to a priority, letters after D are low.
+func todoTxtPriority(letter string) enums.TodoPriority {
+	for priority, priorityLetter := range todoTxtPriorities {
+		if priorityLetter == letter {
+			return priority
+		}
+	}
+	if letter >= "E" && letter <= "Z" {
+		return enums.TodoPriorityLow
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+// rankDigits are the digits of a rank, in their sort order.
+// Lowercase letters and digits sort the same way in every collation.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+var ErrRankOrder = errors.New("no rank fits between these ranks")
+
+// RankBetween returns a rank that sorts strictly between before and after, so an item
+// can be moved without renumbering the others. An empty before stands for the start
+// of the list and an empty after for its end.
+//
+// Ranks are picked in the middle of the gap, except at the open ends of the list
+// where they take the nearest digit to leave room for the next items.
+// A generated rank never ends with "0", so there is always room below it.
+func RankBetween(before, after string) (string, error) {
+	if after != "" && before >= after {
+		return "", ErrRankOrder
+	}
+
+	var rank []byte
+	// bounded is true while the rank is still a prefix of after
+	bounded := after != ""
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(before) {
+			lo = strings.IndexByte(rankDigits, before[i])
+		}
+		hi := len(rankDigits)
+		if bounded {
+			if i >= len(after) {
+				return "", ErrRankOrder
+			}
+			hi = strings.IndexByte(rankDigits, after[i])
+		}
+		if lo < 0 || hi < 0 {
+			return "", ErrRankOrder
+		}
+
+		if hi-lo > 1 {
+			switch {
+			case after == "" && i < len(before):
+				rank = append(rank, rankDigits[lo+1])
+			case before == "" && after != "":
+				rank = append(rank, rankDigits[hi-1])
+			default:
+				rank = append(rank, rankDigits[(lo+hi)/2])
+			}
+			return string(rank), nil
+		}
+
+		// No room at this digit, keep the lower digit and look further
+		rank = append(rank, rankDigits[lo])
+		if lo < hi {
+			bounded = false
+		}
+	}
+}
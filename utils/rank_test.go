@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  string
+		after   string
+		wantErr bool
+	}{
+		{name: "empty list", before: "", after: ""},
+		{name: "start of the list", before: "", after: "a"},
+		{name: "end of the list", before: "y", after: ""},
+		{name: "end at the last digit", before: "z", after: ""},
+		{name: "start below the first digit", before: "", after: "01"},
+		{name: "wide gap", before: "a", after: "z"},
+		{name: "adjacent ranks", before: "a", after: "b"},
+		{name: "adjacent longer ranks", before: "az", after: "b"},
+		{name: "prefix of after", before: "a", after: "a1"},
+		{name: "equal ranks", before: "b", after: "b", wantErr: true},
+		{name: "reversed ranks", before: "c", after: "b", wantErr: true},
+		{name: "nothing between", before: "a", after: "a0", wantErr: true},
+		{name: "invalid digit", before: "A", after: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rank, err := RankBetween(tt.before, tt.after)
+			if tt.wantErr {
+				if !errors.Is(err, ErrRankOrder) {
+					t.Fatalf("RankBetween(%q, %q) = %q, %v, want %v", tt.before, tt.after, rank, err, ErrRankOrder)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RankBetween(%q, %q) error = %v", tt.before, tt.after, err)
+			}
+			checkRank(t, rank, tt.before, tt.after)
+		})
+	}
+}
+
+func TestRankBetweenKeyLength(t *testing.T) {
+	// Moves to the open ends of the list grow the ranks by a digit every 18 moves or so,
+	// moves into the same gap by a digit every five.
+	tests := []struct {
+		name        string
+		before      string
+		after       string
+		towardStart bool // whether each rank becomes the after of the next move
+		maxLen      int
+	}{
+		{name: "appending", maxLen: 6},
+		{name: "prepending", towardStart: true, maxLen: 6},
+		{name: "bisecting toward the start", before: "a", after: "b", towardStart: true, maxLen: 25},
+		{name: "bisecting toward the end", before: "a", after: "b", maxLen: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after := tt.before, tt.after
+			for i := 0; i < 100; i++ {
+				rank, err := RankBetween(before, after)
+				if err != nil {
+					t.Fatalf("move %d: RankBetween(%q, %q) error = %v", i, before, after, err)
+				}
+				checkRank(t, rank, before, after)
+				if len(rank) > tt.maxLen {
+					t.Fatalf("move %d: RankBetween(%q, %q) = %q, longer than %d", i, before, after, rank, tt.maxLen)
+				}
+				if tt.towardStart {
+					after = rank
+				} else {
+					before = rank
+				}
+			}
+		})
+	}
+}
+
+// checkRank fails the test unless rank sorts strictly between before and after and
+// leaves room below it.
+func checkRank(t *testing.T, rank, before, after string) {
+	t.Helper()
+	if rank == "" || rank <= before || (after != "" && rank >= after) {
+		t.Fatalf("RankBetween(%q, %q) = %q, not strictly between", before, after, rank)
+	}
+	if strings.HasSuffix(rank, "0") {
+		t.Fatalf("RankBetween(%q, %q) = %q, ends with 0", before, after, rank)
+	}
+}
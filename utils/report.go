@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"encoding/csv"
+	dto "go-feToDo/dtos"
+	"io"
+	"strconv"
+)
+
+// WriteTimeReportCSV writes a time report as CSV, a line for each of its rows with the
+// time spent in seconds and in hours.
+func WriteTimeReportCSV(w io.Writer, report *dto.TimeReportDTO) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{report.GroupBy, "name", "entries", "seconds", "hours"}); err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		err := writer.Write([]string{
+			row.Key,
+			csvCell(row.Name),
+			strconv.Itoa(row.Entries),
+			strconv.FormatInt(row.Seconds, 10),
+			strconv.FormatFloat(float64(row.Seconds)/3600, 'f', 2, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -1,32 +1,207 @@
 package utils
 
 import (
+	"encoding/json"
 	dto "go-feToDo/dtos"
 	"go-feToDo/models"
 	"strconv"
+	"time"
 )
 
 // ToUserResponseDTO converts a User model to a UserResponseDTO
 func ToUserResponseDTO(user *models.User) *dto.UserResponseDTO {
 	return &dto.UserResponseDTO{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		FeedEnabled: user.FeedTokenHash != nil,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+}
+
+// ToAppPasswordResponseDTO converts an AppPassword model to an AppPasswordResponseDTO
+func ToAppPasswordResponseDTO(password *models.AppPassword) *dto.AppPasswordResponseDTO {
+	return &dto.AppPasswordResponseDTO{
+		ID:         password.ID,
+		Name:       password.Name,
+		LastUsedAt: password.LastUsedAt,
+		CreatedAt:  password.CreatedAt,
 	}
 }
 
 // ToTodoResponseDTO converts a Todo model to a TodoResponseDTO
 func ToTodoResponseDTO(todo *models.Todo) *dto.TodoResponseDTO {
 	return &dto.TodoResponseDTO{
-		ID:          todo.ID,
-		Title:       todo.Title,
-		Description: todo.Description,
-		Status:      todo.Status,
-		AuthorID:    todo.AuthorID,
-		CreatedAt:   todo.CreatedAt,
-		UpdatedAt:   todo.UpdatedAt,
+		ID:             todo.ID,
+		Title:          todo.Title,
+		Description:    todo.Description,
+		Status:         todo.Status,
+		Category:       todo.StatusCategory,
+		Priority:       todo.Priority,
+		Position:       todo.Position,
+		BoardPosition:  todo.BoardPosition,
+		AuthorID:       todo.AuthorID,
+		ProjectID:      todo.ProjectID,
+		AssigneeID:     todo.AssigneeID,
+		StartAt:        todo.StartAt,
+		DueAt:          todo.DueAt,
+		Overdue:        IsOverdue(todo, time.Now()),
+		Blocked:        todo.Blocked(),
+		BlockedBy:      blockerIDs(todo.Blockers),
+		TrackedSeconds: todo.TrackedSeconds,
+		Recurrence:     ToRecurrenceResponseDTO(todo.Recurrence),
+		OccurrenceAt:   todo.OccurrenceAt,
+		Tags:           ToTagResponseDTOs(todo.Tags),
+		Subtasks:       ToSubtaskResponseDTOs(todo.Subtasks),
+		Progress:       ToTodoProgressDTO(todo.Subtasks),
+		CreatedAt:      todo.CreatedAt,
+		UpdatedAt:      todo.UpdatedAt,
+	}
+}
+
+// blockerIDs lists the IDs of the active blockers of a todo.
+func blockerIDs(blockers []models.Todo) []uint {
+	ids := make([]uint, 0, len(blockers))
+	for _, blocker := range blockers {
+		if !blocker.DeletedAt.Valid {
+			ids = append(ids, blocker.ID)
+		}
+	}
+	return ids
+}
+
+// IsOverdue reports whether an unfinished todo is past its due date
+func IsOverdue(todo *models.Todo, now time.Time) bool {
+	return todo.DueAt != nil && todo.DueAt.Before(now) && !todo.Done()
+}
+
+// ToRecurrenceResponseDTO converts a Recurrence model to a RecurrenceResponseDTO
+func ToRecurrenceResponseDTO(recurrence *models.Recurrence) *dto.RecurrenceResponseDTO {
+	if recurrence == nil {
+		return nil
+	}
+	return &dto.RecurrenceResponseDTO{
+		ID:       recurrence.ID,
+		Rule:     recurrence.Rule,
+		Timezone: recurrence.Timezone,
+		Dtstart:  recurrence.Dtstart,
+	}
+}
+
+// ToTagResponseDTO converts a Tag model to a TagResponseDTO
+func ToTagResponseDTO(tag *models.Tag) *dto.TagResponseDTO {
+	return &dto.TagResponseDTO{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		Color:     tag.Color,
+		CreatedAt: tag.CreatedAt,
+		UpdatedAt: tag.UpdatedAt,
+	}
+}
+
+// ToTagResponseDTOs converts a slice of Tag models to TagResponseDTOs
+func ToTagResponseDTOs(tags []models.Tag) []dto.TagResponseDTO {
+	tagDTOs := make([]dto.TagResponseDTO, 0, len(tags))
+	for _, tag := range tags {
+		tagDTOs = append(tagDTOs, *ToTagResponseDTO(&tag))
+	}
+	return tagDTOs
+}
+
+// ToSubtaskResponseDTO converts a Subtask model to a SubtaskResponseDTO
+func ToSubtaskResponseDTO(subtask *models.Subtask) *dto.SubtaskResponseDTO {
+	return &dto.SubtaskResponseDTO{
+		ID:        subtask.ID,
+		Title:     subtask.Title,
+		Done:      subtask.Done,
+		Required:  subtask.Required,
+		Position:  subtask.Position,
+		CreatedAt: subtask.CreatedAt,
+		UpdatedAt: subtask.UpdatedAt,
+	}
+}
+
+// ToSubtaskResponseDTOs converts a slice of Subtask models to SubtaskResponseDTOs
+func ToSubtaskResponseDTOs(subtasks []models.Subtask) []dto.SubtaskResponseDTO {
+	subtaskDTOs := make([]dto.SubtaskResponseDTO, 0, len(subtasks))
+	for _, subtask := range subtasks {
+		subtaskDTOs = append(subtaskDTOs, *ToSubtaskResponseDTO(&subtask))
+	}
+	return subtaskDTOs
+}
+
+// ToTodoProgressDTO counts the done subtasks of a todo
+func ToTodoProgressDTO(subtasks []models.Subtask) dto.TodoProgressDTO {
+	progress := dto.TodoProgressDTO{Total: len(subtasks)}
+	for _, subtask := range subtasks {
+		if subtask.Done {
+			progress.Done++
+		}
+	}
+	return progress
+}
+
+// ToProjectResponseDTO converts a Project model to a ProjectResponseDTO
+func ToProjectResponseDTO(project *models.Project) *dto.ProjectResponseDTO {
+	return &dto.ProjectResponseDTO{
+		ID:         project.ID,
+		Name:       project.Name,
+		Position:   project.Position,
+		IsInbox:    project.IsInbox,
+		Archived:   project.ArchivedAt != nil,
+		WorkflowID: project.WorkflowID,
+		ArchivedAt: project.ArchivedAt,
+		CreatedAt:  project.CreatedAt,
+		UpdatedAt:  project.UpdatedAt,
+	}
+}
+
+// ToShareResponseDTO converts a TodoShare model, with its users, todo and project loaded, to a ShareResponseDTO
+func ToShareResponseDTO(share *models.TodoShare) *dto.ShareResponseDTO {
+	shareDTO := &dto.ShareResponseDTO{
+		ID:         share.ID,
+		TodoID:     share.TodoID,
+		ProjectID:  share.ProjectID,
+		User:       dto.ShareUserDTO{ID: share.User.ID, Username: share.User.Username},
+		InvitedBy:  dto.ShareUserDTO{ID: share.InvitedBy.ID, Username: share.InvitedBy.Username},
+		Role:       share.Role,
+		AcceptedAt: share.AcceptedAt,
+		CreatedAt:  share.CreatedAt,
+	}
+	if share.Todo != nil {
+		shareDTO.TodoTitle = &share.Todo.Title
+	}
+	if share.Project != nil {
+		shareDTO.ProjectName = &share.Project.Name
+	}
+	return shareDTO
+}
+
+// ToCommentResponseDTO converts a Comment model, with its author loaded, to a CommentResponseDTO
+func ToCommentResponseDTO(comment *models.Comment) *dto.CommentResponseDTO {
+	return &dto.CommentResponseDTO{
+		ID:        comment.ID,
+		TodoID:    comment.TodoID,
+		ParentID:  comment.ParentID,
+		Author:    dto.ShareUserDTO{ID: comment.Author.ID, Username: comment.Author.Username},
+		Body:      comment.Body,
+		Deleted:   comment.DeletedAt != nil,
+		EditedAt:  comment.EditedAt,
+		CreatedAt: comment.CreatedAt,
+	}
+}
+
+// ToAttachmentResponseDTO converts an Attachment model, with its uploader loaded, to an AttachmentResponseDTO
+func ToAttachmentResponseDTO(attachment *models.Attachment) *dto.AttachmentResponseDTO {
+	return &dto.AttachmentResponseDTO{
+		ID:          attachment.ID,
+		TodoID:      attachment.TodoID,
+		Uploader:    dto.ShareUserDTO{ID: attachment.Uploader.ID, Username: attachment.Uploader.Username},
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		CreatedAt:   attachment.CreatedAt,
 	}
 }
 
@@ -38,3 +213,82 @@ func ConvId(authorId string) (uint, error) {
 	}
 	return uint(parsed), nil
 }
+
+// ToTodoActivityResponseDTO converts a TodoActivity model, with its actor loaded, to a TodoActivityResponseDTO
+func ToTodoActivityResponseDTO(activity *models.TodoActivity) *dto.TodoActivityResponseDTO {
+	activityDTO := &dto.TodoActivityResponseDTO{
+		ID:        activity.ID,
+		TodoID:    activity.TodoID,
+		Action:    activity.Action,
+		Changes:   map[string]dto.FieldChangeDTO{},
+		CreatedAt: activity.CreatedAt,
+	}
+	if activity.Actor != nil {
+		activityDTO.Actor = &dto.ShareUserDTO{ID: activity.Actor.ID, Username: activity.Actor.Username}
+	}
+	// The changes were marshaled from the same type, an entry that can't be read shows none
+	_ = json.Unmarshal([]byte(activity.Changes), &activityDTO.Changes)
+	return activityDTO
+}
+
+// ToWorkflowResponseDTO converts a Workflow model, with its statuses and transitions loaded, to a WorkflowResponseDTO
+func ToWorkflowResponseDTO(workflow *models.Workflow) *dto.WorkflowResponseDTO {
+	workflowDTO := &dto.WorkflowResponseDTO{
+		ID:          workflow.ID,
+		Name:        workflow.Name,
+		Builtin:     workflow.ID == 0,
+		Default:     workflow.IsDefault,
+		Statuses:    make([]dto.WorkflowStatusDTO, 0, len(workflow.Statuses)),
+		Transitions: make([]dto.WorkflowTransitionDTO, 0, len(workflow.Transitions)),
+	}
+	for _, status := range workflow.Statuses {
+		workflowDTO.Statuses = append(workflowDTO.Statuses, dto.WorkflowStatusDTO{Name: status.Name, Category: status.Category, WIPLimit: status.WIPLimit})
+	}
+	for _, transition := range workflow.Transitions {
+		workflowDTO.Transitions = append(workflowDTO.Transitions, dto.WorkflowTransitionDTO{From: transition.From, To: transition.To})
+	}
+	return workflowDTO
+}
+
+// ToTimeEntryResponseDTO converts a TimeEntry model, with its user loaded, to a TimeEntryResponseDTO
+func ToTimeEntryResponseDTO(entry *models.TimeEntry) *dto.TimeEntryResponseDTO {
+	return &dto.TimeEntryResponseDTO{
+		ID:        entry.ID,
+		TodoID:    entry.TodoID,
+		User:      dto.ShareUserDTO{ID: entry.User.ID, Username: entry.User.Username},
+		StartedAt: entry.StartedAt,
+		EndedAt:   entry.EndedAt,
+		Seconds:   entry.Seconds(),
+		Running:   entry.EndedAt == nil,
+		Note:      entry.Note,
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+// ToTemplateResponseDTO converts a Template model, with its tags and subtasks loaded, to a TemplateResponseDTO
+func ToTemplateResponseDTO(template *models.Template) *dto.TemplateResponseDTO {
+	templateDTO := &dto.TemplateResponseDTO{
+		ID:          template.ID,
+		Name:        template.Name,
+		Title:       template.Title,
+		Description: template.Description,
+		Priority:    template.Priority,
+		ProjectID:   template.ProjectID,
+		Tags:        ToTagResponseDTOs(template.Tags),
+		StartOffset: template.StartOffset,
+		DueOffset:   template.DueOffset,
+		Subtasks:    make([]dto.TemplateSubtaskResponseDTO, 0, len(template.Subtasks)),
+		Variables:   TemplateVariables(template.Title, template.Description),
+		CreatedAt:   template.CreatedAt,
+		UpdatedAt:   template.UpdatedAt,
+	}
+	for _, subtask := range template.Subtasks {
+		templateDTO.Subtasks = append(templateDTO.Subtasks, dto.TemplateSubtaskResponseDTO{
+			ID:       subtask.ID,
+			Title:    subtask.Title,
+			Required: subtask.Required,
+			Position: subtask.Position,
+		})
+	}
+	return templateDTO
+}
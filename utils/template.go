@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// templateVariable matches a {{variable}} of a template, spaces inside the braces are allowed.
+var templateVariable = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// TemplateVariables lists the names of the variables used in the texts of a template,
+// each once, in the order they first appear.
+func TemplateVariables(texts ...string) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, match := range templateVariable.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	return names
+}
+
+// FillTemplate replaces the variables of a text with their values. A variable without a
+// value is kept as is.
+func FillTemplate(text string, values map[string]string) string {
+	return templateVariable.ReplaceAllStringFunc(text, func(variable string) string {
+		name := strings.TrimSpace(strings.Trim(variable, "{}"))
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return variable
+	})
+}
@@ -6,6 +6,10 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
+// Validate checks payloads against their validate tags. The endpoints and the import share
+// it, so a rule reads the same everywhere.
+var Validate = validator.New()
+
 func ParseValidationErrors(err error) []string {
 	var validationErrors []string
 
@@ -0,0 +1,38 @@
+package workers
+
+import (
+	"context"
+	"go-feToDo/services"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RunTrashPurge permanently deletes the todos that stayed in the trash longer than the
+// retention, once at startup and then every interval, until the context is cancelled.
+// A purge in progress is finished before returning.
+func RunTrashPurge(ctx context.Context, retention time.Duration, interval time.Duration) {
+	logger := zap.L().With(zap.String("worker", "trash_purge"))
+	logger.Info("trash purge started", zap.Duration("retention", retention), zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := services.PurgeTrash(ctx, time.Now().Add(-retention))
+		if err != nil {
+			logger.Error("trash purge failed", zap.Error(err))
+		} else if len(purged) > 0 {
+			logger.Info("purged trashed todos", zap.Int("count", len(purged)), zap.Uints("todo_ids", purged))
+		} else {
+			logger.Debug("no trashed todo to purge")
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("trash purge stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}